@@ -3,6 +3,8 @@ package API
 import (
 	"attendance-system/controller"
 	"attendance-system/middleware"
+	"attendance-system/models"
+	"attendance-system/services"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -23,6 +25,10 @@ func AuthRoutes(app *fiber.App) {
 	// Login routes
 	app.Post("/login", controller.Login)
 	app.Post("/refresh-token", controller.RefreshToken)
+	// Second login step, authorized by the challenge_token returned by /login
+	app.Post("/login/2fa", controller.LoginTwoFactor)
+	app.Post("/login/2fa/setup", controller.LoginTwoFactorSetup)
+	app.Post("/login/2fa/enable", controller.LoginTwoFactorEnable)
 
 	// Password Reset Routes - PUBLIC (no authentication required)
 	// Direct root level routes (easier for frontend)
@@ -40,19 +46,105 @@ func AuthRoutes(app *fiber.App) {
 		fgtp.Post("/resend-code", controller.ResendCode)
 	}
 
+	// Profile pictures, usable directly as <img src>. Each URL carries a 128-bit
+	// random key, so it is only known to those shown the photo.
+	app.Get("/media/*", controller.GetMedia)
+
 	// Protected routes (require authentication)
 	protected := app.Group("", middleware.RequireAuth)
 	{
 		protected.Get("/profile", controller.GetProfile)
+		protected.Put("/profile", controller.UpdateProfile)
+		protected.Post("/profile/email/verify", controller.VerifyEmailChange)
+		protected.Delete("/profile/email", controller.CancelEmailChange)
+		protected.Put("/profile/picture", controller.UploadProfilePicture)
+		protected.Delete("/profile/picture", controller.DeleteProfilePicture)
+		protected.Get("/profile/export", controller.ExportMyData)
+		protected.Get("/profile/erasure-request", controller.GetMyErasureRequest)
+		protected.Post("/profile/erasure-request", controller.RequestErasure)
+		protected.Delete("/profile/erasure-request", controller.CancelMyErasureRequest)
+		protected.Get("/profile-locks", middleware.RequirePermission(models.PermProfileLock), controller.ListProfileLocks)
+		protected.Put("/profile-locks", middleware.RequirePermission(models.PermProfileLock), controller.SetProfileLocks)
 		// Return current user's QR code (base64 PNG data)
 		protected.Get("/users/me/qrcode", controller.GetMyQRCode)
+		protected.Post("/change-password", controller.ChangePassword)
+
+		// Sessions (one per login; refresh tokens rotate within a session)
+		protected.Post("/logout", controller.Logout)
+		protected.Post("/logout-all", controller.LogoutAll)
+		protected.Get("/sessions", controller.ListSessions)
+		protected.Delete("/sessions/:id", controller.RevokeSession)
+
+		// Two-factor authentication (TOTP)
+		protected.Get("/2fa", controller.GetTwoFactorStatus)
+		protected.Post("/2fa/setup", controller.SetupTwoFactor)
+		protected.Post("/2fa/enable", controller.EnableTwoFactor)
+		protected.Post("/2fa/disable", controller.DisableTwoFactor)
+		protected.Post("/2fa/recovery-codes", controller.RegenerateRecoveryCodes)
+
+		// Permission-gated routes
+		protected.Post("/users/promote", middleware.RequirePermission(models.PermUserPromote), controller.PromoteUser)
+		protected.Get("/audit-logs", middleware.RequirePermission(models.PermAuditRead), controller.GetAuditLogs)
+		protected.Get("/users/lockouts", middleware.RequirePermission(models.PermUserUnlock), controller.ListLockouts)
+		protected.Post("/users/:student_id/unlock", middleware.RequirePermission(models.PermUserUnlock), controller.UnlockAccount)
+
+		// Account states and soft deletion (attendance history is always kept)
+		protected.Get("/users/deleted", middleware.RequirePermission(models.PermUserStatus), controller.ListDeletedUsers)
+		protected.Put("/users/:student_id/status", middleware.RequirePermission(models.PermUserStatus), controller.SetAccountStatus)
+		protected.Delete("/users/:student_id", middleware.RequirePermission(models.PermUserStatus), controller.DeleteUser)
+		protected.Post("/users/:student_id/restore", middleware.RequirePermission(models.PermUserStatus), controller.RestoreUser)
 	}
 
 	adminRoutes := app.Group("/admin", middleware.RequireAuth, middleware.RequireSuperAdmin)
 	{
-		adminRoutes.Get("/users", controller.GetAllUsers)
+		adminRoutes.Get("/users", controller.ListUsers)
 		adminRoutes.Get("/stats", controller.GetSystemStats)
 		adminRoutes.Post("/promote", controller.PromoteUser)
+		adminRoutes.Delete("/users/:student_id/2fa", controller.ResetUserTwoFactor)
+
+		// Audit log: filtered, cursor-paginated, exportable as CSV or JSON Lines
+		adminRoutes.Get("/audit-logs", controller.SearchAuditLogs)
+		adminRoutes.Get("/audit-logs/targets/:target_id", controller.GetAuditTimeline)
+		adminRoutes.Get("/audit-logs/verify", controller.VerifyAuditLog)
+
+		// Personal data erasure: request → approve/reject → execute with typed confirmation
+		adminRoutes.Get("/erasure-requests", controller.ListErasureRequests)
+		adminRoutes.Post("/erasure-requests", controller.CreateErasureRequest)
+		adminRoutes.Post("/erasure-requests/:id/approve", controller.ApproveErasureRequest)
+		adminRoutes.Post("/erasure-requests/:id/reject", controller.RejectErasureRequest)
+		adminRoutes.Post("/erasure-requests/:id/execute", controller.ExecuteErasureRequest)
+
+		// Roles and their permissions
+		adminRoutes.Get("/roles", controller.ListRoles)
+		adminRoutes.Post("/roles", controller.CreateRole)
+		adminRoutes.Put("/roles/:name/permissions", controller.SetRolePermissions)
+		adminRoutes.Delete("/roles/:name", controller.DeleteRole)
+
+		// Service accounts and their API keys (machine clients use the X-API-Key header)
+		adminRoutes.Get("/service-accounts", controller.ListServiceAccounts)
+		adminRoutes.Post("/service-accounts", controller.CreateServiceAccount)
+		adminRoutes.Delete("/service-accounts/:id", controller.DisableServiceAccount)
+		adminRoutes.Get("/service-accounts/:id/keys", controller.ListAPIKeys)
+		adminRoutes.Post("/service-accounts/:id/keys", controller.CreateAPIKey)
+		adminRoutes.Delete("/service-accounts/:id/keys/:key_id", controller.RevokeAPIKey)
+
+		// Background jobs
+		adminRoutes.Get("/jobs", controller.ListJobs)
+		adminRoutes.Post("/jobs/:name/run", controller.TriggerJob)
+		adminRoutes.Get("/jobs/:name/runs", controller.GetJobRuns)
+
+		// Institution hierarchy (:level = colleges, departments, courses, sections)
+		adminRoutes.Get("/institution/:level", controller.ListInstitutionItems)
+		adminRoutes.Post("/institution/:level", controller.CreateInstitutionItem)
+		adminRoutes.Put("/institution/:level/:id", controller.UpdateInstitutionItem)
+		adminRoutes.Delete("/institution/:level/:id", controller.DeactivateInstitutionItem)
+
+		// Academic terms
+		adminRoutes.Post("/terms", controller.CreateTerm)
+		adminRoutes.Put("/terms/:id", controller.UpdateTerm)
+		adminRoutes.Post("/terms/:id/activate", controller.ActivateTerm)
+		adminRoutes.Post("/terms/:id/close", controller.CloseTerm)
+		adminRoutes.Post("/terms/:id/reopen", controller.ReopenTerm)
 	}
 
 	// Admin-level (organization managers) routes
@@ -62,19 +154,73 @@ func AuthRoutes(app *fiber.App) {
 func EventRoutes(app *fiber.App) {
 	// Public routes
 	app.Get("/events/creation-dropdowns", controller.GetEventCreationDropdowns)
+	// One-click reminder opt-out link from reminder emails
+	app.Get("/reminders/opt-out", controller.OptOutEventRemindersByToken)
 
 	events := app.Group("/events", middleware.RequireAuth)
 	{
 		events.Get("/", controller.GetAllEvents)
 		events.Get("/my-events", controller.GetMyEvents)
 		events.Get("/:id", controller.GetEvent)
+		events.Post("/:id/reminders/opt-out", controller.OptOutEventReminders)
+		events.Delete("/:id/reminders/opt-out", controller.OptInEventReminders)
+
+		// Editing is checked per event (creator, co-organizer or admin)
+		events.Put("/:id", controller.UpdateEvent)
+
+		// Lifecycle: draft → scheduled → ongoing → completed (+ postponed, cancelled)
+		events.Post("/:id/publish", controller.PublishEvent)
+		events.Post("/:id/start", controller.StartEvent)
+		events.Post("/:id/end", controller.EndEvent)
+		events.Post("/:id/postpone", controller.PostponeEvent)
+		events.Post("/:id/reopen", controller.ReopenEvent)
+
+		// Per-event staff (co-organizers and scanners), managed by the event owner
+		events.Get("/:id/staff", controller.ListEventStaff)
+		events.Post("/:id/staff", controller.AssignEventStaff)
+		events.Delete("/:id/staff/:student_id", controller.RemoveEventStaff)
+
+		// Creating needs event.create. It is set per route: a group Use on /events
+		// would also run for every other /events route, including attendance.
+		events.Post("/", middleware.RequirePermission(models.PermEventCreate), controller.CreateEvent)
+		events.Post("/:id/clone", middleware.RequirePermission(models.PermEventCreate), controller.CloneEvent)
+
+		// Deleting is checked per event (creator, or event.delete.any within the department)
+		events.Delete("/:id", controller.DeleteEvent)
 	}
 
-	eventsProtected := app.Group("/events", middleware.RequireAuth, middleware.RequireFacultyOrAdmin)
+	// Saved event templates (own + shared department-wide)
+	templates := app.Group("/event-templates", middleware.RequireAuth, middleware.RequirePermission(models.PermEventCreate))
 	{
-		eventsProtected.Post("/", controller.CreateEvent)
-		eventsProtected.Put("/:id", controller.UpdateEvent)
-		eventsProtected.Delete("/:id", controller.DeleteEvent)
+		templates.Get("/", controller.ListEventTemplates)
+		templates.Post("/", controller.CreateEventTemplate)
+		templates.Get("/:id", controller.GetEventTemplate)
+		templates.Put("/:id", controller.UpdateEventTemplate)
+		templates.Delete("/:id", controller.DeleteEventTemplate)
+		templates.Post("/:id/events", controller.CreateEventFromTemplate)
+	}
+
+	// Class offerings and their enrollment lists (assigned faculty or admin)
+	offerings := app.Group("/offerings", middleware.RequireAuth, middleware.RequireFacultyOrAdmin)
+	{
+		offerings.Get("/", controller.ListClassOfferings)
+		offerings.Post("/", controller.CreateClassOffering)
+		offerings.Get("/:id", controller.GetClassOffering)
+		offerings.Put("/:id", controller.UpdateClassOffering)
+		offerings.Delete("/:id", controller.DeleteClassOffering)
+		offerings.Get("/:id/enrollments", controller.ListEnrollments)
+		offerings.Post("/:id/enrollments", controller.EnrollStudents)
+		offerings.Post("/:id/enrollments/import", controller.ImportEnrollments)
+		offerings.Delete("/:id/enrollments/:student_id", controller.UnenrollStudent)
+		offerings.Get("/:id/report", controller.GetOfferingReport)
+	}
+}
+
+func TermRoutes(app *fiber.App) {
+	terms := app.Group("/terms", middleware.RequireAuth)
+	{
+		terms.Get("/", controller.ListTerms)
+		terms.Get("/active", controller.GetActiveTerm)
 	}
 }
 
@@ -84,6 +230,8 @@ func AttendanceRoutes(app *fiber.App) {
 		attendance.Post("/mark", controller.MarkAttendance)
 		attendance.Get("/my-attendance", controller.GetMyAttendance)
 		attendance.Get("/stats", controller.GetAttendanceStats)
+		// Checked per event (creator, co-organizer or admin)
+		attendance.Put("/:id/status", controller.UpdateAttendanceStatus)
 	}
 
 	// Specific route for event attendance - must be after /forgot-password and public routes
@@ -91,9 +239,19 @@ func AttendanceRoutes(app *fiber.App) {
 	{
 		attendanceByEvent.Get("/", controller.GetAttendanceByEvent)
 	}
+}
+
+// DevRoutes exposes development helpers. They are only registered when the
+// in-memory mail transport is active and APP_ENV is development.
+func DevRoutes(app *fiber.App) {
+	if services.DevMailbox() == nil {
+		return
+	}
 
-	attendanceAdmin := app.Group("/attendance", middleware.RequireAuth, middleware.RequireFacultyOrAdmin)
+	dev := app.Group("/dev")
 	{
-		attendanceAdmin.Put("/:id/status", controller.UpdateAttendanceStatus)
+		dev.Get("/mailbox", controller.ListDevMailbox)
+		dev.Get("/mailbox/:id", controller.GetDevMailboxMessage)
+		dev.Delete("/mailbox", controller.ClearDevMailbox)
 	}
 }
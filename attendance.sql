@@ -131,7 +131,7 @@ CREATE TABLE IF NOT EXISTS attendances (
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE,
-    FOREIGN KEY (student_id) REFERENCES users(student_id) ON DELETE CASCADE
+    FOREIGN KEY (student_id) REFERENCES users(student_id) ON DELETE RESTRICT
 );
 
 -- Create indexes for attendances table
@@ -171,7 +171,10 @@ CREATE TABLE IF NOT EXISTS audit_logs (
     target_id VARCHAR(255),
     details TEXT,
     ip_address VARCHAR(255),
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    prev_hash VARCHAR(64) NOT NULL DEFAULT '',
+    hash VARCHAR(64) NOT NULL DEFAULT '',
+    redaction_sig VARCHAR(64) NOT NULL DEFAULT ''
 );
 
 -- Create indexes for audit_logs table
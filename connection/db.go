@@ -74,6 +74,11 @@ func Connect() {
 		log.Println("audit_logs table created successfully!")
 	}
 
+	// Hash chain columns of audit_logs (audit entries are sealed by services.SealAuditLog)
+	db.Exec("ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS prev_hash varchar(64) NOT NULL DEFAULT ''")
+	db.Exec("ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS hash varchar(64) NOT NULL DEFAULT ''")
+	db.Exec("ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS redaction_sig varchar(64) NOT NULL DEFAULT ''")
+
 	// Ensure `tagged_courses` column exists in events table
 	if !db.Migrator().HasColumn(&models.Event{}, "TaggedCoursesCSV") {
 		if err := db.Migrator().AddColumn(&models.Event{}, "TaggedCoursesCSV"); err != nil {
@@ -84,6 +89,85 @@ func Connect() {
 		}
 	}
 
+	// Preferred email locale for users and pending registrations
+	ensureColumn(db, &models.User{}, "Locale", "ALTER TABLE users ADD COLUMN IF NOT EXISTS locale varchar(10)")
+	ensureColumn(db, &models.User{}, "IsServiceAccount", "ALTER TABLE users ADD COLUMN IF NOT EXISTS is_service_account boolean DEFAULT false")
+	ensureColumn(db, &models.User{}, "ProfilePicture", "ALTER TABLE users ADD COLUMN IF NOT EXISTS profile_picture TEXT")
+	ensureColumn(db, &models.User{}, "Status", "ALTER TABLE users ADD COLUMN IF NOT EXISTS status varchar(20) NOT NULL DEFAULT 'active'")
+	ensureColumn(db, &models.User{}, "StatusReason", "ALTER TABLE users ADD COLUMN IF NOT EXISTS status_reason varchar(500)")
+	ensureColumn(db, &models.User{}, "StatusChangedAt", "ALTER TABLE users ADD COLUMN IF NOT EXISTS status_changed_at timestamptz")
+	ensureColumn(db, &models.User{}, "DeletedAt", "ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at timestamptz")
+	ensureColumn(db, &models.PendingUser{}, "Locale", "ALTER TABLE pending_users ADD COLUMN IF NOT EXISTS locale varchar(10)")
+
+	// Event lifecycle bookkeeping
+	ensureColumn(db, &models.Event{}, "StatusReason", "ALTER TABLE events ADD COLUMN IF NOT EXISTS status_reason varchar(500)")
+	ensureColumn(db, &models.Event{}, "StartedAt", "ALTER TABLE events ADD COLUMN IF NOT EXISTS started_at timestamptz")
+	ensureColumn(db, &models.Event{}, "EndedAt", "ALTER TABLE events ADD COLUMN IF NOT EXISTS ended_at timestamptz")
+	ensureColumn(db, &models.Event{}, "ManualEnd", "ALTER TABLE events ADD COLUMN IF NOT EXISTS manual_end boolean DEFAULT false")
+
+	// Institution hierarchy IDs on users and events
+	ensureColumn(db, &models.User{}, "CollegeID", "ALTER TABLE users ADD COLUMN IF NOT EXISTS college_id bigint")
+	ensureColumn(db, &models.User{}, "DepartmentID", "ALTER TABLE users ADD COLUMN IF NOT EXISTS department_id bigint")
+	ensureColumn(db, &models.User{}, "CourseID", "ALTER TABLE users ADD COLUMN IF NOT EXISTS course_id bigint")
+	ensureColumn(db, &models.User{}, "SectionID", "ALTER TABLE users ADD COLUMN IF NOT EXISTS section_id bigint")
+	ensureColumn(db, &models.Event{}, "CollegeID", "ALTER TABLE events ADD COLUMN IF NOT EXISTS college_id bigint")
+	ensureColumn(db, &models.Event{}, "DepartmentID", "ALTER TABLE events ADD COLUMN IF NOT EXISTS department_id bigint")
+	ensureColumn(db, &models.Event{}, "CourseID", "ALTER TABLE events ADD COLUMN IF NOT EXISTS course_id bigint")
+	ensureColumn(db, &models.Event{}, "SectionID", "ALTER TABLE events ADD COLUMN IF NOT EXISTS section_id bigint")
+
+	// Academic term of an event
+	ensureColumn(db, &models.Event{}, "TermID", "ALTER TABLE events ADD COLUMN IF NOT EXISTS term_id bigint")
+	ensureColumn(db, &models.Event{}, "OfferingID", "ALTER TABLE events ADD COLUMN IF NOT EXISTS offering_id bigint")
+
+	// Tables for newer features are created/updated by GORM
+	if err := db.AutoMigrate(
+		&models.EventReminder{},
+		&models.EventReminderOptOut{},
+		&models.JobRun{},
+		&models.EventChange{},
+		&models.EventChangeNotification{},
+		&models.EventTemplate{},
+		&models.EventStaff{},
+		&models.College{},
+		&models.Department{},
+		&models.Course{},
+		&models.Section{},
+		&models.AcademicTerm{},
+		&models.ClassOffering{},
+		&models.Enrollment{},
+		&models.Role{},
+		&models.RolePermission{},
+		&models.RoleDefaultGrant{},
+		&models.AuthSession{},
+		&models.RefreshToken{},
+		&models.ServiceAccount{},
+		&models.APIKey{},
+		&models.TwoFactor{},
+		&models.RecoveryCode{},
+		&models.AuthFailure{},
+		&models.PasswordHistory{},
+		&models.ProfileFieldLock{},
+		&models.EmailChange{},
+		&models.ErasureRequest{},
+		&models.AuditCheckpoint{},
+		&models.AuditChainHead{},
+	); err != nil {
+		log.Printf("Failed to migrate feature tables: %v", err)
+	}
+
 	DB = db
 	log.Println("Database connected successfully!")
 }
+
+// ensureColumn adds a model field's column when missing, falling back to raw SQL
+func ensureColumn(db *gorm.DB, model interface{}, field, fallbackSQL string) {
+	if db.Migrator().HasColumn(model, field) {
+		return
+	}
+	if err := db.Migrator().AddColumn(model, field); err != nil {
+		log.Printf("Failed to add column %s: %v", field, err)
+		if execErr := db.Exec(fallbackSQL).Error; execErr != nil {
+			log.Printf("Fallback ALTER TABLE failed: %v", execErr)
+		}
+	}
+}
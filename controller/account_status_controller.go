@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"attendance-system/models"
+	"attendance-system/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// accountStatusTarget loads the user an account state change is aimed at and
+// checks the caller may manage them. Errors are *fiber.Error for the error handler.
+func accountStatusTarget(c *fiber.Ctx) (models.User, models.User, error) {
+	var target models.User
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return user, target, fiber.NewError(fiber.StatusUnauthorized, "Unauthorized")
+	}
+	if err := services.GetUserByStudentID(c.Params("student_id"), &target); err != nil {
+		return user, target, fiber.NewError(fiber.StatusNotFound, models.ErrUserNotFound)
+	}
+	if !services.CanManageUser(user, target) {
+		return user, target, fiber.NewError(fiber.StatusForbidden, "You can only manage users in your department")
+	}
+	if !services.CanGrantRole(user.Role, target.Role) {
+		return user, target, fiber.NewError(fiber.StatusForbidden, "You cannot manage a user with more permissions than you")
+	}
+	return user, target, nil
+}
+
+// SetAccountStatus suspends, deactivates, graduates or reactivates a user
+func SetAccountStatus(c *fiber.Ctx) error {
+	var req models.AccountStatusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": models.ErrInvalidRequest})
+	}
+	user, target, err := accountStatusTarget(c)
+	if err != nil {
+		return err
+	}
+
+	updated, err := services.SetAccountStatus(target.StudentID, req.Status, req.Reason, user.StudentID, c.IP())
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{
+		"message": "Account status updated",
+		"user":    models.NewUserResponse(*updated),
+	})
+}
+
+// DeleteUser soft-deletes a user; their attendance history is kept
+func DeleteUser(c *fiber.Ctx) error {
+	var req models.DeleteUserRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": models.ErrInvalidRequest})
+		}
+	}
+	user, target, err := accountStatusTarget(c)
+	if err != nil {
+		return err
+	}
+
+	if err := services.SoftDeleteUser(target.StudentID, req.Reason, user.StudentID, c.IP()); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "User deleted. Their attendance history is kept and the account can be restored."})
+}
+
+// RestoreUser undoes a soft delete
+func RestoreUser(c *fiber.Ctx) error {
+	user, target, err := accountStatusTarget(c)
+	if err != nil {
+		return err
+	}
+
+	restored, err := services.RestoreUser(target.StudentID, user.StudentID, c.IP())
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{
+		"message": "User restored",
+		"user":    models.NewUserResponse(*restored),
+	})
+}
+
+// ListDeletedUsers lists soft-deleted users that can be restored
+func ListDeletedUsers(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+	users, err := services.ListDeletedUsers(user)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"users": users})
+}
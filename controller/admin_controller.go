@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"attendance-system/models"
 	"attendance-system/services"
 	"fmt"
 
@@ -70,20 +71,29 @@ func UpdateAdmin(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{"message": "admin updated"})
 }
 
-// DeleteAdmin deletes a user by student_id (superadmin only)
+// DeleteAdmin soft-deletes a user by student_id (superadmin only)
 func DeleteAdmin(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
 	id := c.Params("id")
 	if id == "" {
 		return c.Status(400).JSON(fiber.Map{"error": "id is required"})
 	}
-	if err := services.DeleteUserByStudentID(id); err != nil {
+	if err := services.SoftDeleteUser(id, "", user.StudentID, c.IP()); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 	return c.JSON(fiber.Map{"message": "user deleted"})
 }
 
-// GetAuditLogs returns audit logs (superadmin)
+// GetAuditLogs returns the newest audit logs the caller may read (audit.read).
+// The full search and export API is /admin/audit-logs.
 func GetAuditLogs(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": models.ErrUnauthorized})
+	}
 	// Parse simple filters
 	filters := make(map[string]interface{})
 	if action := c.Query("action"); action != "" {
@@ -94,7 +104,7 @@ func GetAuditLogs(c *fiber.Ctx) error {
 	}
 	limit := 50
 	offset := 0
-	logs, total, err := services.GetAuditLogs(filters, limit, offset)
+	logs, total, err := services.GetAuditLogs(user, filters, limit, offset)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
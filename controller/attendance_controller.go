@@ -11,12 +11,22 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-// getUserAndCheckPermissions retrieves the user and checks if they can mark attendance for the given studentID
-func getUserAndCheckPermissions(c *fiber.Ctx, targetStudentID string) (models.User, error) {
+// legacyStudentIDHeader returns the deprecated X-Student-ID header, or "" when
+// LEGACY_STUDENT_ID_AUTH is off
+func legacyStudentIDHeader(c *fiber.Ctx) string {
+	if !services.LegacyStudentIDAuthEnabled() {
+		return ""
+	}
+	return c.Get(utils.HeaderStudentID)
+}
+
+// getUserAndCheckPermissions retrieves the user marking attendance. Whether they may
+// mark someone else is decided per event by services.MarkAttendance (staff assignments).
+func getUserAndCheckPermissions(c *fiber.Ctx) (models.User, error) {
 	// Get user from context
 	user, ok := c.Locals("user").(models.User)
 	if !ok {
-		studentID := c.Get(utils.HeaderStudentID)
+		studentID := legacyStudentIDHeader(c)
 		if studentID == "" {
 			return models.User{}, fiber.NewError(401, utils.ErrUnauthorized)
 		}
@@ -27,13 +37,6 @@ func getUserAndCheckPermissions(c *fiber.Ctx, targetStudentID string) (models.Us
 		user = dbUser
 	}
 
-	// Only allow marking own attendance unless admin/faculty
-	if targetStudentID != "" && targetStudentID != user.StudentID {
-		if user.Role != "superadmin" && user.Role != "admin" && user.Role != "faculty" {
-			return models.User{}, fiber.NewError(403, "You can only mark your own attendance")
-		}
-	}
-
 	return user, nil
 }
 
@@ -53,7 +56,7 @@ func MarkAttendance(c *fiber.Ctx) error {
 	}
 
 	// If studentID not provided, use current user (but we need to get user first)
-	user, err := getUserAndCheckPermissions(c, req.StudentID)
+	user, err := getUserAndCheckPermissions(c)
 	if err != nil {
 		return err
 	}
@@ -66,9 +69,12 @@ func MarkAttendance(c *fiber.Ctx) error {
 	attendance, err := services.MarkAttendance(*req, user.StudentID, user.Role)
 	if err != nil {
 		// Map service-level access denial to HTTP 403
-		if err == services.ErrEventAccessDenied {
+		if err == services.ErrEventAccessDenied || err == services.ErrScanNotAllowed {
 			return c.Status(403).JSON(fiber.Map{"error": err.Error()})
 		}
+		if err == services.ErrTermClosed || err == services.ErrAccountNotActive {
+			return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
@@ -86,9 +92,17 @@ func GetAttendanceByEvent(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": utils.ErrInvalidEventID})
 	}
 
-	attendances, err := services.GetAttendanceByEvent(uint(eventID))
+	user, err := getUserFromContext(c)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
+	}
+
+	attendances, err := services.GetAttendanceByEvent(uint(eventID), user)
+	if err != nil {
+		if err == services.ErrForbidden {
+			return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
 	}
 
 	return c.JSON(fiber.Map{
@@ -101,7 +115,7 @@ func GetAttendanceByEvent(c *fiber.Ctx) error {
 func GetMyAttendance(c *fiber.Ctx) error {
 	user, ok := c.Locals("user").(models.User)
 	if !ok {
-		studentID := c.Get(utils.HeaderStudentID)
+		studentID := legacyStudentIDHeader(c)
 		if studentID == "" {
 			return c.Status(401).JSON(fiber.Map{"error": utils.ErrUnauthorized})
 		}
@@ -123,6 +137,13 @@ func GetMyAttendance(c *fiber.Ctx) error {
 	if endDate := c.Query("end_date"); endDate != "" {
 		filters["end_date"] = endDate
 	}
+	termID, err := parseTermFilter(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if termID != 0 {
+		filters["term_id"] = termID
+	}
 
 	attendances, err := services.GetAttendanceByStudent(user.StudentID, filters)
 	if err != nil {
@@ -148,16 +169,30 @@ func GetAttendanceStats(c *fiber.Ctx) error {
 		}
 	}
 
-	// If no studentID provided, try to get from context
+	var termID *uint
+	id, err := parseTermFilter(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if id != 0 {
+		termID = &id
+	}
+
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	// Without student_id, return the caller's own stats
 	if studentID == "" {
-		user, ok := c.Locals("user").(models.User)
-		if ok {
-			studentID = user.StudentID
-		}
+		studentID = user.StudentID
 	}
 
-	stats, err := services.GetAttendanceStats(studentID, eventID)
+	stats, err := services.GetAttendanceStats(user, studentID, eventID, termID)
 	if err != nil {
+		if err == services.ErrForbidden {
+			return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
@@ -190,7 +225,7 @@ func UpdateAttendanceStatus(c *fiber.Ctx) error {
 
 	user, ok := c.Locals("user").(models.User)
 	if !ok {
-		studentID := c.Get(utils.HeaderStudentID)
+		studentID := legacyStudentIDHeader(c)
 		if studentID == "" {
 			return c.Status(401).JSON(fiber.Map{"error": utils.ErrUnauthorized})
 		}
@@ -199,6 +234,9 @@ func UpdateAttendanceStatus(c *fiber.Ctx) error {
 
 	attendance, err := services.UpdateAttendanceStatus(uint(attendanceID), req.Status, req.Notes, user.StudentID)
 	if err != nil {
+		if err == services.ErrTermClosed {
+			return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"attendance-system/models"
+	"attendance-system/services"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// auditLogFilters reads the audit log filters shared by the list, export and
+// timeline endpoints. Errors are *fiber.Error for the error handler.
+func auditLogFilters(c *fiber.Ctx) (map[string]interface{}, error) {
+	filters := make(map[string]interface{})
+	for _, key := range []string{"action", "actor_id", "target_id", "ip_address", "q"} {
+		if v := c.Query(key); v != "" {
+			filters[key] = v
+		}
+	}
+	from, err := parseDateQuery(c, "from", false)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	to, err := parseDateQuery(c, "to", true)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	filters["start_date"], filters["end_date"] = from, to
+	return filters, nil
+}
+
+// auditLogPage reads the cursor and limit query parameters
+func auditLogPage(c *fiber.Ctx) (uint, int, error) {
+	var cursor uint
+	if v := c.Query("cursor"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return 0, 0, fiber.NewError(fiber.StatusBadRequest, "invalid cursor")
+		}
+		cursor = uint(n)
+	}
+	limit := c.QueryInt("limit", defaultPageSize)
+	if limit < 1 || limit > maxPageSize {
+		return 0, 0, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxPageSize))
+	}
+	return cursor, limit, nil
+}
+
+// SearchAuditLogs lists audit entries newest first (superadmin). Filters: action
+// (comma-separated), actor_id, target_id, ip_address, q, from and to. Pages are
+// fetched with ?cursor=<next_cursor>; ?format=csv or ?format=jsonl exports every match.
+func SearchAuditLogs(c *fiber.Ctx) error {
+	filters, err := auditLogFilters(c)
+	if err != nil {
+		return err
+	}
+
+	if format := c.Query("format"); format != "" && format != "json" {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return c.Status(401).JSON(fiber.Map{"error": models.ErrUnauthorized})
+		}
+		var contentType string
+		switch format {
+		case "csv":
+			contentType = "text/csv; charset=utf-8"
+		case "jsonl":
+			contentType = "application/x-ndjson"
+		default:
+			return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("invalid format %q. Use csv or jsonl", format)})
+		}
+		actor, ip := user.StudentID, c.IP()
+		return streamDownload(c, contentType, "audit-log-"+time.Now().Format("2006-01-02")+"."+format, func(w io.Writer) error {
+			_, err := services.ExportAuditLogs(w, format, filters, actor, ip)
+			return err
+		})
+	}
+
+	cursor, limit, err := auditLogPage(c)
+	if err != nil {
+		return err
+	}
+	logs, next, err := services.ListAuditLogs(filters, cursor, limit, false)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	total, err := services.CountAuditLogs(filters)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"total": total, "logs": logs, "next_cursor": next, "limit": limit})
+}
+
+// GetAuditTimeline lists everything that happened to one target (a student ID,
+// event, term, ...), oldest first unless ?order=desc. The list filters apply.
+func GetAuditTimeline(c *fiber.Ctx) error {
+	filters, err := auditLogFilters(c)
+	if err != nil {
+		return err
+	}
+	targetID := c.Params("target_id")
+	filters["target_id"] = targetID
+	cursor, limit, err := auditLogPage(c)
+	if err != nil {
+		return err
+	}
+	ascending := c.Query("order", "asc") != "desc"
+
+	logs, next, err := services.ListAuditLogs(filters, cursor, limit, ascending)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	total, err := services.CountAuditLogs(filters)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	resp := fiber.Map{"target_id": targetID, "total": total, "logs": logs, "next_cursor": next, "limit": limit}
+	// Student targets also show who the timeline is about
+	var target models.User
+	if err := services.GetUserByStudentID(targetID, &target); err == nil {
+		resp["user"] = models.NewUserResponse(target)
+	}
+	return c.JSON(resp)
+}
+
+// VerifyAuditLog walks the audit hash chain and reports the first break
+func VerifyAuditLog(c *fiber.Ctx) error {
+	report, err := services.VerifyAuditChain()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(report)
+}
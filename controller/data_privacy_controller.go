@@ -0,0 +1,174 @@
+package controller
+
+import (
+	"attendance-system/models"
+	"attendance-system/services"
+	"attendance-system/utils"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ExportMyData downloads a ZIP archive of the current user's personal data
+func ExportMyData(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": models.ErrUnauthorized})
+	}
+	if user.IsServiceAccount {
+		return c.Status(403).JSON(fiber.Map{"error": "Service accounts hold no personal data"})
+	}
+
+	archive, err := services.ExportPersonalData(user, c.IP())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="personal-data-`+time.Now().Format("2006-01-02")+`.zip"`)
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Send(archive)
+}
+
+// RequestErasure files a request to erase the current user's personal data.
+// The password is required as the user's confirmation.
+func RequestErasure(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": models.ErrUnauthorized})
+	}
+	var req models.ErasureRequestBody
+	if err := c.BodyParser(&req); err != nil || req.Password == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "password is required to confirm the request"})
+	}
+
+	if err := services.CheckLockout(models.LockoutScopeLogin, user.StudentID); err != nil {
+		return lockoutResponse(c, err)
+	}
+	if user.IsServiceAccount || utils.ComparePassword(user.Password, req.Password) != nil {
+		return failedAttempt(c, models.LockoutScopeLogin, user.StudentID, 400, "Password is incorrect")
+	}
+	services.ClearAuthFailures(models.LockoutScopeLogin, user.StudentID)
+
+	erasure, err := services.FileErasureRequest(user.StudentID, req.Reason, user.StudentID, c.IP())
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Erasure request received. An administrator will review it; you can cancel it until it is carried out.",
+		"request": erasure,
+	})
+}
+
+// GetMyErasureRequest returns the current user's open erasure request
+func GetMyErasureRequest(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": models.ErrUnauthorized})
+	}
+	erasure, err := services.GetOpenErasureRequest(user.StudentID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"request": erasure})
+}
+
+// CancelMyErasureRequest withdraws the current user's open erasure request
+func CancelMyErasureRequest(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": models.ErrUnauthorized})
+	}
+	if err := services.CancelErasureRequest(user.StudentID, c.IP()); err != nil {
+		if err == services.ErrNoErasureRequest {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Erasure request cancelled"})
+}
+
+// ListErasureRequests lists erasure requests (?status=pending|approved|...)
+func ListErasureRequests(c *fiber.Ctx) error {
+	requests, err := services.ListErasureRequests(c.Query("status"))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"requests": requests})
+}
+
+// CreateErasureRequest files an erasure request on a user's behalf (e.g. one received by email)
+func CreateErasureRequest(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": models.ErrUnauthorized})
+	}
+	var req models.ErasureRequestBody
+	if err := c.BodyParser(&req); err != nil || req.StudentID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "student_id is required"})
+	}
+	erasure, err := services.FileErasureRequest(req.StudentID, req.Reason, user.StudentID, c.IP())
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(201).JSON(fiber.Map{"request": erasure})
+}
+
+// ApproveErasureRequest and RejectErasureRequest review a pending request
+func ApproveErasureRequest(c *fiber.Ctx) error { return reviewErasureRequest(c, true) }
+
+func RejectErasureRequest(c *fiber.Ctx) error { return reviewErasureRequest(c, false) }
+
+func reviewErasureRequest(c *fiber.Ctx, approve bool) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": models.ErrUnauthorized})
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request ID"})
+	}
+	var req models.ErasureReviewRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": models.ErrInvalidRequest})
+		}
+	}
+
+	erasure, err := services.ReviewErasureRequest(uint(id), approve, req.Note, user.StudentID, c.IP())
+	if err != nil {
+		if err == services.ErrNoErasureRequest {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"request": erasure})
+}
+
+// ExecuteErasureRequest anonymizes the user of an approved request
+func ExecuteErasureRequest(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": models.ErrUnauthorized})
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request ID"})
+	}
+	var req models.ErasureExecuteRequest
+	if err := c.BodyParser(&req); err != nil || req.ConfirmStudentID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "confirm_student_id is required"})
+	}
+
+	erasure, err := services.ExecuteErasure(uint(id), req.ConfirmStudentID, user.StudentID, c.IP())
+	if err != nil {
+		if err == services.ErrNoErasureRequest {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{
+		"message": "Personal data erased. Attendance counts are kept under the pseudonym.",
+		"request": erasure,
+	})
+}
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"attendance-system/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListDevMailbox returns emails captured by the in-memory mail transport (dev only)
+// Optional query: ?to=someone@example.com
+func ListDevMailbox(c *fiber.Ctx) error {
+	mailbox := services.DevMailbox()
+	if mailbox == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Dev mailbox is not enabled"})
+	}
+
+	messages := mailbox.Messages(c.Query("to"))
+	return c.JSON(fiber.Map{
+		"messages": messages,
+		"count":    len(messages),
+	})
+}
+
+// GetDevMailboxMessage returns a single captured email (dev only)
+func GetDevMailboxMessage(c *fiber.Ctx) error {
+	mailbox := services.DevMailbox()
+	if mailbox == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Dev mailbox is not enabled"})
+	}
+
+	msg, err := mailbox.Message(c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// ?format=html renders the message body directly in the browser
+	if c.Query("format") == "html" && msg.HTMLBody != "" {
+		c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+		return c.SendString(msg.HTMLBody)
+	}
+
+	return c.JSON(fiber.Map{"message": msg})
+}
+
+// ClearDevMailbox removes all captured emails (dev only)
+func ClearDevMailbox(c *fiber.Ctx) error {
+	mailbox := services.DevMailbox()
+	if mailbox == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Dev mailbox is not enabled"})
+	}
+
+	mailbox.Clear()
+	return c.JSON(fiber.Map{"message": "Dev mailbox cleared"})
+}
@@ -32,7 +32,7 @@ func getUserFromContext(c *fiber.Ctx) (models.User, error) {
 	user, ok := c.Locals("user").(models.User)
 	if !ok {
 		// Fallback: try to get from query/header (for backward compatibility)
-		studentID := c.Get(utils.HeaderStudentID)
+		studentID := legacyStudentIDHeader(c)
 		if studentID == "" {
 			return models.User{}, fiber.NewError(401, utils.ErrUnauthorized)
 		}
@@ -83,8 +83,16 @@ func GetEvent(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": utils.ErrInvalidEventID})
 	}
 
-	event, err := services.GetEvent(uint(eventID))
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	event, err := services.GetEvent(uint(eventID), user)
 	if err != nil {
+		if err == services.ErrForbidden {
+			return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
 	}
 
@@ -112,6 +120,24 @@ func GetAllEvents(c *fiber.Ctx) error {
 	if isActive := c.Query("is_active"); isActive != "" {
 		filters["is_active"] = isActive == "true"
 	}
+	for _, key := range []string{"college_id", "department_id", "course_id", "section_id"} {
+		if id, err := strconv.ParseUint(c.Query(key), 10, 32); err == nil && id != 0 {
+			filters[key] = uint(id)
+		}
+	}
+	termID, err := parseTermFilter(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if termID != 0 {
+		filters["term_id"] = termID
+	}
+	// Drafts are only visible to their event staff and admins
+	if user, ok := c.Locals("user").(models.User); ok {
+		filters["viewer"] = user
+	} else {
+		filters["hide_drafts"] = true
+	}
 
 	events, err := services.GetAllEvents(filters)
 	if err != nil {
@@ -128,18 +154,18 @@ func GetAllEvents(c *fiber.Ctx) error {
 func GetMyEvents(c *fiber.Ctx) error {
 	user, ok := c.Locals("user").(models.User)
 	if !ok {
-		studentID := c.Get("X-Student-ID")
+		studentID := legacyStudentIDHeader(c)
 		if studentID == "" {
 			return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
 		}
 		user.StudentID = studentID
 	}
 
-	// Admins/faculty/superadmin should see all events (management view).
+	// Event organizers (event.create) get the management view of all events.
 	var events []models.Event
 	var err error
-	if user.Role == "superadmin" || user.Role == "admin" || user.Role == "faculty" {
-		events, err = services.GetAllEvents(map[string]interface{}{})
+	if services.HasPermission(user.Role, models.PermEventCreate) {
+		events, err = services.GetAllEvents(map[string]interface{}{"viewer": user})
 	} else {
 		events, err = services.GetEventsByStudent(user.StudentID)
 	}
@@ -170,7 +196,7 @@ func UpdateEvent(c *fiber.Ctx) error {
 
 	user, ok := c.Locals("user").(models.User)
 	if !ok {
-		studentID := c.Get(utils.HeaderStudentID)
+		studentID := legacyStudentIDHeader(c)
 		if studentID == "" {
 			return c.Status(401).JSON(fiber.Map{"error": utils.ErrUnauthorized})
 		}
@@ -198,14 +224,14 @@ func DeleteEvent(c *fiber.Ctx) error {
 
 	user, ok := c.Locals("user").(models.User)
 	if !ok {
-		studentID := c.Get(utils.HeaderStudentID)
+		studentID := legacyStudentIDHeader(c)
 		if studentID == "" {
 			return c.Status(401).JSON(fiber.Map{"error": utils.ErrUnauthorized})
 		}
 		user.StudentID = studentID
 	}
 
-	if err := services.DeleteEvent(uint(eventID), user.StudentID); err != nil {
+	if err := services.DeleteEvent(uint(eventID), user.StudentID, c.IP()); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
@@ -214,10 +240,225 @@ func DeleteEvent(c *fiber.Ctx) error {
 	})
 }
 
-// GetEventCreationDropdowns returns predefined sections and departments for event creation
+// GetEventCreationDropdowns returns the institution hierarchy for event creation
 func GetEventCreationDropdowns(c *fiber.Ctx) error {
-	return c.Status(200).JSON(fiber.Map{
-		"sections":    utils.Sections,
-		"departments": utils.Departments,
+	dropdowns, err := services.GetInstitutionDropdowns()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load dropdowns"})
+	}
+	return c.Status(200).JSON(dropdowns)
+}
+
+// OptOutEventReminders stops reminder emails for this event for the current user
+func OptOutEventReminders(c *fiber.Ctx) error {
+	eventID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": utils.ErrInvalidEventID})
+	}
+
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	if err := services.OptOutOfEventReminders(uint(eventID), user.StudentID); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "You will no longer receive reminders for this event"})
+}
+
+// OptInEventReminders re-enables reminder emails for this event for the current user
+func OptInEventReminders(c *fiber.Ctx) error {
+	eventID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": utils.ErrInvalidEventID})
+	}
+
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	if err := services.OptInToEventReminders(uint(eventID), user.StudentID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Reminders enabled for this event"})
+}
+
+// OptOutEventRemindersByToken handles the one-click opt-out link in reminder emails
+// Request: GET /reminders/opt-out?token=<token_from_email>
+func OptOutEventRemindersByToken(c *fiber.Ctx) error {
+	claims, err := services.VerifyReminderOptOutToken(c.Query("token"))
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "Invalid or expired link"})
+	}
+
+	if err := services.OptOutOfEventReminders(claims.EventID, claims.StudentID); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":  "You will no longer receive reminders for this event",
+		"event_id": claims.EventID,
 	})
 }
+
+// eventTransitionHandler wraps a lifecycle action (start/end/publish/reopen)
+// with the common ID parsing, auth and optional {"reason": "..."} body.
+func eventTransitionHandler(action func(uint, services.EventTransition) (*models.Event, error), message string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		eventID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": utils.ErrInvalidEventID})
+		}
+
+		user, err := getUserFromContext(c)
+		if err != nil {
+			return err
+		}
+
+		req := new(models.EventTransitionRequest)
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(req); err != nil {
+				return c.Status(400).JSON(fiber.Map{"error": "Invalid request format"})
+			}
+		}
+
+		event, err := action(uint(eventID), services.EventTransition{
+			ActorID:   user.StudentID,
+			IPAddress: c.IP(),
+			Reason:    req.Reason,
+		})
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		return c.JSON(fiber.Map{
+			"message": message,
+			"event":   event,
+		})
+	}
+}
+
+// StartEvent opens an event for check-in now (may be before its start time)
+var StartEvent = eventTransitionHandler(services.StartEvent, "Event started")
+
+// EndEvent completes an event now (may be before or after its end time)
+var EndEvent = eventTransitionHandler(services.EndEvent, "Event ended")
+
+// PublishEvent publishes a draft event
+var PublishEvent = eventTransitionHandler(services.PublishEvent, "Event published")
+
+// ReopenEvent reopens a completed or cancelled event
+var ReopenEvent = eventTransitionHandler(services.ReopenEvent, "Event reopened")
+
+// PostponeEvent moves an event to a new date/time
+// Request: {"event_date": "2025-01-20", "start_time": "09:00", "end_time": "11:00", "reason": "..."}
+func PostponeEvent(c *fiber.Ctx) error {
+	eventID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": utils.ErrInvalidEventID})
+	}
+
+	req := new(models.EventPostponeRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request format"})
+	}
+	if req.EventDate == "" || req.StartTime == "" || req.EndTime == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "event_date, start_time, and end_time are required"})
+	}
+
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	event, err := services.PostponeEvent(uint(eventID), *req, services.EventTransition{
+		ActorID:   user.StudentID,
+		IPAddress: c.IP(),
+	})
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Event postponed",
+		"event":   event,
+	})
+}
+
+// ListEventStaff returns co-organizers and scanners assigned to an event
+func ListEventStaff(c *fiber.Ctx) error {
+	eventID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": utils.ErrInvalidEventID})
+	}
+
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	staff, err := services.ListEventStaff(uint(eventID), user)
+	if err != nil {
+		return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"staff": staff,
+		"count": len(staff),
+	})
+}
+
+// AssignEventStaff adds or updates a staff assignment (event owner only)
+// Request: {"student_id": "2024-00123", "role": "scanner"}
+func AssignEventStaff(c *fiber.Ctx) error {
+	eventID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": utils.ErrInvalidEventID})
+	}
+
+	req := new(models.EventStaffRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if req.StudentID == "" || req.Role == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "student_id and role are required"})
+	}
+
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	staff, err := services.AssignEventStaff(uint(eventID), *req, user, c.IP())
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Event staff assigned",
+		"staff":   staff,
+	})
+}
+
+// RemoveEventStaff removes a staff assignment (event owner only)
+func RemoveEventStaff(c *fiber.Ctx) error {
+	eventID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": utils.ErrInvalidEventID})
+	}
+
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	if err := services.RemoveEventStaff(uint(eventID), c.Params("student_id"), user, c.IP()); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Event staff removed"})
+}
@@ -0,0 +1,195 @@
+// controller/event_template_controller.go
+package controller
+
+import (
+	"attendance-system/models"
+	"attendance-system/services"
+	"attendance-system/utils"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// parseTemplateID reads the :id route param of a template
+func parseTemplateID(c *fiber.Ctx) (uint, error) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return 0, fiber.NewError(400, "Invalid template ID")
+	}
+	return uint(id), nil
+}
+
+// CreateEventTemplate saves a new event template
+// Request: {"name": "Weekly lab", "shared": true, "title": "...", "start_time": "09:00", ...}
+// or {"name": "...", "from_event_id": 12} to start from an existing event
+func CreateEventTemplate(c *fiber.Ctx) error {
+	req := new(models.EventTemplateRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := services.CreateEventTemplate(*req, user)
+	if err != nil {
+		if err == services.ErrForbidden {
+			return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message":  "Event template created successfully",
+		"template": tmpl,
+	})
+}
+
+// ListEventTemplates returns the user's templates and those shared with their department
+func ListEventTemplates(c *fiber.Ctx) error {
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	templates, err := services.ListEventTemplates(user)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"templates": templates,
+		"count":     len(templates),
+	})
+}
+
+// GetEventTemplate returns a single template
+func GetEventTemplate(c *fiber.Ctx) error {
+	templateID, err := parseTemplateID(c)
+	if err != nil {
+		return err
+	}
+
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := services.GetEventTemplate(templateID, user)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"template": tmpl})
+}
+
+// UpdateEventTemplate updates a template (owner or admin)
+func UpdateEventTemplate(c *fiber.Ctx) error {
+	templateID, err := parseTemplateID(c)
+	if err != nil {
+		return err
+	}
+
+	req := new(models.EventTemplateRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request format"})
+	}
+
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := services.UpdateEventTemplate(templateID, *req, user)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":  "Event template updated successfully",
+		"template": tmpl,
+	})
+}
+
+// DeleteEventTemplate deletes a template (owner or admin)
+func DeleteEventTemplate(c *fiber.Ctx) error {
+	templateID, err := parseTemplateID(c)
+	if err != nil {
+		return err
+	}
+
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	if err := services.DeleteEventTemplate(templateID, user); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Event template deleted successfully"})
+}
+
+// CreateEventFromTemplate creates an event from a template
+// Request: {"event_date": "2025-01-20", "start_time": "09:00", "end_time": "11:00", "draft": false}
+// start_time/end_time are optional when the template has them
+func CreateEventFromTemplate(c *fiber.Ctx) error {
+	templateID, err := parseTemplateID(c)
+	if err != nil {
+		return err
+	}
+
+	req := new(models.EventFromTemplateRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	event, err := services.CreateEventFromTemplate(templateID, *req, user)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Event created successfully",
+		"event":   event,
+	})
+}
+
+// CloneEvent copies an event into a new draft on another date
+// Request: {"event_date": "2025-01-27"} or {"shift_days": 7}
+func CloneEvent(c *fiber.Ctx) error {
+	eventID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": utils.ErrInvalidEventID})
+	}
+
+	req := new(models.CloneEventRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	event, err := services.CloneEvent(uint(eventID), *req, user)
+	if err != nil {
+		if err == services.ErrForbidden {
+			return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Event cloned as draft",
+		"event":   event,
+	})
+}
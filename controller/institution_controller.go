@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"attendance-system/models"
+	"attendance-system/services"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const errUnknownInstitutionLevel = "Unknown level. Use colleges, departments, courses or sections"
+
+// ListInstitutionItems lists one level of the institution hierarchy (superadmin only)
+// Optional query: ?parent_id=1&include_inactive=true
+func ListInstitutionItems(c *fiber.Ctx) error {
+	level := c.Params("level")
+	if !services.IsInstitutionLevel(level) {
+		return c.Status(404).JSON(fiber.Map{"error": errUnknownInstitutionLevel})
+	}
+
+	items, err := services.ListInstitutionItems(level, uint(c.QueryInt("parent_id", 0)), c.QueryBool("include_inactive", false))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{level: items})
+}
+
+// CreateInstitutionItem adds a college, department, course or section (superadmin only)
+func CreateInstitutionItem(c *fiber.Ctx) error {
+	level := c.Params("level")
+	if !services.IsInstitutionLevel(level) {
+		return c.Status(404).JSON(fiber.Map{"error": errUnknownInstitutionLevel})
+	}
+
+	var req models.InstitutionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	item, err := services.CreateInstitutionItem(level, req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Entry created successfully",
+		"item":    item,
+	})
+}
+
+// UpdateInstitutionItem renames, moves or (de)activates an entry (superadmin only)
+func UpdateInstitutionItem(c *fiber.Ctx) error {
+	level := c.Params("level")
+	if !services.IsInstitutionLevel(level) {
+		return c.Status(404).JSON(fiber.Map{"error": errUnknownInstitutionLevel})
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	var req models.InstitutionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	item, err := services.UpdateInstitutionItem(level, uint(id), req)
+	if err != nil {
+		if err.Error() == "entry not found" {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Entry updated successfully",
+		"item":    item,
+	})
+}
+
+// DeactivateInstitutionItem hides an entry from dropdowns and validation (superadmin only)
+func DeactivateInstitutionItem(c *fiber.Ctx) error {
+	level := c.Params("level")
+	if !services.IsInstitutionLevel(level) {
+		return c.Status(404).JSON(fiber.Map{"error": errUnknownInstitutionLevel})
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	if err := services.DeactivateInstitutionItem(level, uint(id)); err != nil {
+		if err.Error() == "entry not found" {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Entry deactivated successfully"})
+}
@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"attendance-system/models"
+	"attendance-system/services"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListJobs returns registered background jobs with their last run (superadmin only)
+func ListJobs(c *fiber.Ctx) error {
+	jobs, err := services.ListJobs()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"jobs":   jobs,
+		"count":  len(jobs),
+		"leader": services.IsJobSchedulerLeader(),
+	})
+}
+
+// TriggerJob runs a background job immediately (superadmin only)
+func TriggerJob(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	run, err := services.TriggerJob(c.Params("name"), user.StudentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrJobNotFound):
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, services.ErrJobAlreadyRunning):
+			return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(202).JSON(fiber.Map{
+		"message": "Job started",
+		"run":     run,
+	})
+}
+
+// GetJobRuns returns the recent run history of a job (superadmin only)
+// Optional query: ?limit=20
+func GetJobRuns(c *fiber.Ctx) error {
+	runs, err := services.GetJobRuns(c.Params("name"), c.QueryInt("limit", 20))
+	if err != nil {
+		if errors.Is(err, services.ErrJobNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"runs":  runs,
+		"count": len(runs),
+	})
+}
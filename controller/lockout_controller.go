@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"attendance-system/models"
+	"attendance-system/services"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// lockoutResponse answers 429 with Retry-After for a locked identifier
+func lockoutResponse(c *fiber.Ctx, err error) error {
+	var locked *services.LockoutError
+	if !errors.As(err, &locked) {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(locked.RetryAfterSeconds()))
+	return c.Status(429).JSON(fiber.Map{
+		"error":        err.Error(),
+		"locked_until": locked.Until,
+	})
+}
+
+// failedAttempt counts a failed attempt and returns the given error, or the
+// lockout response when this attempt locked the identifier
+func failedAttempt(c *fiber.Ctx, scope, identifier string, status int, message string) error {
+	if err := services.RecordAuthFailure(scope, identifier, c.IP()); err != nil {
+		return lockoutResponse(c, err)
+	}
+	return c.Status(status).JSON(fiber.Map{"error": message})
+}
+
+// ListLockouts lists identifiers currently locked after failed attempts
+func ListLockouts(c *fiber.Ctx) error {
+	lockouts, err := services.ListLockouts()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"lockouts": lockouts})
+}
+
+// UnlockAccount clears a user's lockouts and failed-attempt counters
+func UnlockAccount(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var target models.User
+	if err := services.GetUserByStudentID(c.Params("student_id"), &target); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": models.ErrUserNotFound})
+	}
+	if !services.CanGrantRole(user.Role, target.Role) {
+		return c.Status(403).JSON(fiber.Map{"error": "You cannot unlock a user with more permissions than you"})
+	}
+
+	cleared, err := services.UnlockAccount(target.StudentID, user.StudentID, c.IP())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{
+		"message":          "Account unlocked successfully",
+		"counters_cleared": cleared,
+	})
+}
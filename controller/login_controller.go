@@ -25,45 +25,94 @@ func Login(c *fiber.Ctx) error {
 	if strings.Contains(req.StudentID, "@") {
 		// login by email
 		if err := services.GetUserByEmail(req.StudentID, &user); err != nil {
-			return c.Status(401).JSON(fiber.Map{"error": models.ErrInvalidCredentials})
+			return unknownLoginIdentifier(c, req.StudentID, models.ErrInvalidCredentials)
 		}
 	} else {
 		sid := utils.SanitizeStudentID(req.StudentID)
 		if err := services.GetUserByStudentID(sid, &user); err != nil {
-			return c.Status(401).JSON(fiber.Map{"error": models.ErrInvalidStudentID})
+			return unknownLoginIdentifier(c, sid, models.ErrInvalidStudentID)
 		}
 	}
 
-	// Verify password
-	if err := utils.ComparePassword(user.Password, req.Password); err != nil {
-		return c.Status(401).JSON(fiber.Map{"error": models.ErrInvalidStudentID})
+	// Failed attempts are counted per account, whichever identifier was used
+	if err := services.CheckLockout(models.LockoutScopeLogin, user.StudentID); err != nil {
+		return lockoutResponse(c, err)
 	}
 
+	// Verify password (service accounts have none)
+	if user.IsServiceAccount || utils.ComparePassword(user.Password, req.Password) != nil {
+		return failedAttempt(c, models.LockoutScopeLogin, user.StudentID, 401, models.ErrInvalidStudentID)
+	}
+	services.ClearAuthFailures(models.LockoutScopeLogin, user.StudentID)
+
 	// Check if verified
 	if !user.IsVerified {
 		return c.Status(403).JSON(fiber.Map{"error": models.ErrEmailNotVerified})
 	}
+	if err := services.CheckAccountAccess(user); err != nil {
+		return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+	}
 
-	accessToken, err := services.GenerateAccessToken(user)
-	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": models.ErrFailedGenerateAccessToken})
+	return completeLogin(c, user)
+}
+
+// unknownLoginIdentifier counts a login attempt for an unknown email or
+// student ID so identifiers cannot be probed without limit
+func unknownLoginIdentifier(c *fiber.Ctx, identifier, message string) error {
+	if err := services.CheckLockout(models.LockoutScopeLogin, identifier); err != nil {
+		return lockoutResponse(c, err)
+	}
+	return failedAttempt(c, models.LockoutScopeLogin, identifier, 401, message)
+}
+
+// completeLogin answers a successful password check: with a 2FA challenge
+// when the user has a second factor or their role requires one, otherwise
+// with a new session
+func completeLogin(c *fiber.Ctx, user models.User) error {
+	purpose := ""
+	switch {
+	case services.IsTwoFactorEnabled(user.StudentID):
+		purpose = models.TwoFactorPurposeLogin
+	case services.TwoFactorRequired(user.Role):
+		purpose = models.TwoFactorPurposeSetup
+	}
+	if purpose != "" {
+		challenge, err := services.GenerateTwoFactorChallengeToken(user.StudentID, purpose)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": models.ErrFailedGenerateAccessToken})
+		}
+		return c.JSON(fiber.Map{
+			"message":                   "Password accepted. A second factor is required.",
+			"two_factor_required":       purpose == models.TwoFactorPurposeLogin,
+			"two_factor_setup_required": purpose == models.TwoFactorPurposeSetup,
+			"challenge_token":           challenge,
+		})
 	}
 
-	refreshToken, err := services.GenerateRefreshToken(user)
+	return startLoginSession(c, user, nil)
+}
+
+// startLoginSession opens a session and returns the login response
+func startLoginSession(c *fiber.Ctx, user models.User, extra fiber.Map) error {
+	tokens, err := services.StartSession(user, c.Get("User-Agent"), c.IP())
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": models.ErrFailedGenerateRefreshToken})
 	}
 
-	return c.JSON(fiber.Map{
+	resp := fiber.Map{
 		"message":       models.SuccessLoginFacultyAdmin,
 		"student_id":    user.StudentID,
 		"email":         user.Email,
 		"role":          user.Role,
 		"first_name":    user.FirstName,
 		"last_name":     user.LastName,
-		"access_token":  accessToken,
-		"refresh_token": refreshToken,
-	})
+		"access_token":  tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+	}
+	for k, v := range extra {
+		resp[k] = v
+	}
+	return c.JSON(resp)
 }
 
 // LoginByEmail adds login by email endpoint
@@ -85,42 +134,32 @@ func LoginByEmail(c *fiber.Ctx) error {
 	// Get user by email
 	var user models.User
 	if err := services.GetUserByEmail(req.Email, &user); err != nil {
-		return c.Status(401).JSON(fiber.Map{"error": models.ErrInvalidCredentials})
+		return unknownLoginIdentifier(c, req.Email, models.ErrInvalidCredentials)
 	}
 
-	// Verify password
-	if err := utils.ComparePassword(user.Password, req.Password); err != nil {
-		return c.Status(401).JSON(fiber.Map{"error": models.ErrInvalidCredentials})
+	if err := services.CheckLockout(models.LockoutScopeLogin, user.StudentID); err != nil {
+		return lockoutResponse(c, err)
 	}
 
+	// Verify password (service accounts have none)
+	if user.IsServiceAccount || utils.ComparePassword(user.Password, req.Password) != nil {
+		return failedAttempt(c, models.LockoutScopeLogin, user.StudentID, 401, models.ErrInvalidCredentials)
+	}
+	services.ClearAuthFailures(models.LockoutScopeLogin, user.StudentID)
+
 	// Check if verified
 	if !user.IsVerified {
 		return c.Status(403).JSON(fiber.Map{"error": models.ErrEmailNotVerified})
 	}
-
-	accessToken, err := services.GenerateAccessToken(user)
-	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": models.ErrFailedGenerateAccessToken})
+	if err := services.CheckAccountAccess(user); err != nil {
+		return c.Status(403).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	refreshToken, err := services.GenerateRefreshToken(user)
-	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": models.ErrFailedGenerateRefreshToken})
-	}
-
-	return c.JSON(fiber.Map{
-		"message":       models.SuccessLoginFacultyAdmin,
-		"email":         user.Email,
-		"student_id":    user.StudentID,
-		"role":          user.Role,
-		"first_name":    user.FirstName,
-		"last_name":     user.LastName,
-		"access_token":  accessToken,
-		"refresh_token": refreshToken,
-	})
+	return completeLogin(c, user)
 }
 
-// RefreshToken generates a new access token from a valid refresh token
+// RefreshToken exchanges a refresh token for a new access and refresh token.
+// The old refresh token stops working; replaying it revokes the session.
 func RefreshToken(c *fiber.Ctx) error {
 	type RefreshRequest struct {
 		RefreshToken string `json:"refresh_token"`
@@ -135,27 +174,25 @@ func RefreshToken(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": models.ErrTokenRequired})
 	}
 
-	// Verify refresh token
-	claims, err := services.VerifyRefreshToken(req.RefreshToken)
-	if err != nil {
-		return c.Status(401).JSON(fiber.Map{"error": models.ErrRefreshTokenExpired})
-	}
-
-	// Get user
-	var user models.User
-	if err := services.GetUserByStudentID(claims.StudentID, &user); err != nil {
-		return c.Status(404).JSON(fiber.Map{"error": models.ErrUserNotFound})
-	}
-
-	// Generate new access token
-	accessToken, err := services.GenerateAccessToken(user)
+	tokens, err := services.RotateRefreshToken(req.RefreshToken, c.Get("User-Agent"), c.IP())
 	if err != nil {
+		switch {
+		case err == services.ErrInvalidToken:
+			return c.Status(401).JSON(fiber.Map{"error": models.ErrRefreshTokenExpired})
+		case err == services.ErrRefreshTokenReused, err == services.ErrTwoFactorRequired:
+			return c.Status(401).JSON(fiber.Map{"error": err.Error()})
+		case err == services.ErrAccountSuspended, err == services.ErrAccountDeactivated, err == services.ErrAccountDeleted:
+			return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+		case err.Error() == models.ErrUserNotFound:
+			return c.Status(404).JSON(fiber.Map{"error": models.ErrUserNotFound})
+		}
 		return c.Status(500).JSON(fiber.Map{"error": models.ErrFailedGenerateAccessToken})
 	}
 
 	return c.JSON(fiber.Map{
-		"message":      models.SuccessTokenRefreshed,
-		"access_token": accessToken,
+		"message":       models.SuccessTokenRefreshed,
+		"access_token":  tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
 	})
 }
 
@@ -167,12 +204,5 @@ func GetProfile(c *fiber.Ctx) error {
 		return c.Status(401).JSON(fiber.Map{"error": models.ErrUnauthorized})
 	}
 
-	return c.JSON(fiber.Map{
-		"message":    models.SuccessProfileFetched,
-		"student_id": user.StudentID,
-		"email":      user.Email,
-		"role":       user.Role,
-		"first_name": user.FirstName,
-		"last_name":  user.LastName,
-	})
+	return c.JSON(services.GetProfile(user))
 }
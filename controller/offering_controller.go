@@ -0,0 +1,250 @@
+// controller/offering_controller.go
+package controller
+
+import (
+	"attendance-system/models"
+	"attendance-system/services"
+	"bytes"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// parseOfferingID reads the :id route param of a class offering
+func parseOfferingID(c *fiber.Ctx) (uint, error) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return 0, fiber.NewError(400, "Invalid offering ID")
+	}
+	return uint(id), nil
+}
+
+// offeringError maps offering service errors to HTTP responses
+func offeringError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, services.ErrOfferingNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+}
+
+// CreateClassOffering adds a class offering
+// Request: {"subject_code": "IT-204", "subject_title": "...", "section": "B", "term_id": 3}
+func CreateClassOffering(c *fiber.Ctx) error {
+	req := new(models.ClassOfferingRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	offering, err := services.CreateClassOffering(*req, user)
+	if err != nil {
+		return offeringError(c, err)
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message":  "Class offering created successfully",
+		"offering": offering,
+	})
+}
+
+// ListClassOfferings returns the user's offerings (all for admins)
+// Optional query: ?term_id=<id|active>
+func ListClassOfferings(c *fiber.Ctx) error {
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return err
+	}
+	termID, err := parseTermFilter(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	offerings, err := services.ListClassOfferings(user, termID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"offerings": offerings,
+		"count":     len(offerings),
+	})
+}
+
+// GetClassOffering returns one offering
+func GetClassOffering(c *fiber.Ctx) error {
+	offeringID, err := parseOfferingID(c)
+	if err != nil {
+		return err
+	}
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	offering, err := services.GetClassOffering(offeringID, user)
+	if err != nil {
+		return offeringError(c, err)
+	}
+	return c.JSON(fiber.Map{"offering": offering})
+}
+
+// UpdateClassOffering updates an offering's details
+func UpdateClassOffering(c *fiber.Ctx) error {
+	offeringID, err := parseOfferingID(c)
+	if err != nil {
+		return err
+	}
+	req := new(models.ClassOfferingRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	offering, err := services.UpdateClassOffering(offeringID, *req, user)
+	if err != nil {
+		return offeringError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message":  "Class offering updated successfully",
+		"offering": offering,
+	})
+}
+
+// DeleteClassOffering deactivates an offering
+func DeleteClassOffering(c *fiber.Ctx) error {
+	offeringID, err := parseOfferingID(c)
+	if err != nil {
+		return err
+	}
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	if err := services.DeactivateClassOffering(offeringID, user); err != nil {
+		return offeringError(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "Class offering deactivated successfully"})
+}
+
+// ListEnrollments returns the offering's enrollment list
+func ListEnrollments(c *fiber.Ctx) error {
+	offeringID, err := parseOfferingID(c)
+	if err != nil {
+		return err
+	}
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	enrollments, err := services.ListEnrollments(offeringID, user)
+	if err != nil {
+		return offeringError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"enrollments": enrollments,
+		"count":       len(enrollments),
+	})
+}
+
+// EnrollStudents adds students to an offering
+// Request: {"student_ids": ["2024-0001", "2024-0002"]}
+func EnrollStudents(c *fiber.Ctx) error {
+	offeringID, err := parseOfferingID(c)
+	if err != nil {
+		return err
+	}
+	req := new(models.EnrollmentRequest)
+	if err := c.BodyParser(req); err != nil || len(req.StudentIDs) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "student_ids is required"})
+	}
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := services.EnrollStudents(offeringID, req.StudentIDs, user, c.IP())
+	if err != nil {
+		return offeringError(c, err)
+	}
+	return c.JSON(fiber.Map{"result": result})
+}
+
+// ImportEnrollments enrolls the students listed in a CSV upload (form field
+// "file") or a text/csv request body
+func ImportEnrollments(c *fiber.Ctx) error {
+	offeringID, err := parseOfferingID(c)
+	if err != nil {
+		return err
+	}
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	var result *models.EnrollmentResult
+	if fileHeader, ferr := c.FormFile("file"); ferr == nil {
+		file, err := fileHeader.Open()
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Failed to read uploaded file"})
+		}
+		defer file.Close()
+		result, err = services.ImportEnrollmentsCSV(offeringID, file, user, c.IP())
+	} else {
+		result, err = services.ImportEnrollmentsCSV(offeringID, bytes.NewReader(c.Body()), user, c.IP())
+	}
+	if err != nil {
+		return offeringError(c, err)
+	}
+	return c.JSON(fiber.Map{"result": result})
+}
+
+// UnenrollStudent removes a student from an offering
+func UnenrollStudent(c *fiber.Ctx) error {
+	offeringID, err := parseOfferingID(c)
+	if err != nil {
+		return err
+	}
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	if err := services.UnenrollStudent(offeringID, c.Params("student_id"), user, c.IP()); err != nil {
+		return offeringError(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "Student unenrolled successfully"})
+}
+
+// GetOfferingReport returns per-student attendance across the offering's completed events
+func GetOfferingReport(c *fiber.Ctx) error {
+	offeringID, err := parseOfferingID(c)
+	if err != nil {
+		return err
+	}
+	user, err := getUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	report, err := services.GetOfferingAttendanceReport(offeringID, user)
+	if err != nil {
+		return offeringError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"report": report,
+		"count":  len(report),
+	})
+}
@@ -45,7 +45,7 @@ func ForgotPassword(c *fiber.Ctx) error {
 // VerifyResetCode verifies the reset code using bearer token
 // Request Header: Authorization: Bearer <token_from_forgot_password>
 // Request: { "code": "123456" }  <- code received in email
-// Response: { "message": "Code is valid", "status": "success" }
+// Response: { "message": "Code is valid", "status": "success", "token": "<token for /reset-password>" }
 func VerifyResetCode(c *fiber.Ctx) error {
 	type VerifyRequest struct {
 		Code string `json:"code"`
@@ -80,23 +80,34 @@ func VerifyResetCode(c *fiber.Ctx) error {
 		return c.Status(401).JSON(fiber.Map{"error": "Invalid or expired token"})
 	}
 
-	// Verify the code in database with the email from token
-	err = services.VerifyResetCodeDB(claims.Email, req.Code)
+	// Verify the code in database with the email from token; repeated
+	// failures lock the email and cancel its codes
+	if err := services.CheckLockout(models.LockoutScopeResetCode, claims.Email); err != nil {
+		return lockoutResponse(c, err)
+	}
+	verified, err := services.VerifyResetCode(claims.Email, req.Code)
+	if err == services.ErrInvalidResetCode {
+		return failedAttempt(c, models.LockoutScopeResetCode, claims.Email, 400, "Invalid or expired code")
+	}
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid or expired code"})
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to verify code"})
 	}
+	services.ClearAuthFailures(models.LockoutScopeResetCode, claims.Email)
 
-	// Code is valid
+	// Code is valid; only this token lets /reset-password skip the code
 	return c.JSON(fiber.Map{
 		"message": SuccessCodeValid,
 		"status":  "success",
 		"email":   claims.Email,
-		"token":   auth[len("Bearer "):], // Return the same token for /reset-password
+		"token":   verified,
 	})
 }
 
+// ResetPassword sets a new password. The bearer token is either the one from
+// /verify-reset-code, or the one from /forgot-password together with "code".
 func ResetPassword(c *fiber.Ctx) error {
 	type ResetPasswordRequest struct {
+		Code               string `json:"code"`
 		NewPassword        string `json:"new_password"`
 		ConfirmNewPassword string `json:"confirm_new_password"`
 	}
@@ -139,14 +150,32 @@ func ResetPassword(c *fiber.Ctx) error {
 		return c.Status(401).JSON(fiber.Map{"error": "Invalid or expired token"})
 	}
 
-	// Reset password using email from token (code already verified in /verify-reset-code)
-	err = services.ResetPasswordWithCode(claims.Email, claims.Code, req.NewPassword)
+	if err := services.CheckLockout(models.LockoutScopeResetCode, claims.Email); err != nil {
+		return lockoutResponse(c, err)
+	}
+
+	if claims.ResetID != 0 {
+		// Code already verified in /verify-reset-code
+		err = services.ResetPasswordWithVerifiedReset(claims.Email, claims.ResetID, req.NewPassword)
+	} else {
+		if req.Code == "" {
+			return c.Status(400).JSON(fiber.Map{"error": ErrCodeRequired})
+		}
+		err = services.ResetPasswordWithCode(claims.Email, req.Code, req.NewPassword)
+		if err == services.ErrInvalidResetCode {
+			return failedAttempt(c, models.LockoutScopeResetCode, claims.Email, 400, "Invalid or expired code")
+		}
+	}
 	if err != nil {
-		if err.Error() == "invalid or expired reset code" {
+		if err == services.ErrInvalidResetCode {
 			return c.Status(400).JSON(fiber.Map{"error": "Invalid or expired code"})
 		}
+		if err == services.ErrPasswordReused {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to reset password"})
 	}
+	services.ClearAuthFailures(models.LockoutScopeResetCode, claims.Email)
 
 	return c.JSON(fiber.Map{
 		"message": SuccessPasswordReset,
@@ -154,6 +183,54 @@ func ResetPassword(c *fiber.Ctx) error {
 	})
 }
 
+// ChangePassword changes the logged-in user's password
+// Request: { "current_password", "new_password", "confirm_new_password" }
+// Other sessions are logged out; the current one stays valid.
+func ChangePassword(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": models.ErrUnauthorized})
+	}
+
+	req := new(models.ChangePasswordRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": ErrInvalidRequest})
+	}
+	if req.CurrentPassword == "" || req.NewPassword == "" || req.ConfirmNewPassword == "" {
+		return c.Status(400).JSON(fiber.Map{"error": ErrAllFieldsRequired})
+	}
+	if req.NewPassword != req.ConfirmNewPassword {
+		return c.Status(400).JSON(fiber.Map{"error": "Passwords do not match"})
+	}
+	if valid, msg := utils.ValidatePassword(req.NewPassword); !valid {
+		return c.Status(400).JSON(fiber.Map{"error": msg})
+	}
+
+	// Wrong current passwords count towards the login lockout
+	if err := services.CheckLockout(models.LockoutScopeLogin, user.StudentID); err != nil {
+		return lockoutResponse(c, err)
+	}
+
+	sessionID, _ := c.Locals("session_id").(string)
+	err := services.ChangePassword(user.StudentID, req.CurrentPassword, req.NewPassword, sessionID, c.IP())
+	switch {
+	case err == services.ErrWrongCurrentPassword:
+		return failedAttempt(c, models.LockoutScopeLogin, user.StudentID, 400, "Current password is incorrect")
+	case err == services.ErrPasswordReused:
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	case err != nil && err.Error() == models.ErrUserNotFound:
+		return c.Status(404).JSON(fiber.Map{"error": models.ErrUserNotFound})
+	case err != nil:
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to change password"})
+	}
+	services.ClearAuthFailures(models.LockoutScopeLogin, user.StudentID)
+
+	return c.JSON(fiber.Map{
+		"message": "Password changed successfully. Other sessions have been logged out.",
+		"status":  "success",
+	})
+}
+
 func ResendCode(c *fiber.Ctx) error {
 	type Request struct {
 		Email string `json:"email"`
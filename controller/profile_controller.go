@@ -0,0 +1,200 @@
+package controller
+
+import (
+	"attendance-system/models"
+	"attendance-system/services"
+	"fmt"
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// UpdateProfile lets users edit their own profile. Empty fields are left
+// unchanged; a new email only applies after POST /profile/email/verify.
+func UpdateProfile(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": models.ErrUnauthorized})
+	}
+	if user.IsServiceAccount {
+		return c.Status(403).JSON(fiber.Map{"error": "Service accounts have no editable profile"})
+	}
+
+	var req models.UpdateUserRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": models.ErrInvalidRequest})
+	}
+
+	updated, emailPending, err := services.UpdateProfile(user, req, c.IP())
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	profile := services.GetProfile(*updated)
+	profile.Message = "Profile updated successfully"
+	if emailPending {
+		profile.Message = "Profile updated. Enter the code sent to your new email address to confirm it."
+	}
+	return c.JSON(profile)
+}
+
+// VerifyEmailChange confirms a new email address with the emailed code
+func VerifyEmailChange(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": models.ErrUnauthorized})
+	}
+
+	var req models.VerifyEmailChangeRequest
+	if err := c.BodyParser(&req); err != nil || req.Code == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Code is required"})
+	}
+
+	if err := services.CheckLockout(models.LockoutScopeVerifyEmail, user.StudentID); err != nil {
+		return lockoutResponse(c, err)
+	}
+	email, err := services.ConfirmEmailChange(user, req.Code, c.IP())
+	switch {
+	case err == services.ErrInvalidEmailChangeCode:
+		return failedAttempt(c, models.LockoutScopeVerifyEmail, user.StudentID, 400, "Invalid verification code")
+	case err == services.ErrNoEmailChange:
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	case err != nil:
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	services.ClearAuthFailures(models.LockoutScopeVerifyEmail, user.StudentID)
+
+	return c.JSON(fiber.Map{
+		"message": "Email address changed successfully",
+		"email":   email,
+	})
+}
+
+// CancelEmailChange discards a pending email change
+func CancelEmailChange(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": models.ErrUnauthorized})
+	}
+	if err := services.CancelEmailChange(user.StudentID); err != nil {
+		if err == services.ErrNoEmailChange {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Email change cancelled"})
+}
+
+// ListProfileLocks lists the profile fields users cannot edit themselves
+func ListProfileLocks(c *fiber.Ctx) error {
+	locks, err := services.ListProfileLocks()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{
+		"locks":           locks,
+		"lockable_fields": models.LockableProfileFields,
+	})
+}
+
+// SetProfileLocks replaces the locked profile fields
+func SetProfileLocks(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": models.ErrUnauthorized})
+	}
+
+	var req models.ProfileLocksRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": models.ErrInvalidRequest})
+	}
+
+	locks, err := services.SetProfileLocks(req.Fields, user.StudentID, c.IP())
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{
+		"message": "Profile locks updated",
+		"locks":   locks,
+	})
+}
+
+// UploadProfilePicture sets the current user's photo from a multipart "photo"
+// file or a JSON base64 data URL
+func UploadProfilePicture(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": models.ErrUnauthorized})
+	}
+	if user.IsServiceAccount {
+		return c.Status(403).JSON(fiber.Map{"error": "Service accounts have no editable profile"})
+	}
+
+	var data []byte
+	if file, err := c.FormFile("photo"); err == nil {
+		if file.Size > int64(services.ProfilePictureMaxBytes()) {
+			return c.Status(413).JSON(fiber.Map{"error": fmt.Sprintf("Image must be at most %d KB", services.ProfilePictureMaxBytes()/1024)})
+		}
+		f, err := file.Open()
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Could not read the uploaded file"})
+		}
+		defer f.Close()
+		if data, err = io.ReadAll(io.LimitReader(f, int64(services.ProfilePictureMaxBytes())+1)); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Could not read the uploaded file"})
+		}
+	} else {
+		var req models.ProfilePictureRequest
+		if err := c.BodyParser(&req); err != nil || req.Image == "" {
+			return c.Status(400).JSON(fiber.Map{"error": "Send the photo as a multipart \"photo\" file or a JSON \"image\" data URL"})
+		}
+		if data, err = services.DecodeImageDataURL(req.Image); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
+	updated, err := services.SetProfilePicture(user, data, c.IP())
+	if err != nil {
+		if err == services.ErrProfilePictureLocked {
+			return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	profile := services.GetProfile(updated)
+	profile.Message = "Profile picture updated"
+	return c.JSON(profile)
+}
+
+// DeleteProfilePicture removes the current user's photo
+func DeleteProfilePicture(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": models.ErrUnauthorized})
+	}
+	if _, err := services.RemoveProfilePicture(user, c.IP()); err != nil {
+		switch err {
+		case services.ErrNoProfilePicture:
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		case services.ErrProfilePictureLocked:
+			return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Profile picture removed"})
+}
+
+// GetMedia serves a stored file such as a profile picture thumbnail. Keys are
+// random per upload, so responses may be cached privately for a long time.
+func GetMedia(c *fiber.Ctx) error {
+	data, contentType, err := services.GetMediaFile(c.Params("*"))
+	if err != nil {
+		if err == services.ErrFileNotFound {
+			return c.Status(404).JSON(fiber.Map{"error": "File not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to read file"})
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderCacheControl, "private, max-age=31536000, immutable")
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	return c.Send(data)
+}
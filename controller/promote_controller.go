@@ -8,21 +8,6 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-func GetAllUsers(c *fiber.Ctx) error {
-	var users []models.User
-	if err := connection.DB.Find(&users).Error; err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to fetch users",
-		})
-	}
-
-	return c.JSON(fiber.Map{
-		"message": "Users retrieved successfully",
-		"status":  "success",
-		"users":   users,
-	})
-}
-
 func PromoteUser(c *fiber.Ctx) error {
 	req := new(models.PromoteRequest)
 	if err := c.BodyParser(req); err != nil {
@@ -37,20 +22,15 @@ func PromoteUser(c *fiber.Ctx) error {
 		})
 	}
 
-	// Validate role
-	validRoles := map[string]bool{
-		"student": true,
-		"faculty": true,
-		"admin":   true,
-	}
-	if !validRoles[req.Role] {
+	// Validate role: any defined role except superadmin
+	if req.Role == models.RoleSuperAdmin || !services.RoleExists(req.Role) {
 		return c.Status(400).JSON(fiber.Map{
-			"error": "Invalid role. Must be 'student', 'faculty', or 'admin'",
+			"error": "Invalid role. Must be an existing role other than 'superadmin'",
 		})
 	}
 
-	// Get superadmin user from context
-	superadmin, ok := c.Locals("user").(models.User)
+	// Get promoting user from context (requires user.promote)
+	promoter, ok := c.Locals("user").(models.User)
 	if !ok {
 		return c.Status(401).JSON(fiber.Map{
 			"error": "Unauthorized",
@@ -64,6 +44,21 @@ func PromoteUser(c *fiber.Ctx) error {
 			"error": "Student not found",
 		})
 	}
+	if user.Role == models.RoleSuperAdmin || user.StudentID == promoter.StudentID {
+		return c.Status(403).JSON(fiber.Map{
+			"error": "Superadmins and your own account cannot be re-assigned",
+		})
+	}
+	if !services.CanGrantRole(promoter.Role, user.Role) {
+		return c.Status(403).JSON(fiber.Map{
+			"error": "You cannot manage a user with more permissions than you",
+		})
+	}
+	if !services.CanGrantRole(promoter.Role, req.Role) {
+		return c.Status(403).JSON(fiber.Map{
+			"error": "You cannot assign a role with permissions you do not have",
+		})
+	}
 
 	// Update the user's role
 	if err := connection.DB.Model(&user).Update("role", req.Role).Error; err != nil {
@@ -72,10 +67,17 @@ func PromoteUser(c *fiber.Ctx) error {
 		})
 	}
 
+	// Tokens issued under the old role must not keep working
+	if _, err := services.RevokeAllSessions(user.StudentID, models.SessionRevokedRoleChange); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Role updated but existing sessions could not be revoked",
+		})
+	}
+
 	// Log the action for audit trail
 	go services.LogAuditAction(
 		services.AuditUserPromoted,
-		superadmin.StudentID,
+		promoter.StudentID,
 		req.StudentID,
 		"Promoted to "+req.Role,
 		c.IP(),
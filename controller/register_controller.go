@@ -3,7 +3,6 @@ package controller
 import (
 	"attendance-system/models"
 	"attendance-system/services"
-	"attendance-system/utils"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -19,6 +18,11 @@ func Register(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Email, password, first name, and last name are required"})
 	}
 
+	// Fall back to the browser language for email localization
+	if req.Locale == "" {
+		req.Locale = c.Get(fiber.HeaderAcceptLanguage)
+	}
+
 	studentID, token, err := services.RegisterService(req)
 	if err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
@@ -32,10 +36,11 @@ func Register(c *fiber.Ctx) error {
 	})
 }
 
-// GetRegistrationDropdowns returns predefined departments and sections for registration dropdowns
+// GetRegistrationDropdowns returns the institution hierarchy for registration dropdowns
 func GetRegistrationDropdowns(c *fiber.Ctx) error {
-	return c.Status(200).JSON(fiber.Map{
-		"departments": utils.Departments,
-		"sections":    utils.Sections,
-	})
+	dropdowns, err := services.GetInstitutionDropdowns()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load dropdowns"})
+	}
+	return c.Status(200).JSON(dropdowns)
 }
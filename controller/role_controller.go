@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"attendance-system/models"
+	"attendance-system/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListRoles lists all roles with their permissions (superadmin only)
+func ListRoles(c *fiber.Ctx) error {
+	roles, err := services.ListRoles()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{
+		"roles":       roles,
+		"permissions": models.AllPermissions,
+	})
+}
+
+// CreateRole adds a custom role (superadmin only)
+func CreateRole(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var req models.RoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	role, err := services.CreateRole(req, user.StudentID, c.IP())
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Role created successfully",
+		"role":    role,
+	})
+}
+
+// SetRolePermissions replaces a role's permissions (superadmin only)
+func SetRolePermissions(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var req models.RolePermissionsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	role, err := services.SetRolePermissions(c.Params("name"), req.Permissions, user.StudentID, c.IP())
+	if err != nil {
+		if err == services.ErrRoleNotFound {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Role permissions updated successfully",
+		"role":    role,
+	})
+}
+
+// DeleteRole removes an unused custom role (superadmin only)
+func DeleteRole(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	if err := services.DeleteRole(c.Params("name"), user.StudentID, c.IP()); err != nil {
+		if err == services.ErrRoleNotFound {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Role deleted successfully"})
+}
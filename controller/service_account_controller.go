@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"attendance-system/models"
+	"attendance-system/services"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListServiceAccounts lists integration service accounts (superadmin only)
+func ListServiceAccounts(c *fiber.Ctx) error {
+	accounts, err := services.ListServiceAccounts()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"service_accounts": accounts})
+}
+
+// CreateServiceAccount adds a service account with a role (superadmin only)
+func CreateServiceAccount(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var req models.ServiceAccountRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	account, err := services.CreateServiceAccount(req, user.StudentID, c.IP())
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message":         "Service account created successfully",
+		"service_account": account,
+	})
+}
+
+// DisableServiceAccount disables a service account and revokes its keys (superadmin only)
+func DisableServiceAccount(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid service account ID"})
+	}
+
+	if err := services.DisableServiceAccount(uint(id), user.StudentID, c.IP()); err != nil {
+		if err == services.ErrServiceAccountNotFound {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Service account disabled successfully"})
+}
+
+// ListAPIKeys lists a service account's keys by prefix (superadmin only)
+func ListAPIKeys(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid service account ID"})
+	}
+
+	keys, err := services.ListAPIKeys(uint(id))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"api_keys": keys})
+}
+
+// CreateAPIKey issues a key; the plaintext is only returned here (superadmin only)
+func CreateAPIKey(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid service account ID"})
+	}
+
+	var req models.APIKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	plaintext, key, err := services.CreateAPIKey(uint(id), req, user.StudentID, c.IP())
+	if err != nil {
+		if err == services.ErrServiceAccountNotFound {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "API key created. Store it now; it cannot be shown again.",
+		"key":     plaintext,
+		"api_key": key,
+	})
+}
+
+// RevokeAPIKey revokes a service account key (superadmin only)
+func RevokeAPIKey(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid service account ID"})
+	}
+	keyID, err := strconv.ParseUint(c.Params("key_id"), 10, 32)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid API key ID"})
+	}
+
+	if err := services.RevokeAPIKey(uint(id), uint(keyID), user.StudentID, c.IP()); err != nil {
+		if err == services.ErrServiceAccountNotFound || err.Error() == "API key not found" {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "API key revoked successfully"})
+}
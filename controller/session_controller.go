@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"attendance-system/models"
+	"attendance-system/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Logout revokes the current session (or the session of the given refresh token)
+func Logout(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": models.ErrUnauthorized})
+	}
+
+	sessionID, _ := c.Locals("session_id").(string)
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	_ = c.BodyParser(&req)
+	if req.RefreshToken != "" {
+		if claims, err := services.VerifyRefreshToken(req.RefreshToken); err == nil && claims.StudentID == user.StudentID {
+			sessionID = claims.SessionID
+		}
+	}
+	if sessionID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "No session to log out. Send the refresh_token or use /logout-all."})
+	}
+
+	if err := services.RevokeSession(user.StudentID, sessionID, models.SessionRevokedLogout); err != nil {
+		if err == services.ErrSessionNotFound {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Logged out successfully"})
+}
+
+// LogoutAll revokes every session of the current user
+func LogoutAll(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": models.ErrUnauthorized})
+	}
+
+	n, err := services.RevokeAllSessions(user.StudentID, models.SessionRevokedLogoutAll)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	go services.LogAuditAction(services.AuditSessionsRevoked, user.StudentID, user.StudentID, models.SessionRevokedLogoutAll, c.IP())
+
+	return c.JSON(fiber.Map{
+		"message": "Logged out of all sessions",
+		"revoked": n,
+	})
+}
+
+// ListSessions lists the current user's active sessions
+func ListSessions(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": models.ErrUnauthorized})
+	}
+	sessionID, _ := c.Locals("session_id").(string)
+
+	sessions, err := services.ListSessions(user.StudentID, sessionID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"sessions": sessions,
+		"count":    len(sessions),
+	})
+}
+
+// RevokeSession logs out one of the current user's sessions
+func RevokeSession(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": models.ErrUnauthorized})
+	}
+
+	if err := services.RevokeSession(user.StudentID, c.Params("id"), models.SessionRevokedLogout); err != nil {
+		if err == services.ErrSessionNotFound {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Session revoked successfully"})
+}
@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"attendance-system/models"
+	"attendance-system/services"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// parseTermFilter reads ?term_id=<id|active>. It returns 0 when no filter is given.
+func parseTermFilter(c *fiber.Ctx) (uint, error) {
+	value := c.Query("term_id")
+	if value == "" {
+		return 0, nil
+	}
+	if value == "active" {
+		term, err := services.GetActiveTerm()
+		if err != nil {
+			return 0, err
+		}
+		return term.ID, nil
+	}
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || id == 0 {
+		return 0, errors.New("invalid term_id")
+	}
+	return uint(id), nil
+}
+
+// ListTerms returns all academic terms and the active one
+func ListTerms(c *fiber.Ctx) error {
+	terms, err := services.ListTerms()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var activeID *uint
+	for i := range terms {
+		if terms[i].IsActive {
+			activeID = &terms[i].ID
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"terms":          terms,
+		"count":          len(terms),
+		"active_term_id": activeID,
+	})
+}
+
+// GetActiveTerm returns the current academic term
+func GetActiveTerm(c *fiber.Ctx) error {
+	term, err := services.GetActiveTerm()
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"term": term})
+}
+
+// CreateTerm adds an academic term (superadmin only)
+func CreateTerm(c *fiber.Ctx) error {
+	var req models.AcademicTermRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	term, err := services.CreateTerm(req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Term created successfully",
+		"term":    term,
+	})
+}
+
+// UpdateTerm renames a term or changes its dates (superadmin only)
+func UpdateTerm(c *fiber.Ctx) error {
+	termID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid term ID"})
+	}
+
+	var req models.AcademicTermRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	term, err := services.UpdateTerm(uint(termID), req)
+	if err != nil {
+		return termError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Term updated successfully",
+		"term":    term,
+	})
+}
+
+// termAction builds a handler for POST /admin/terms/:id/<action>
+func termAction(action func(termID uint, c *fiber.Ctx) (*models.AcademicTerm, error), message string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		termID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid term ID"})
+		}
+
+		term, err := action(uint(termID), c)
+		if err != nil {
+			return termError(c, err)
+		}
+
+		return c.JSON(fiber.Map{
+			"message": message,
+			"term":    term,
+		})
+	}
+}
+
+// ActivateTerm makes a term the active one (superadmin only)
+var ActivateTerm = termAction(func(termID uint, c *fiber.Ctx) (*models.AcademicTerm, error) {
+	return services.ActivateTerm(termID)
+}, "Term activated")
+
+// CloseTerm freezes attendance for the term's events (superadmin only)
+var CloseTerm = termAction(func(termID uint, c *fiber.Ctx) (*models.AcademicTerm, error) {
+	user, _ := c.Locals("user").(models.User)
+	return services.CloseTerm(termID, user.StudentID, c.IP())
+}, "Term closed; its attendance is now read-only")
+
+// ReopenTerm allows attendance changes in the term again (superadmin only)
+var ReopenTerm = termAction(func(termID uint, c *fiber.Ctx) (*models.AcademicTerm, error) {
+	user, _ := c.Locals("user").(models.User)
+	return services.ReopenTerm(termID, user.StudentID, c.IP())
+}, "Term reopened")
+
+func termError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, services.ErrTermNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+}
@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"attendance-system/models"
+	"attendance-system/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// challengeUser resolves the user behind a 2FA challenge token
+func challengeUser(token, purpose string) (models.User, bool) {
+	var user models.User
+	claims, err := services.VerifyTwoFactorChallengeToken(token, purpose)
+	if err != nil {
+		return user, false
+	}
+	if err := services.GetUserByStudentID(claims.Subject, &user); err != nil || user.IsServiceAccount || !user.IsVerified {
+		return user, false
+	}
+	if services.CheckAccountAccess(user) != nil {
+		return user, false
+	}
+	return user, true
+}
+
+// LoginTwoFactor completes a login with a TOTP or recovery code
+func LoginTwoFactor(c *fiber.Ctx) error {
+	var req models.TwoFactorLoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": models.ErrInvalidRequest})
+	}
+	user, ok := challengeUser(req.ChallengeToken, models.TwoFactorPurposeLogin)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Invalid or expired challenge token. Please log in again."})
+	}
+	if err := services.CheckLockout(models.LockoutScopeTwoFactor, user.StudentID); err != nil {
+		return lockoutResponse(c, err)
+	}
+	if err := services.VerifySecondFactor(user.StudentID, req.Code, req.RecoveryCode); err != nil {
+		return failedAttempt(c, models.LockoutScopeTwoFactor, user.StudentID, 401, err.Error())
+	}
+	services.ClearAuthFailures(models.LockoutScopeTwoFactor, user.StudentID)
+	return startLoginSession(c, user, nil)
+}
+
+// LoginTwoFactorSetup starts enrolment for a user whose role requires 2FA
+func LoginTwoFactorSetup(c *fiber.Ctx) error {
+	var req models.TwoFactorLoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": models.ErrInvalidRequest})
+	}
+	user, ok := challengeUser(req.ChallengeToken, models.TwoFactorPurposeSetup)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Invalid or expired challenge token. Please log in again."})
+	}
+	setup, err := services.BeginTwoFactorSetup(user)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(setup)
+}
+
+// LoginTwoFactorEnable confirms enrolment during login and opens the session
+func LoginTwoFactorEnable(c *fiber.Ctx) error {
+	var req models.TwoFactorLoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": models.ErrInvalidRequest})
+	}
+	user, ok := challengeUser(req.ChallengeToken, models.TwoFactorPurposeSetup)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Invalid or expired challenge token. Please log in again."})
+	}
+	codes, err := services.EnableTwoFactor(user, req.Code, c.IP())
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return startLoginSession(c, user, fiber.Map{"recovery_codes": codes})
+}
+
+// GetTwoFactorStatus returns the current user's 2FA state
+func GetTwoFactorStatus(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+	return c.JSON(services.GetTwoFactorStatus(user))
+}
+
+// SetupTwoFactor returns a new secret and QR code to scan
+func SetupTwoFactor(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+	setup, err := services.BeginTwoFactorSetup(user)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(setup)
+}
+
+// EnableTwoFactor confirms setup with a code and returns recovery codes
+func EnableTwoFactor(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+	var req models.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": models.ErrInvalidRequest})
+	}
+	codes, err := services.EnableTwoFactor(user, req.Code, c.IP())
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{
+		"message":        "Two-factor authentication enabled. Store the recovery codes now; they cannot be shown again.",
+		"recovery_codes": codes,
+	})
+}
+
+// DisableTwoFactor turns 2FA off (password and a code are required)
+func DisableTwoFactor(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+	if services.TwoFactorRequired(user.Role) {
+		return c.Status(403).JSON(fiber.Map{"error": "Two-factor authentication is mandatory for your role"})
+	}
+	var req models.DisableTwoFactorRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": models.ErrInvalidRequest})
+	}
+	if err := services.DisableTwoFactor(user, req, c.IP()); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes replaces the current user's recovery codes
+func RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+	var req models.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": models.ErrInvalidRequest})
+	}
+	codes, err := services.RegenerateRecoveryCodes(user, req.Code, c.IP())
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"recovery_codes": codes})
+}
+
+// ResetUserTwoFactor removes a user's 2FA after a lost device (superadmin only)
+func ResetUserTwoFactor(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+	if err := services.ResetTwoFactor(c.Params("student_id"), user.StudentID, c.IP()); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Two-factor authentication reset. The user must enrol again at next login if their role requires it."})
+}
@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"attendance-system/logging"
+	"attendance-system/models"
+	"attendance-system/services"
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// parseDateQuery reads a YYYY-MM-DD or RFC 3339 query value. With endOfDay a
+// plain date means the end of that day, for use as an exclusive upper bound.
+func parseDateQuery(c *fiber.Ctx, key string, endOfDay bool) (time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s, use YYYY-MM-DD or RFC 3339", key)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// streamDownload sends an export as an attachment, writing it to the client as
+// it is produced instead of holding it in memory. export runs after the handler
+// returns, so it must not use c; once the body started an error can only be logged.
+func streamDownload(c *fiber.Ctx, contentType, filename string, export func(w io.Writer) error) error {
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := export(w); err != nil {
+			logging.Logger.Error("Export failed", zap.String("file", filename), zap.Error(err))
+		}
+	})
+	return nil
+}
+
+// ListUsers is the paginated user directory (GET /admin/users). Filters:
+// role, course, year_level, section, department, college, status, verified,
+// created_from, created_to, include_deleted and q (search). Sort with
+// ?sort=last_name or ?sort=-created_at; ?format=csv exports every match.
+func ListUsers(c *fiber.Ctx) error {
+	filters := make(map[string]interface{})
+	for _, key := range []string{"role", "course", "year_level", "section", "department", "college", "status", "q"} {
+		if v := c.Query(key); v != "" {
+			filters[key] = v
+		}
+	}
+	if v := c.Query("verified"); v != "" {
+		verified, err := strconv.ParseBool(v)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "verified must be true or false"})
+		}
+		filters["verified"] = verified
+	}
+	filters["include_deleted"] = c.QueryBool("include_deleted", false)
+	from, err := parseDateQuery(c, "created_from", false)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	to, err := parseDateQuery(c, "created_to", true)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	filters["created_from"], filters["created_to"] = from, to
+	sort := c.Query("sort")
+
+	if c.Query("format") == "csv" {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return c.Status(401).JSON(fiber.Map{"error": models.ErrUnauthorized})
+		}
+		if err := services.ValidateUserDirectorySort(sort); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		actor, ip := user.StudentID, c.IP()
+		return streamDownload(c, "text/csv; charset=utf-8", "users-"+time.Now().Format("2006-01-02")+".csv", func(w io.Writer) error {
+			_, err := services.ExportUsersCSV(w, filters, sort, actor, ip)
+			return err
+		})
+	}
+
+	limit := c.QueryInt("limit", defaultPageSize)
+	if limit < 1 || limit > maxPageSize {
+		return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("limit must be between 1 and %d", maxPageSize)})
+	}
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+
+	users, total, err := services.ListUsers(filters, sort, limit, (page-1)*limit)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{
+		"users":       users,
+		"total":       total,
+		"page":        page,
+		"limit":       limit,
+		"total_pages": (total + int64(limit) - 1) / int64(limit),
+	})
+}
@@ -70,16 +70,19 @@ func VerifyEmail(c *fiber.Ctx) error {
 		tokenClaims, err := services.VerifyEmailVerificationToken(token)
 		if err == nil {
 			claims = tokenClaims
-			// Verify the code matches the token claims
-			if strings.TrimSpace(req.Code) != strings.TrimSpace(claims.Code) {
-				return c.Status(400).JSON(fiber.Map{"error": "Invalid verification code"})
+			if err := services.CheckLockout(models.LockoutScopeVerifyEmail, claims.Email); err != nil {
+				return lockoutResponse(c, err)
 			}
-
 			// Find pending user by email from claims
 			if err := connection.DB.Where("email = ?", claims.Email).First(&pending).Error; err != nil {
 				return c.Status(400).JSON(fiber.Map{"error": "Registration not found"})
 			}
 
+			// The token only names the registration; the code comes from the email
+			if strings.TrimSpace(req.Code) != strings.TrimSpace(pending.VerificationCode) {
+				return failedAttempt(c, models.LockoutScopeVerifyEmail, claims.Email, 400, "Invalid verification code")
+			}
+
 			// Check if code expired (compare in UTC)
 			if time.Now().UTC().After(pending.ExpiresAt.UTC()) {
 				connection.DB.Delete(&pending)
@@ -101,13 +104,16 @@ func VerifyEmail(c *fiber.Ctx) error {
 			return c.Status(401).JSON(fiber.Map{"error": "Authorization header or token is required (or provide email+code)"})
 		}
 
+		if err := services.CheckLockout(models.LockoutScopeVerifyEmail, req.Email); err != nil {
+			return lockoutResponse(c, err)
+		}
 		if err := connection.DB.Where("email = ?", req.Email).First(&pending).Error; err != nil {
-			return c.Status(400).JSON(fiber.Map{"error": "Registration not found"})
+			return failedAttempt(c, models.LockoutScopeVerifyEmail, req.Email, 400, "Registration not found")
 		}
 
 		// Validate code and expiry (compare in UTC)
 		if strings.TrimSpace(req.Code) != strings.TrimSpace(pending.VerificationCode) {
-			return c.Status(400).JSON(fiber.Map{"error": "Invalid verification code"})
+			return failedAttempt(c, models.LockoutScopeVerifyEmail, req.Email, 400, "Invalid verification code")
 		}
 		if time.Now().UTC().After(pending.ExpiresAt.UTC()) {
 			connection.DB.Delete(&pending)
@@ -140,11 +146,13 @@ func VerifyEmail(c *fiber.Ctx) error {
 		College:       pending.College,
 		ContactNumber: pending.ContactNumber,
 		Address:       pending.Address,
+		Locale:        pending.Locale,
 
 		QRCodeData: qrCodeBase64,
 		IsVerified: true,
 		VerifiedAt: time.Now(),
 	}
+	user.InstitutionRefs = services.LookupInstitutionRefs(user.College, user.Department, user.Course, user.Section)
 
 	// Ensure ID is zero so DB assigns it (defensive against client-provided IDs)
 	user.ID = 0
@@ -154,6 +162,7 @@ func VerifyEmail(c *fiber.Ctx) error {
 
 	// Delete from pending table
 	connection.DB.Delete(&pending)
+	services.ClearAuthFailures(models.LockoutScopeVerifyEmail, pending.Email)
 
 	return c.JSON(fiber.Map{
 		"message": "Email verification successful",
@@ -9,7 +9,6 @@ import (
 	"attendance-system/services"
 	"fmt"
 	"os"
-	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -31,8 +30,18 @@ func main() {
 	// Seed default admin
 	seeder.SeedSuperAdmin()
 
-	// Background job
-	go startEventStatusChecker()
+	// Built-in roles and their default permissions
+	services.SeedRoles()
+
+	// Map free-text college/department/course/section values to the hierarchy
+	services.MigrateInstitutionHierarchy()
+
+	// Hash-chain audit entries written before the chain existed
+	services.SealAuditLog()
+
+	// Background jobs
+	services.RegisterDefaultJobs()
+	go services.StartJobScheduler()
 
 	// Fiber app
 	app := fiber.New(fiber.Config{
@@ -66,7 +75,7 @@ func main() {
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: "*", // DEV ONLY
 		AllowMethods: "GET,POST,PUT,DELETE,OPTIONS,PATCH",
-		AllowHeaders: "Origin, Content-Type, Accept, Authorization",
+		AllowHeaders: "Origin, Content-Type, Accept, Authorization, X-API-Key",
 		MaxAge:       300,
 	}))
 
@@ -78,6 +87,8 @@ func main() {
 	API.AuthRoutes(app)
 	API.EventRoutes(app)
 	API.AttendanceRoutes(app)
+	API.TermRoutes(app)
+	API.DevRoutes(app)
 
 	// Health check
 	app.Get("/health", func(c *fiber.Ctx) error {
@@ -112,16 +123,3 @@ func main() {
 		panic(err)
 	}
 }
-
-// Background job
-func startEventStatusChecker() {
-	ticker := time.NewTicker(5 * time.Minute)
-	defer ticker.Stop()
-
-	// Run once at startup
-	services.CheckAndUpdateCompletedEvents()
-
-	for range ticker.C {
-		services.CheckAndUpdateCompletedEvents()
-	}
-}
\ No newline at end of file
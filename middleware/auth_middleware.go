@@ -9,12 +9,16 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-// extractStudentID extracts student ID from Authorization header (supports both JWT and legacy Bearer format)
+// extractStudentID extracts student ID and login session ID from Authorization header
+// (supports both JWT and legacy Bearer format)
 func extractStudentID(c *fiber.Ctx) (string, string, error) {
+	legacy := services.LegacyStudentIDAuthEnabled()
 	authHeader := c.Get("Authorization")
 	if authHeader == "" {
-		// Try alternative header
-		authHeader = c.Get("X-Student-ID")
+		// Try alternative header (legacy only)
+		if legacy {
+			authHeader = c.Get("X-Student-ID")
+		}
 		if authHeader == "" {
 			return "", "", fiber.NewError(fiber.StatusUnauthorized, "Authorization required")
 		}
@@ -40,17 +44,40 @@ func extractStudentID(c *fiber.Ctx) (string, string, error) {
 	// Try JWT first
 	claims, err := services.VerifyAccessToken(token)
 	if err == nil {
-		// Valid JWT token
-		return claims.StudentID, claims.Role, nil
+		// Valid JWT token; reject it once its session has been logged out
+		if services.IsSessionRevoked(claims.SessionID) {
+			return "", "", fiber.NewError(fiber.StatusUnauthorized, models.ErrSessionRevoked)
+		}
+		return claims.StudentID, claims.SessionID, nil
 	}
 
-	// Fallback: treat as legacy student ID format (for backward compatibility)
-	// This should be deprecated in future versions
+	// Fallback: treat as legacy student ID format (for backward compatibility).
+	// Only enabled with LEGACY_STUDENT_ID_AUTH=true.
+	if !legacy {
+		return "", "", fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired token")
+	}
 	return token, "", nil
 }
 
+// requireAPIKey authenticates a machine client by its service account API key.
+// The service account is not made the request's user yet: only a route that
+// declares a scope with RequirePermission does that once the key holds the
+// scope, so keys are rejected by every other route.
+func requireAPIKey(c *fiber.Ctx, apiKey string) error {
+	key, user, err := services.AuthenticateAPIKey(apiKey, c.IP())
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	c.Locals("api_key", key)
+	c.Locals("api_key_user", *user)
+	return c.Next()
+}
+
 func RequireSuperAdmin(c *fiber.Ctx) error {
-	studentID, _, err := extractStudentID(c)
+	studentID, sessionID, err := extractStudentID(c)
 	if err != nil {
 		return err
 	}
@@ -63,6 +90,12 @@ func RequireSuperAdmin(c *fiber.Ctx) error {
 		})
 	}
 
+	if err := services.CheckAccountAccess(user); err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
 	// Check if user is superadmin
 	if user.Role != models.RoleSuperAdmin {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
@@ -72,13 +105,20 @@ func RequireSuperAdmin(c *fiber.Ctx) error {
 
 	// Store user info in context for later use
 	c.Locals("user", user)
+	c.Locals("session_id", sessionID)
 
 	return c.Next()
 }
 
-// RequireAuth - JWT authentication middleware (checks if user exists and is verified)
+// RequireAuth - JWT authentication middleware (checks if user exists and is verified).
+// Machine clients authenticate with a service account key in the X-API-Key header;
+// the key is only accepted on routes guarded by RequirePermission.
 func RequireAuth(c *fiber.Ctx) error {
-	studentID, _, err := extractStudentID(c)
+	if apiKey := c.Get(models.APIKeyHeader); apiKey != "" {
+		return requireAPIKey(c, apiKey)
+	}
+
+	studentID, sessionID, err := extractStudentID(c)
 	if err != nil {
 		return err
 	}
@@ -98,15 +138,23 @@ func RequireAuth(c *fiber.Ctx) error {
 		})
 	}
 
+	// Suspended, deactivated and deleted accounts lose access immediately
+	if err := services.CheckAccountAccess(user); err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
 	// Store user info in context
 	c.Locals("user", user)
+	c.Locals("session_id", sessionID)
 
 	return c.Next()
 }
 
 // RequireAdmin - middleware for organization/event managers (admin + superadmin)
 func RequireAdmin(c *fiber.Ctx) error {
-	studentID, _, err := extractStudentID(c)
+	studentID, sessionID, err := extractStudentID(c)
 	if err != nil {
 		return err
 	}
@@ -132,8 +180,14 @@ func RequireAdmin(c *fiber.Ctx) error {
 			"error": "Account not verified. Please verify your email first.",
 		})
 	}
+	if err := services.CheckAccountAccess(user); err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
 
 	c.Locals("user", user)
+	c.Locals("session_id", sessionID)
 	return c.Next()
 }
 
@@ -160,3 +214,36 @@ func RequireFacultyOrAdmin(c *fiber.Ctx) error {
 
 	return c.Next()
 }
+
+// RequirePermission - requires the authenticated user's role to grant a permission.
+// Must run after RequireAuth.
+func RequirePermission(permission string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// API keys are further limited to their scopes
+		if key, ok := c.Locals("api_key").(*models.APIKey); ok {
+			if !key.HasScope(permission) {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+					"error": "Access denied - API key lacks scope " + permission,
+				})
+			}
+			if _, ok := c.Locals("user").(models.User); !ok {
+				c.Locals("user", c.Locals("api_key_user"))
+			}
+		}
+
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Authentication required",
+			})
+		}
+
+		if !services.HasPermission(user.Role, permission) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Access denied - missing permission " + permission,
+			})
+		}
+
+		return c.Next()
+	}
+}
@@ -19,6 +19,7 @@ type PendingUser struct {
 	College          string
 	ContactNumber    string
 	Address          string
+	Locale           string    `gorm:"type:varchar(10)"`
 	VerificationCode string    `gorm:"not null"`
 	CreatedAt        time.Time `gorm:"autoCreateTime"`
 	ExpiresAt        time.Time
@@ -0,0 +1,27 @@
+// models/account_status_model.go
+package models
+
+// Account states. Only active and graduated accounts can sign in; graduated
+// students keep read access to their history but can no longer be marked
+// present. Soft deletion (User.DeletedAt) is separate and keeps the state so
+// a restore returns the account to it.
+const (
+	AccountActive      = "active"
+	AccountSuspended   = "suspended"
+	AccountDeactivated = "deactivated"
+	AccountGraduated   = "graduated"
+)
+
+// AccountStatuses lists every account state
+var AccountStatuses = []string{AccountActive, AccountSuspended, AccountDeactivated, AccountGraduated}
+
+// AccountStatusRequest changes a user's account state
+type AccountStatusRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// DeleteUserRequest soft-deletes a user; the reason is kept for the audit log
+type DeleteUserRequest struct {
+	Reason string `json:"reason"`
+}
@@ -35,8 +35,9 @@ type Attendance struct {
 	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 
 	// Transient fields - not persisted to database
-	TotalAttendanceCount int `json:"total_attendance_count" gorm:"-"` // Total attendance count for this student
-	EventAttendanceCount int `json:"event_attendance_count" gorm:"-"` // Total attendance count for the event
+	TotalAttendanceCount int    `json:"total_attendance_count" gorm:"-"`      // Total attendance count for this student
+	EventAttendanceCount int    `json:"event_attendance_count" gorm:"-"`      // Total attendance count for the event
+	StudentPhotoURL      string `json:"student_photo_url,omitempty" gorm:"-"` // Lets scanner staff confirm identity
 
 	// Relationships
 	Event   Event `json:"event,omitempty" gorm:"foreignKey:EventID"`
@@ -0,0 +1,44 @@
+// models/audit_checkpoint_model.go
+package models
+
+import "time"
+
+// AuditCheckpoint is written when retention pruning deletes the oldest audit
+// entries. It records the hash of the last deleted entry, which the first
+// remaining entry links to, and is signed so it cannot be forged without the
+// audit signing key.
+type AuditCheckpoint struct {
+	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	LastEntryID uint      `json:"last_entry_id" gorm:"not null;index"`
+	LastHash    string    `json:"last_hash" gorm:"not null;type:varchar(64)"`
+	LastEntryAt time.Time `json:"last_entry_at"`
+	PrunedCount int64     `json:"pruned_count"`
+	Signature   string    `json:"signature" gorm:"not null;type:varchar(64)"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// AuditChainHead is the signed position of the newest audit entry (a single
+// row, updated on every append). The chain must reach it, so deleting the
+// newest entries is detected as well.
+type AuditChainHead struct {
+	ID          uint      `json:"-" gorm:"primaryKey"`
+	LastEntryID uint      `json:"last_entry_id" gorm:"not null"`
+	LastHash    string    `json:"last_hash" gorm:"not null;type:varchar(64)"`
+	Signature   string    `json:"signature" gorm:"not null;type:varchar(64)"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// AuditChainReport is the result of walking the audit hash chain. When Valid
+// is false, BreakID is the first entry that failed and BreakReason says why.
+type AuditChainReport struct {
+	Valid       bool             `json:"valid"`
+	Checked     int64            `json:"checked"`
+	Redacted    int64            `json:"redacted"`
+	Checkpoint  *AuditCheckpoint `json:"checkpoint,omitempty"`
+	SignedHead  *AuditChainHead  `json:"signed_head,omitempty"`
+	HeadID      uint             `json:"head_id"`
+	HeadHash    string           `json:"head_hash"`
+	BreakID     uint             `json:"break_id,omitempty"`
+	BreakReason string           `json:"break_reason,omitempty"`
+	VerifiedAt  time.Time        `json:"verified_at"`
+}
@@ -0,0 +1,26 @@
+// models/auth_failure_model.go
+package models
+
+import "time"
+
+// Actions protected against guessing, each with its own failure counter
+const (
+	LockoutScopeLogin       = "login"
+	LockoutScopeResetCode   = "reset_code"
+	LockoutScopeVerifyEmail = "verify_email"
+	LockoutScopeTwoFactor   = "two_factor"
+)
+
+// AuthFailure counts consecutive failed attempts for one identifier (a
+// student ID, or the normalized email/ID that was tried) and action.
+// Lockouts grows with every lockout until a success and drives the backoff.
+type AuthFailure struct {
+	ID            uint       `json:"-" gorm:"primaryKey;autoIncrement"`
+	Scope         string     `json:"scope" gorm:"not null;type:varchar(30);uniqueIndex:idx_auth_failure_key"`
+	Identifier    string     `json:"identifier" gorm:"not null;type:varchar(255);uniqueIndex:idx_auth_failure_key"`
+	Failures      int        `json:"failures" gorm:"default:0"`
+	Lockouts      int        `json:"lockouts" gorm:"default:0"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty" gorm:"index"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	LastIP        string     `json:"last_ip" gorm:"type:varchar(255)"`
+}
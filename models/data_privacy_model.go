@@ -0,0 +1,49 @@
+// models/data_privacy_model.go
+package models
+
+import "time"
+
+// Erasure request states. A request is approved, then executed by a
+// superadmin who confirms the student ID; it can be rejected or cancelled
+// before that.
+const (
+	ErasurePending   = "pending"
+	ErasureApproved  = "approved"
+	ErasureRejected  = "rejected"
+	ErasureCancelled = "cancelled"
+	ErasureCompleted = "completed"
+)
+
+// ErasureRequest asks for a user's personal data to be erased. Once completed
+// StudentID holds the pseudonym the user's records were moved to.
+type ErasureRequest struct {
+	ID          uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	StudentID   string     `json:"student_id" gorm:"not null;type:varchar(255);index"`
+	Status      string     `json:"status" gorm:"not null;type:varchar(20);index"`
+	Reason      string     `json:"reason,omitempty" gorm:"type:varchar(1000)"`
+	RequestedBy string     `json:"requested_by" gorm:"type:varchar(255)"`
+	ReviewedBy  string     `json:"reviewed_by,omitempty" gorm:"type:varchar(255)"`
+	ReviewNote  string     `json:"review_note,omitempty" gorm:"type:varchar(1000)"`
+	ReviewedAt  *time.Time `json:"reviewed_at,omitempty"`
+	CompletedBy string     `json:"completed_by,omitempty" gorm:"type:varchar(255)"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// ErasureRequestBody files an erasure request. Users confirm with their
+// password; admins filing on someone's behalf set StudentID instead.
+type ErasureRequestBody struct {
+	StudentID string `json:"student_id"`
+	Password  string `json:"password"`
+	Reason    string `json:"reason"`
+}
+
+// ErasureReviewRequest approves or rejects an erasure request
+type ErasureReviewRequest struct {
+	Note string `json:"note"`
+}
+
+// ErasureExecuteRequest carries the typed confirmation for running an erasure
+type ErasureExecuteRequest struct {
+	ConfirmStudentID string `json:"confirm_student_id"`
+}
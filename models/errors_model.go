@@ -21,6 +21,7 @@ const (
 	ErrTokenRequired              = "refresh_token is required"
 	ErrFailedGenerateAccessToken  = "Failed to generate access token"
 	ErrFailedGenerateRefreshToken = "Failed to generate refresh token"
+	ErrSessionRevoked             = "Session has been revoked. Please log in again."
 
 	// Validation Errors
 	ErrFieldsRequired        = "Student ID/Email and password are required"
@@ -51,6 +52,7 @@ const (
 	ErrInvalidEventDate   = "Invalid event date"
 	ErrEventAlreadyActive = "Event is already active"
 	ErrEventNotActive     = "Event is not active"
+	ErrInvalidTransition  = "Event status cannot be changed this way"
 
 	// Attendance Errors
 	ErrFailedMarkAttendance    = "Failed to mark attendance"
@@ -98,10 +100,12 @@ const (
 	RoleStudent    = "student"
 
 	// Event Status
+	EventStatusDraft     = "draft"
 	EventStatusScheduled = "scheduled"
 	EventStatusOngoing   = "ongoing"
 	EventStatusCompleted = "completed"
 	EventStatusCancelled = "cancelled"
+	EventStatusPostponed = "postponed"
 
 	// Attendance Status
 	AttendanceStatusPresent = "present"
@@ -0,0 +1,39 @@
+// models/event_change_model.go
+package models
+
+import "time"
+
+// Event change kinds that students are notified about
+const (
+	EventChangeCancelled = "cancelled"
+	EventChangePostponed = "postponed"
+	EventChangeUpdated   = "updated" // time and/or location edited
+)
+
+// EventChange records a change to an event that affected students must be told
+// about. A background job delivers it once and sets DeliveredAt.
+type EventChange struct {
+	ID           uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	EventID      uint       `json:"event_id" gorm:"not null;index"`
+	Kind         string     `json:"kind" gorm:"not null;type:varchar(20)"`
+	OldStartTime time.Time  `json:"old_start_time"`
+	OldEndTime   time.Time  `json:"old_end_time"`
+	NewStartTime time.Time  `json:"new_start_time"`
+	NewEndTime   time.Time  `json:"new_end_time"`
+	OldLocation  string     `json:"old_location" gorm:"type:varchar(255)"`
+	NewLocation  string     `json:"new_location" gorm:"type:varchar(255)"`
+	Reason       string     `json:"reason,omitempty" gorm:"type:varchar(500)"`
+	ChangedBy    string     `json:"changed_by" gorm:"type:varchar(255)"`
+	Attempts     int        `json:"attempts" gorm:"default:0"`
+	DeliveredAt  *time.Time `json:"delivered_at,omitempty" gorm:"index"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// EventChangeNotification records that a student was sent a change notice, so
+// each change reaches each student at most once.
+type EventChangeNotification struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	ChangeID  uint      `json:"change_id" gorm:"not null;uniqueIndex:idx_event_change_notifications_unique"`
+	StudentID string    `json:"student_id" gorm:"not null;type:varchar(255);uniqueIndex:idx_event_change_notifications_unique"`
+	SentAt    time.Time `json:"sent_at" gorm:"autoCreateTime"`
+}
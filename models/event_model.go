@@ -18,13 +18,27 @@ type Event struct {
 	Department  string    `json:"department" gorm:"type:varchar(100)"`
 	College     string    `json:"college" gorm:"type:varchar(100)"`
 
+	// Stable institution hierarchy IDs for College/Department/Course/Section
+	InstitutionRefs
+
+	// Academic term containing EventDate (set automatically)
+	TermID *uint `json:"term_id,omitempty" gorm:"index"`
+	// Class offering whose enrollment list this event targets
+	OfferingID *uint `json:"offering_id,omitempty" gorm:"index"`
+
 	// Event creator/owner
 	CreatedBy     string `json:"created_by" gorm:"not null;type:varchar(255)"` // StudentID of creator
 	CreatedByRole string `json:"created_by_role" gorm:"type:varchar(50);default:'faculty'"`
 
 	// Status
-	Status   string `json:"status" gorm:"type:varchar(50);default:'scheduled'"` // scheduled, ongoing, completed, cancelled
+	Status   string `json:"status" gorm:"type:varchar(50);default:'scheduled'"` // draft, scheduled, ongoing, completed, cancelled, postponed
 	IsActive bool   `json:"is_active" gorm:"default:true"`
+	// StatusReason is the note given with the last manual status change (e.g. why it was postponed)
+	StatusReason string     `json:"status_reason,omitempty" gorm:"type:varchar(500)"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	EndedAt      *time.Time `json:"ended_at,omitempty"`
+	// ManualEnd keeps a reopened event ongoing past end_time until it is ended explicitly
+	ManualEnd bool `json:"manual_end" gorm:"default:false"`
 
 	// QR Code for this event
 	QRCodeData string `json:"qr_code_data,omitempty" gorm:"type:text"`
@@ -58,4 +72,21 @@ type EventRequest struct {
 	Department    string   `json:"department"`
 	College       string   `json:"college"`
 	TaggedCourses []string `json:"tagged_courses,omitempty"`
+	// Draft creates the event unpublished; students see it once it is published
+	Draft bool `json:"draft,omitempty"`
+	// OfferingID creates the event for a class offering's enrolled students
+	OfferingID uint `json:"offering_id,omitempty"`
+}
+
+// EventTransitionRequest is the optional body for start/end/reopen/publish
+type EventTransitionRequest struct {
+	Reason string `json:"reason"`
+}
+
+// EventPostponeRequest moves an event to a new date and time
+type EventPostponeRequest struct {
+	EventDate string `json:"event_date"` // YYYY-MM-DD
+	StartTime string `json:"start_time"` // HH:MM or ISO 8601
+	EndTime   string `json:"end_time"`   // HH:MM or ISO 8601
+	Reason    string `json:"reason"`
 }
@@ -0,0 +1,31 @@
+// models/event_staff_model.go
+package models
+
+import "time"
+
+// Per-event staff roles
+const (
+	EventStaffCoOrganizer = "co_organizer" // can edit the event and scan
+	EventStaffScanner     = "scanner"      // can only mark attendance
+)
+
+// EventStaff assigns a user to help run a specific event
+type EventStaff struct {
+	ID         uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	EventID    uint      `json:"event_id" gorm:"not null;uniqueIndex:idx_event_staff_unique"`
+	StudentID  string    `json:"student_id" gorm:"not null;type:varchar(255);uniqueIndex:idx_event_staff_unique;index"`
+	Role       string    `json:"role" gorm:"not null;type:varchar(20)"`
+	AssignedBy string    `json:"assigned_by" gorm:"type:varchar(255)"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Transient display fields
+	Name     string `json:"name,omitempty" gorm:"-"`
+	UserRole string `json:"user_role,omitempty" gorm:"-"`
+}
+
+// EventStaffRequest assigns or changes a staff member's role
+type EventStaffRequest struct {
+	StudentID string `json:"student_id"`
+	Role      string `json:"role"` // co_organizer or scanner
+}
@@ -0,0 +1,67 @@
+// models/event_template_model.go
+package models
+
+import "time"
+
+// EventTemplate is a saved set of event fields that can be turned into new
+// events. Templates belong to their owner; shared templates are also visible
+// to everyone in the template's department.
+type EventTemplate struct {
+	ID      uint   `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name    string `json:"name" gorm:"not null;type:varchar(255)"`
+	OwnerID string `json:"owner_id" gorm:"not null;type:varchar(255);index"` // StudentID of owner
+	Shared  bool   `json:"shared" gorm:"default:false"`
+
+	Title       string `json:"title" gorm:"type:varchar(255)"`
+	Description string `json:"description" gorm:"type:text"`
+	Location    string `json:"location" gorm:"type:varchar(255)"`
+	Course      string `json:"course" gorm:"type:varchar(100)"`
+	Section     string `json:"section" gorm:"type:varchar(50)"`
+	YearLevel   string `json:"year_level" gorm:"type:varchar(50)"`
+	Department  string `json:"department" gorm:"type:varchar(100);index"`
+	College     string `json:"college" gorm:"type:varchar(100)"`
+	StartTime   string `json:"start_time" gorm:"type:varchar(5)"` // HH:MM
+	EndTime     string `json:"end_time" gorm:"type:varchar(5)"`   // HH:MM
+
+	TaggedCoursesCSV string   `json:"-" gorm:"type:text;column:tagged_courses"`
+	TaggedCourses    []string `json:"tagged_courses,omitempty" gorm:"-"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// EventTemplateRequest creates or updates a template. When FromEventID is set
+// on create, the template is filled from that event first.
+type EventTemplateRequest struct {
+	Name          string   `json:"name"`
+	Shared        *bool    `json:"shared,omitempty"`
+	FromEventID   uint     `json:"from_event_id,omitempty"`
+	Title         string   `json:"title"`
+	Description   string   `json:"description"`
+	Location      string   `json:"location"`
+	Course        string   `json:"course"`
+	Section       string   `json:"section"`
+	YearLevel     string   `json:"year_level"`
+	Department    string   `json:"department"`
+	College       string   `json:"college"`
+	StartTime     string   `json:"start_time"` // HH:MM
+	EndTime       string   `json:"end_time"`   // HH:MM
+	TaggedCourses []string `json:"tagged_courses,omitempty"`
+}
+
+// EventFromTemplateRequest creates an event from a template on a given date.
+// StartTime/EndTime override the template's times.
+type EventFromTemplateRequest struct {
+	EventDate string `json:"event_date"` // YYYY-MM-DD
+	StartTime string `json:"start_time,omitempty"`
+	EndTime   string `json:"end_time,omitempty"`
+	Draft     bool   `json:"draft,omitempty"`
+}
+
+// CloneEventRequest copies an event to a new date: either an explicit
+// EventDate or a number of days to shift by.
+type CloneEventRequest struct {
+	EventDate string `json:"event_date,omitempty"` // YYYY-MM-DD
+	ShiftDays int    `json:"shift_days,omitempty"`
+	Title     string `json:"title,omitempty"`
+}
@@ -0,0 +1,69 @@
+// models/institution_model.go
+package models
+
+import "time"
+
+// College is the top of the institution hierarchy:
+// College → Department → Course/Program → Section
+type College struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Code      string    `json:"code" gorm:"type:varchar(20)"`
+	Name      string    `json:"name" gorm:"not null;type:varchar(100);uniqueIndex"`
+	IsActive  bool      `json:"is_active" gorm:"default:true"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Department belongs to a college
+type Department struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	CollegeID uint      `json:"college_id" gorm:"not null;uniqueIndex:idx_departments_college_name"`
+	Code      string    `json:"code" gorm:"type:varchar(20)"`
+	Name      string    `json:"name" gorm:"not null;type:varchar(100);uniqueIndex:idx_departments_college_name"`
+	IsActive  bool      `json:"is_active" gorm:"default:true"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Course is a degree program offered by a department (e.g. BSIT). Code is the
+// value stored on users and events (e.g. "IT").
+type Course struct {
+	ID           uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	DepartmentID uint      `json:"department_id" gorm:"not null;index"`
+	Code         string    `json:"code" gorm:"not null;type:varchar(100);uniqueIndex"`
+	Name         string    `json:"name" gorm:"type:varchar(255)"`
+	IsActive     bool      `json:"is_active" gorm:"default:true"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Section is a block of students within a course
+type Section struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	CourseID  uint      `json:"course_id" gorm:"not null;uniqueIndex:idx_sections_course_name"`
+	Name      string    `json:"name" gorm:"not null;type:varchar(50);uniqueIndex:idx_sections_course_name"`
+	YearLevel string    `json:"year_level,omitempty" gorm:"type:varchar(50)"`
+	IsActive  bool      `json:"is_active" gorm:"default:true"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// InstitutionRefs links a user or event to hierarchy rows by stable ID. The
+// free-text College/Department/Course/Section fields are kept for display and
+// older clients.
+type InstitutionRefs struct {
+	CollegeID    *uint `json:"college_id,omitempty" gorm:"index"`
+	DepartmentID *uint `json:"department_id,omitempty" gorm:"index"`
+	CourseID     *uint `json:"course_id,omitempty" gorm:"index"`
+	SectionID    *uint `json:"section_id,omitempty" gorm:"index"`
+}
+
+// InstitutionRequest creates or updates a hierarchy entry. ParentID is the
+// college for a department, the department for a course and the course for a section.
+type InstitutionRequest struct {
+	ParentID  uint   `json:"parent_id"`
+	Code      string `json:"code"`
+	Name      string `json:"name"`
+	YearLevel string `json:"year_level,omitempty"`
+	IsActive  *bool  `json:"is_active,omitempty"`
+}
@@ -0,0 +1,32 @@
+// models/job_model.go
+package models
+
+import "time"
+
+// Job run statuses
+const (
+	JobRunStatusRunning   = "running"
+	JobRunStatusSucceeded = "succeeded"
+	JobRunStatusFailed    = "failed"
+)
+
+// Job run triggers
+const (
+	JobTriggerSchedule = "schedule"
+	JobTriggerManual   = "manual"
+)
+
+// JobRun is one execution of a background job (run history)
+type JobRun struct {
+	ID          uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	JobName     string     `json:"job_name" gorm:"not null;type:varchar(100);index"`
+	Trigger     string     `json:"trigger" gorm:"type:varchar(20)"`                 // schedule, manual
+	TriggeredBy string     `json:"triggered_by,omitempty" gorm:"type:varchar(255)"` // StudentID for manual runs
+	InstanceID  string     `json:"instance_id" gorm:"type:varchar(255)"`
+	Status      string     `json:"status" gorm:"type:varchar(20);index"` // running, succeeded, failed
+	Result      string     `json:"result,omitempty" gorm:"type:text"`
+	Error       string     `json:"error,omitempty" gorm:"type:text"`
+	StartedAt   time.Time  `json:"started_at" gorm:"not null;index"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	DurationMS  int64      `json:"duration_ms"`
+}
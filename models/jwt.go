@@ -15,26 +15,52 @@ type JWTClaims struct {
 	StudentID string `json:"student_id"`
 	Email     string `json:"email"`
 	Role      string `json:"role"`
+	SessionID string `json:"sid,omitempty"` // login session, checked for revocation
 	jwt.RegisteredClaims
 }
 
 // RefreshTokenClaims contains refresh token claims
 type RefreshTokenClaims struct {
 	StudentID string `json:"student_id"`
+	SessionID string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
-// PasswordResetTokenClaims contains password reset token claims
+// PasswordResetTokenClaims contains password reset token claims. The code is
+// never carried; ResetID is set only once /verify-reset-code accepted a code
+// and names the reset it unlocks.
 type PasswordResetTokenClaims struct {
-	Email string `json:"email"`
-	Code  string `json:"code"`
+	Email   string `json:"email"`
+	ResetID uint   `json:"reset_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// EmailVerificationTokenClaims contains email verification token claims
+// EmailVerificationTokenClaims contains email verification token claims. The
+// code is never carried; it must come from the email.
 type EmailVerificationTokenClaims struct {
 	Email string `json:"email"`
-	Code  string `json:"code"`
+	jwt.RegisteredClaims
+}
+
+// ReminderOptOutTokenClaims identifies the event/student pair for the
+// one-click reminder opt-out link included in reminder emails
+type ReminderOptOutTokenClaims struct {
+	EventID   uint   `json:"event_id"`
+	StudentID string `json:"student_id"`
+	jwt.RegisteredClaims
+}
+
+// Two-factor challenge purposes
+const (
+	TwoFactorPurposeLogin = "2fa_login" // user has 2FA; a code completes the login
+	TwoFactorPurposeSetup = "2fa_setup" // role requires 2FA; user must enrol to log in
+)
+
+// TwoFactorChallengeClaims is issued after a correct password when a second
+// factor is still needed. The student ID is carried only in "sub" so the
+// token can never pass as an access token.
+type TwoFactorChallengeClaims struct {
+	Purpose string `json:"purpose"`
 	jwt.RegisteredClaims
 }
 
@@ -47,6 +73,10 @@ const (
 	PasswordResetTokenExpiry = 15 * time.Minute
 	// EmailVerificationTokenExpiry is the expiration time for email verification tokens (15 minutes)
 	EmailVerificationTokenExpiry = 15 * time.Minute
+	// ReminderOptOutTokenExpiry is the expiration time for reminder opt-out links (30 days)
+	ReminderOptOutTokenExpiry = 30 * 24 * time.Hour
+	// TwoFactorChallengeExpiry is the expiration time for 2FA login challenges (5 minutes)
+	TwoFactorChallengeExpiry = 5 * time.Minute
 )
 
 var (
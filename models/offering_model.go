@@ -0,0 +1,74 @@
+// models/offering_model.go
+package models
+
+import "time"
+
+// ClassOffering is a subject taught to an enrolled group of students in a term
+// (e.g. IT-204, section B, 1st semester). Events created for an offering target
+// its enrollment list instead of course/year/section matching.
+type ClassOffering struct {
+	ID           uint   `json:"id" gorm:"primaryKey;autoIncrement"`
+	SubjectCode  string `json:"subject_code" gorm:"not null;type:varchar(50);index"` // e.g. "IT-204"
+	SubjectTitle string `json:"subject_title" gorm:"type:varchar(255)"`
+	Section      string `json:"section" gorm:"type:varchar(50)"`
+	Course       string `json:"course,omitempty" gorm:"type:varchar(100)"`
+	YearLevel    string `json:"year_level,omitempty" gorm:"type:varchar(50)"`
+	TermID       *uint  `json:"term_id,omitempty" gorm:"index"`
+	FacultyID    string `json:"faculty_id" gorm:"not null;type:varchar(255);index"` // StudentID of the assigned faculty
+	IsActive     bool   `json:"is_active" gorm:"default:true"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Transient
+	EnrollmentCount int `json:"enrollment_count" gorm:"-"`
+}
+
+// Enrollment places a student in a class offering
+type Enrollment struct {
+	ID         uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	OfferingID uint      `json:"offering_id" gorm:"not null;uniqueIndex:idx_enrollments_offering_student"`
+	StudentID  string    `json:"student_id" gorm:"not null;type:varchar(255);uniqueIndex:idx_enrollments_offering_student;index"`
+	EnrolledBy string    `json:"enrolled_by" gorm:"type:varchar(255)"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	// Transient
+	Name string `json:"name,omitempty" gorm:"-"`
+}
+
+// ClassOfferingRequest creates or updates an offering. Only admins may set
+// FacultyID to someone else.
+type ClassOfferingRequest struct {
+	SubjectCode  string `json:"subject_code"`
+	SubjectTitle string `json:"subject_title"`
+	Section      string `json:"section"`
+	Course       string `json:"course"`
+	YearLevel    string `json:"year_level"`
+	TermID       uint   `json:"term_id"`
+	FacultyID    string `json:"faculty_id"`
+	IsActive     *bool  `json:"is_active,omitempty"`
+}
+
+// EnrollmentRequest adds students to an offering
+type EnrollmentRequest struct {
+	StudentIDs []string `json:"student_ids"`
+}
+
+// EnrollmentResult reports the outcome of an enrollment batch (API or CSV)
+type EnrollmentResult struct {
+	Enrolled        int      `json:"enrolled"`
+	AlreadyEnrolled int      `json:"already_enrolled"`
+	NotFound        []string `json:"not_found,omitempty"`
+}
+
+// OfferingStudentReport is one row of an offering attendance report
+type OfferingStudentReport struct {
+	StudentID      string  `json:"student_id"`
+	Name           string  `json:"name"`
+	Sessions       int     `json:"sessions"`
+	PresentCount   int     `json:"present_count"`
+	LateCount      int     `json:"late_count"`
+	ExcusedCount   int     `json:"excused_count"`
+	AbsentCount    int     `json:"absent_count"`
+	AttendanceRate float64 `json:"attendance_rate"`
+}
@@ -0,0 +1,20 @@
+// models/password_history_model.go
+package models
+
+import "time"
+
+// PasswordHistory keeps hashes of a user's previous passwords so recent ones
+// cannot be reused
+type PasswordHistory struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement"`
+	StudentID    string    `gorm:"not null;type:varchar(255);index"`
+	PasswordHash string    `gorm:"not null;type:varchar(255)"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+}
+
+// ChangePasswordRequest changes the logged-in user's password
+type ChangePasswordRequest struct {
+	CurrentPassword    string `json:"current_password"`
+	NewPassword        string `json:"new_password"`
+	ConfirmNewPassword string `json:"confirm_new_password"`
+}
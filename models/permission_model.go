@@ -0,0 +1,66 @@
+// models/permission_model.go
+package models
+
+import "time"
+
+// Permissions that can be granted to roles
+const (
+	PermEventCreate        = "event.create"        // create, clone and template events
+	PermEventDeleteAny     = "event.delete.any"    // delete events created by others in the user's department
+	PermAttendanceOverride = "attendance.override" // change attendance status and scan outside the check-in window
+	PermUserPromote        = "user.promote"        // change other users' roles
+	PermAuditRead          = "audit.read"          // read the audit log
+	PermUserUnlock         = "user.unlock"         // view lockouts and unlock accounts after failed attempts
+	PermProfileLock        = "profile.lock"        // lock profile fields and edit own locked fields
+	PermUserStatus         = "user.status"         // suspend, deactivate, delete and restore accounts
+)
+
+// AllPermissions lists every known permission
+var AllPermissions = []string{
+	PermEventCreate,
+	PermEventDeleteAny,
+	PermAttendanceOverride,
+	PermUserPromote,
+	PermAuditRead,
+	PermUserUnlock,
+	PermProfileLock,
+	PermUserStatus,
+}
+
+// Role is a named set of permissions that users can be assigned
+type Role struct {
+	Name        string    `json:"name" gorm:"primaryKey;type:varchar(50)"`
+	Description string    `json:"description" gorm:"type:varchar(255)"`
+	IsSystem    bool      `json:"is_system" gorm:"default:false"` // built-in roles cannot be deleted
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	Permissions []string `json:"permissions" gorm:"-"`
+}
+
+// RolePermission grants a permission to a role
+type RolePermission struct {
+	ID         uint   `json:"id" gorm:"primaryKey;autoIncrement"`
+	RoleName   string `json:"role_name" gorm:"not null;type:varchar(50);uniqueIndex:idx_role_permission_unique"`
+	Permission string `json:"permission" gorm:"not null;type:varchar(100);uniqueIndex:idx_role_permission_unique"`
+}
+
+// RoleDefaultGrant records that a built-in role received one of its default
+// permissions. Each default is granted once, so defaults added later reach
+// existing databases while a default a superadmin removed stays removed.
+type RoleDefaultGrant struct {
+	RoleName   string    `json:"role_name" gorm:"primaryKey;type:varchar(50)"`
+	Permission string    `json:"permission" gorm:"primaryKey;type:varchar(100)"`
+	GrantedAt  time.Time `json:"granted_at" gorm:"autoCreateTime"`
+}
+
+// RoleRequest creates a custom role
+type RoleRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// RolePermissionsRequest replaces a role's permissions
+type RolePermissionsRequest struct {
+	Permissions []string `json:"permissions"`
+}
@@ -0,0 +1,56 @@
+// models/profile_model.go
+package models
+
+import "time"
+
+// LockableProfileFields can be locked against self-service edits because they
+// decide which events a user is eligible for, or (the photo) identify them at scanners
+var LockableProfileFields = []string{"college", "department", "course", "year_level", "section", "profile_picture"}
+
+// ProfileFieldLock marks a profile field as editable only by users holding
+// the profile.lock permission
+type ProfileFieldLock struct {
+	Field    string    `json:"field" gorm:"primaryKey;type:varchar(30)"`
+	LockedBy string    `json:"locked_by" gorm:"type:varchar(255)"`
+	LockedAt time.Time `json:"locked_at" gorm:"autoCreateTime"`
+}
+
+// EmailChange is a requested new email address waiting for its verification code
+type EmailChange struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	StudentID string    `gorm:"uniqueIndex;not null;type:varchar(255)"`
+	NewEmail  string    `gorm:"not null;type:varchar(255);index"`
+	Code      string    `gorm:"not null;type:varchar(10)"`
+	ExpiresAt time.Time `gorm:"index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// ProfileLocksRequest replaces the set of locked profile fields
+type ProfileLocksRequest struct {
+	Fields []string `json:"fields"`
+}
+
+// VerifyEmailChangeRequest confirms a new email address
+type VerifyEmailChangeRequest struct {
+	Code string `json:"code"`
+}
+
+// ProfileResponse is the authenticated user's own profile
+type ProfileResponse struct {
+	Message string `json:"message"`
+	UserResponse
+	InstitutionRefs
+	Locale           string   `json:"locale,omitempty"`
+	TwoFactorEnabled bool     `json:"two_factor_enabled"`
+	LockedFields     []string `json:"locked_fields"`
+	PendingEmail     string   `json:"pending_email,omitempty"`
+
+	ProfilePictureURL          string `json:"profile_picture_url,omitempty"`
+	ProfilePictureThumbnailURL string `json:"profile_picture_thumbnail_url,omitempty"`
+}
+
+// ProfilePictureRequest uploads a photo as a base64 data URL
+// (multipart uploads use the "photo" form field instead)
+type ProfilePictureRequest struct {
+	Image string `json:"image"`
+}
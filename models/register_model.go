@@ -9,7 +9,7 @@ type User struct {
 	ID         uint      `json:"-" gorm:"primaryKey;autoIncrement"`
 	StudentID  string    `json:"student_id" gorm:"uniqueIndex;type:varchar(255);not null"`
 	Email      string    `json:"email" gorm:"uniqueIndex;not null;type:varchar(255)"`
-	Password   string    `json:"password" gorm:"not null;type:varchar(255)"`
+	Password   string    `json:"-" gorm:"not null;type:varchar(255)"`
 	Username   string    `json:"username" gorm:"not null;type:varchar(255)"`
 	Role       string    `json:"role" gorm:"not null;type:varchar(50);default:'student'"`
 	IsVerified bool      `json:"is_verified" gorm:"default:false"`
@@ -26,6 +26,22 @@ type User struct {
 	College       string `json:"college,omitempty" gorm:"type:varchar(100)"`
 	ContactNumber string `json:"contact_number,omitempty" gorm:"type:varchar(20)"`
 	Address       string `json:"address,omitempty" gorm:"type:text"`
+	Locale        string `json:"locale,omitempty" gorm:"type:varchar(10)"` // preferred email language (en, fil)
+
+	// Storage key prefix of the profile photo thumbnails, never the image itself
+	ProfilePicture string `json:"-" gorm:"type:text"`
+
+	// Account state (see AccountStatuses) and soft deletion; attendance is never removed
+	Status          string     `json:"status" gorm:"type:varchar(20);not null;default:'active';index"`
+	StatusReason    string     `json:"status_reason,omitempty" gorm:"type:varchar(500)"`
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty" gorm:"index"`
+
+	// Stable institution hierarchy IDs for College/Department/Course/Section
+	InstitutionRefs
+
+	// Service accounts authenticate only with API keys, never with a password
+	IsServiceAccount bool `json:"is_service_account,omitempty" gorm:"default:false"`
 
 	QRCodeData    string    `json:"qr_code_data,omitempty" gorm:"type:text"`
 	QRType        string    `json:"qr_type" gorm:"type:varchar(50);default:'student_id'"`
@@ -53,10 +69,13 @@ type RegisterRequest struct {
 	College       string `json:"college,omitempty"`
 	ContactNumber string `json:"contact_number,omitempty"`
 	Address       string `json:"address,omitempty"`
+	Locale        string `json:"locale,omitempty"`
 }
 
-// UpdateUserRequest for user profile updates
+// UpdateUserRequest for user profile updates. Empty fields are left unchanged;
+// a new Email only applies once it has been verified.
 type UpdateUserRequest struct {
+	Email         string `json:"email,omitempty"`
 	FirstName     string `json:"first_name,omitempty"`
 	LastName      string `json:"last_name,omitempty"`
 	MiddleName    string `json:"middle_name,omitempty"`
@@ -67,26 +86,63 @@ type UpdateUserRequest struct {
 	College       string `json:"college,omitempty"`
 	ContactNumber string `json:"contact_number,omitempty"`
 	Address       string `json:"address,omitempty"`
+	Locale        string `json:"locale,omitempty"`
 }
 
 // UserResponse for API responses
 type UserResponse struct {
-	ID            uint      `json:"id"`
-	StudentID     string    `json:"student_id"`
-	Email         string    `json:"email"`
-	Username      string    `json:"username"`
-	Role          string    `json:"role"`
-	IsVerified    bool      `json:"is_verified"`
-	FirstName     string    `json:"first_name"`
-	LastName      string    `json:"last_name"`
-	MiddleName    string    `json:"middle_name,omitempty"`
-	Course        string    `json:"course,omitempty"`
-	YearLevel     string    `json:"year_level,omitempty"`
-	Section       string    `json:"section,omitempty"`
-	Department    string    `json:"department,omitempty"`
-	College       string    `json:"college,omitempty"`
-	ContactNumber string    `json:"contact_number,omitempty"`
-	Address       string    `json:"address,omitempty"`
-	CreatedAt     time.Time `json:"created_at"`
-	VerifiedAt    time.Time `json:"verified_at,omitempty"`
+	ID            uint       `json:"id"`
+	StudentID     string     `json:"student_id"`
+	Email         string     `json:"email"`
+	Username      string     `json:"username"`
+	Role          string     `json:"role"`
+	IsVerified    bool       `json:"is_verified"`
+	FirstName     string     `json:"first_name"`
+	LastName      string     `json:"last_name"`
+	MiddleName    string     `json:"middle_name,omitempty"`
+	Course        string     `json:"course,omitempty"`
+	YearLevel     string     `json:"year_level,omitempty"`
+	Section       string     `json:"section,omitempty"`
+	Department    string     `json:"department,omitempty"`
+	College       string     `json:"college,omitempty"`
+	ContactNumber string     `json:"contact_number,omitempty"`
+	Address       string     `json:"address,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	VerifiedAt    time.Time  `json:"verified_at,omitempty"`
+	Status        string     `json:"status"`
+	DeletedAt     *time.Time `json:"deleted_at,omitempty"`
+}
+
+// NewUserResponse copies the public fields of a user
+func NewUserResponse(u User) UserResponse {
+	return UserResponse{
+		ID:            u.ID,
+		StudentID:     u.StudentID,
+		Email:         u.Email,
+		Username:      u.Username,
+		Role:          u.Role,
+		IsVerified:    u.IsVerified,
+		FirstName:     u.FirstName,
+		LastName:      u.LastName,
+		MiddleName:    u.MiddleName,
+		Course:        u.Course,
+		YearLevel:     u.YearLevel,
+		Section:       u.Section,
+		Department:    u.Department,
+		College:       u.College,
+		ContactNumber: u.ContactNumber,
+		Address:       u.Address,
+		CreatedAt:     u.CreatedAt,
+		VerifiedAt:    u.VerifiedAt,
+		Status:        u.AccountStatus(),
+		DeletedAt:     u.DeletedAt,
+	}
+}
+
+// AccountStatus returns the account state, treating rows from before states existed as active
+func (u User) AccountStatus() string {
+	if u.Status == "" {
+		return AccountActive
+	}
+	return u.Status
 }
@@ -0,0 +1,21 @@
+// models/reminder_model.go
+package models
+
+import "time"
+
+// EventReminder records a reminder that was sent so restarts never resend it
+type EventReminder struct {
+	ID            uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	EventID       uint      `json:"event_id" gorm:"not null;uniqueIndex:idx_event_reminders_unique"`
+	StudentID     string    `json:"student_id" gorm:"not null;type:varchar(255);uniqueIndex:idx_event_reminders_unique"`
+	OffsetMinutes int       `json:"offset_minutes" gorm:"not null;uniqueIndex:idx_event_reminders_unique"`
+	SentAt        time.Time `json:"sent_at" gorm:"autoCreateTime"`
+}
+
+// EventReminderOptOut marks a student who does not want reminders for an event
+type EventReminderOptOut struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	EventID   uint      `json:"event_id" gorm:"not null;uniqueIndex:idx_event_reminder_opt_outs_unique"`
+	StudentID string    `json:"student_id" gorm:"not null;type:varchar(255);uniqueIndex:idx_event_reminder_opt_outs_unique"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
@@ -0,0 +1,78 @@
+// models/service_account_model.go
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// APIKeyHeader carries a service account API key for machine clients
+const APIKeyHeader = "X-API-Key"
+
+// ServiceAccount is a non-human principal for integrations. It is backed by a
+// users row (IsServiceAccount) so roles, policies and audit entries apply to
+// it like to any other user; it authenticates only with API keys.
+type ServiceAccount struct {
+	ID          uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	StudentID   string     `json:"student_id" gorm:"uniqueIndex;not null;type:varchar(255)"`
+	Name        string     `json:"name" gorm:"not null;type:varchar(100)"`
+	Description string     `json:"description,omitempty" gorm:"type:varchar(255)"`
+	CreatedBy   string     `json:"created_by" gorm:"type:varchar(255)"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	DisabledAt  *time.Time `json:"disabled_at,omitempty"`
+
+	Role string `json:"role" gorm:"-"`
+}
+
+// APIKey is a hashed, scoped credential of a service account. Only Prefix is
+// stored in clear for display; the full key is shown once at creation.
+type APIKey struct {
+	ID               uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	ServiceAccountID uint       `json:"service_account_id" gorm:"not null;index"`
+	Name             string     `json:"name" gorm:"type:varchar(100)"`
+	Prefix           string     `json:"prefix" gorm:"not null;type:varchar(20);index"`
+	KeyHash          string     `json:"-" gorm:"not null;type:varchar(64);uniqueIndex"`
+	ScopesCSV        string     `json:"-" gorm:"column:scopes;type:text"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt       *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP       string     `json:"last_used_ip,omitempty" gorm:"type:varchar(255)"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+	CreatedBy        string     `json:"created_by" gorm:"type:varchar(255)"`
+	CreatedAt        time.Time  `json:"created_at" gorm:"autoCreateTime"`
+
+	Scopes []string `json:"scopes" gorm:"-"`
+}
+
+// LoadScopes fills Scopes from the stored CSV
+func (k *APIKey) LoadScopes() {
+	k.Scopes = []string{}
+	for _, s := range strings.Split(k.ScopesCSV, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			k.Scopes = append(k.Scopes, s)
+		}
+	}
+}
+
+// HasScope reports whether the key was granted a permission
+func (k *APIKey) HasScope(permission string) bool {
+	for _, s := range strings.Split(k.ScopesCSV, ",") {
+		if strings.TrimSpace(s) == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// ServiceAccountRequest creates a service account
+type ServiceAccountRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Role        string `json:"role"`
+}
+
+// APIKeyRequest creates an API key
+type APIKeyRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days"` // 0 = never
+}
@@ -0,0 +1,43 @@
+// models/session_model.go
+package models
+
+import "time"
+
+// Reasons recorded when a session is revoked
+const (
+	SessionRevokedLogout         = "logout"
+	SessionRevokedLogoutAll      = "logout_all"
+	SessionRevokedReuse          = "refresh_token_reuse"
+	SessionRevokedPasswordReset  = "password_reset"
+	SessionRevokedRoleChange     = "role_change"
+	SessionRevokedPasswordChange = "password_change"
+	SessionRevoked2FAReset       = "two_factor_reset"
+	SessionRevoked2FARequired    = "two_factor_required"
+	SessionRevokedAccountStatus  = "account_status"
+)
+
+// AuthSession is one login (a refresh token family). Every refresh rotates
+// the token but keeps the session.
+type AuthSession struct {
+	ID            string     `json:"id" gorm:"primaryKey;type:varchar(64)"`
+	StudentID     string     `json:"-" gorm:"not null;type:varchar(255);index"`
+	UserAgent     string     `json:"user_agent" gorm:"type:varchar(512)"`
+	IPAddress     string     `json:"ip_address" gorm:"type:varchar(255)"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	LastUsedAt    time.Time  `json:"last_used_at"`
+	ExpiresAt     time.Time  `json:"expires_at" gorm:"index"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+	RevokedReason string     `json:"revoked_reason,omitempty" gorm:"type:varchar(50)"`
+
+	Current bool `json:"current" gorm:"-"`
+}
+
+// RefreshToken is a single issued refresh token. RotatedAt is set once it has
+// been exchanged; presenting it again is treated as theft.
+type RefreshToken struct {
+	ID        string    `gorm:"primaryKey;type:varchar(64)"` // JWT ID (jti)
+	SessionID string    `gorm:"not null;type:varchar(64);index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	ExpiresAt time.Time `gorm:"index"`
+	RotatedAt *time.Time
+}
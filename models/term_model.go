@@ -0,0 +1,27 @@
+// models/term_model.go
+package models
+
+import "time"
+
+// AcademicTerm is a semester or other grading period. Events are attached to
+// the term whose dates contain the event date. Closing a term freezes the
+// attendance of its events.
+type AcademicTerm struct {
+	ID        uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name      string     `json:"name" gorm:"not null;type:varchar(100);uniqueIndex"` // e.g. "1st Semester 2026-2027"
+	StartDate time.Time  `json:"start_date" gorm:"not null;type:date"`
+	EndDate   time.Time  `json:"end_date" gorm:"not null;type:date"`
+	IsActive  bool       `json:"is_active" gorm:"default:false"` // the current term; at most one
+	IsClosed  bool       `json:"is_closed" gorm:"default:false"`
+	ClosedAt  *time.Time `json:"closed_at,omitempty"`
+	ClosedBy  string     `json:"closed_by,omitempty" gorm:"type:varchar(255)"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// AcademicTermRequest creates or updates a term. Dates are YYYY-MM-DD.
+type AcademicTermRequest struct {
+	Name      string `json:"name"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+}
@@ -0,0 +1,45 @@
+// models/two_factor_model.go
+package models
+
+import "time"
+
+// TwoFactor holds a user's TOTP enrolment. Secret is set at setup; the
+// factor only counts once Enabled after the first valid code.
+type TwoFactor struct {
+	ID           uint       `json:"-" gorm:"primaryKey;autoIncrement"`
+	StudentID    string     `json:"-" gorm:"uniqueIndex;not null;type:varchar(255)"`
+	Secret       string     `json:"-" gorm:"not null;type:varchar(64)"`
+	Enabled      bool       `json:"enabled" gorm:"default:false"`
+	EnabledAt    *time.Time `json:"enabled_at,omitempty"`
+	LastUsedStep int64      `json:"-"` // last accepted TOTP time step, prevents code replay
+	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// RecoveryCode is a one-time backup code, stored hashed
+type RecoveryCode struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	StudentID string `gorm:"not null;type:varchar(255);index"`
+	CodeHash  string `gorm:"not null;type:varchar(64)"`
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// TwoFactorCodeRequest carries a TOTP code or a recovery code
+type TwoFactorCodeRequest struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code,omitempty"`
+}
+
+// TwoFactorLoginRequest completes a login challenge
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code,omitempty"`
+}
+
+// DisableTwoFactorRequest re-authenticates before 2FA is turned off
+type DisableTwoFactorRequest struct {
+	Password     string `json:"password"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code,omitempty"`
+}
@@ -0,0 +1,157 @@
+// services/account_status_service.go
+package services
+
+import (
+	"attendance-system/connection"
+	"attendance-system/models"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Errors returned for accounts that may not sign in
+var (
+	ErrAccountSuspended   = errors.New("this account is suspended. Please contact an administrator")
+	ErrAccountDeactivated = errors.New("this account is deactivated. Please contact an administrator")
+	ErrAccountDeleted     = errors.New("this account has been deleted")
+	ErrAccountNotActive   = errors.New("student account is not active")
+)
+
+// CheckAccountAccess returns why a user may not sign in or use a session, or nil
+func CheckAccountAccess(user models.User) error {
+	if user.DeletedAt != nil {
+		return ErrAccountDeleted
+	}
+	switch user.AccountStatus() {
+	case models.AccountSuspended:
+		return ErrAccountSuspended
+	case models.AccountDeactivated:
+		return ErrAccountDeactivated
+	}
+	return nil
+}
+
+// CanBeMarkedPresent reports whether attendance may still be recorded for user
+func CanBeMarkedPresent(user models.User) bool {
+	return user.DeletedAt == nil && user.AccountStatus() == models.AccountActive
+}
+
+// validAccountStatus reports whether status is a known account state
+func validAccountStatus(status string) bool {
+	for _, s := range models.AccountStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// SetAccountStatus moves a user to another account state. Suspending or
+// deactivating ends every session of the user.
+func SetAccountStatus(studentID, status, reason, actor, ipAddress string) (*models.User, error) {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if !validAccountStatus(status) {
+		return nil, fmt.Errorf("invalid status. Use one of: %s", strings.Join(models.AccountStatuses, ", "))
+	}
+	reason = strings.TrimSpace(reason)
+	if len(reason) > 500 {
+		return nil, errors.New("reason must be at most 500 characters")
+	}
+	if studentID == actor {
+		return nil, errors.New("you cannot change the status of your own account")
+	}
+
+	var user models.User
+	if err := GetUserByStudentID(studentID, &user); err != nil {
+		return nil, errors.New(models.ErrUserNotFound)
+	}
+	previous := user.AccountStatus()
+	if previous == status {
+		return nil, fmt.Errorf("account is already %s", status)
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":            status,
+		"status_reason":     reason,
+		"status_changed_at": now,
+	}
+	if err := connection.DB.Model(&models.User{}).Where(studentWhere, studentID).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update account status: %v", err)
+	}
+	if status == models.AccountSuspended || status == models.AccountDeactivated {
+		if _, err := RevokeAllSessions(studentID, models.SessionRevokedAccountStatus); err != nil {
+			return nil, err
+		}
+	}
+
+	details := previous + " -> " + status
+	if reason != "" {
+		details += ": " + reason
+	}
+	go LogAuditAction(AuditAccountStatus, actor, studentID, details, ipAddress)
+
+	user.Status, user.StatusReason, user.StatusChangedAt = status, reason, &now
+	return &user, nil
+}
+
+// SoftDeleteUser hides a user and blocks sign-in while keeping the row, and
+// with it all attendance history. RestoreUser undoes it.
+func SoftDeleteUser(studentID, reason, actor, ipAddress string) error {
+	if studentID == "" {
+		return fmt.Errorf("student id is required")
+	}
+	if studentID == actor {
+		return errors.New("you cannot delete your own account")
+	}
+	var user models.User
+	if err := GetUserByStudentID(studentID, &user); err != nil {
+		return errors.New(models.ErrUserNotFound)
+	}
+	if user.DeletedAt != nil {
+		return errors.New("user is already deleted")
+	}
+
+	if err := connection.DB.Model(&models.User{}).Where(studentWhere, studentID).Update("deleted_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to delete user: %v", err)
+	}
+	if _, err := RevokeAllSessions(studentID, models.SessionRevokedAccountStatus); err != nil {
+		return err
+	}
+
+	go LogAuditAction(AuditUserDeleted, actor, studentID, strings.TrimSpace(reason), ipAddress)
+	return nil
+}
+
+// RestoreUser brings back a soft-deleted user in the state they had before
+func RestoreUser(studentID, actor, ipAddress string) (*models.User, error) {
+	var user models.User
+	if err := GetUserByStudentID(studentID, &user); err != nil {
+		return nil, errors.New(models.ErrUserNotFound)
+	}
+	if user.DeletedAt == nil {
+		return nil, errors.New("user is not deleted")
+	}
+	if err := connection.DB.Model(&models.User{}).Where(studentWhere, studentID).Update("deleted_at", nil).Error; err != nil {
+		return nil, fmt.Errorf("failed to restore user: %v", err)
+	}
+
+	go LogAuditAction(AuditUserRestored, actor, studentID, "status "+user.AccountStatus(), ipAddress)
+	user.DeletedAt = nil
+	return &user, nil
+}
+
+// ListDeletedUsers returns the soft-deleted users the viewer administers,
+// most recently deleted first
+func ListDeletedUsers(viewer models.User) ([]models.UserResponse, error) {
+	var users []models.User
+	if err := scopeAdministeredUsers(connection.DB.Where("deleted_at IS NOT NULL"), viewer).Order("deleted_at DESC").Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch deleted users: %v", err)
+	}
+	out := make([]models.UserResponse, 0, len(users))
+	for _, u := range users {
+		out = append(out, models.NewUserResponse(u))
+	}
+	return out, nil
+}
@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 const (
@@ -20,6 +22,10 @@ const (
 // Sentinel error returned when a student is not allowed to enter a tagged event
 var ErrEventAccessDenied = errors.New("Not Authorized to scan QR Code")
 
+// Sentinel error returned when someone marks another user's attendance without
+// being the event owner, a co-organizer, a scanner, or an admin
+var ErrScanNotAllowed = errors.New("you are not assigned to scan for this event")
+
 // MarkAttendance marks attendance for a student in an event (check-in or check-out)
 func MarkAttendance(req models.AttendanceRequest, markedBy, markedByRole string) (*models.Attendance, error) {
 	// Validate and load required data
@@ -31,12 +37,27 @@ func MarkAttendance(req models.AttendanceRequest, markedBy, markedByRole string)
 	if err != nil {
 		return nil, err
 	}
+	if err := ensureEventTermOpen(event); err != nil {
+		return nil, err
+	}
 
 	studentID := resolveStudentID(req.StudentID, markedBy)
 	student, err := loadStudentByID(studentID)
 	if err != nil {
 		return nil, err
 	}
+	// Suspended, graduated and deleted students keep their history but get no new records
+	if !CanBeMarkedPresent(student) {
+		return nil, ErrAccountNotActive
+	}
+
+	// Marking someone else requires a staff assignment on this event
+	if studentID != markedBy {
+		scanner, err := loadStudentByID(markedBy)
+		if err != nil || !canScanEvent(&event, scanner) {
+			return nil, ErrScanNotAllowed
+		}
+	}
 
 	// Enforce course-tag restrictions (students only)
 	if err := enforceEventCourseAccess(event, student, markedByRole); err != nil {
@@ -66,6 +87,7 @@ func MarkAttendance(req models.AttendanceRequest, markedBy, markedByRole string)
 	// Attach student info to the returned attendance so callers (e.g., admin scan)
 	// can immediately show the student's name without an extra request.
 	attendance.Student = student
+	attendance.StudentPhotoURL = ProfilePictureURL(student.ProfilePicture, ProfilePictureSize)
 
 	// Calculate total attendance count for this student
 	var studentCount int64
@@ -98,21 +120,24 @@ func determineTimeStatus(actualTime time.Time, expectedTime time.Time, gracePeri
 }
 
 // applyCheckIn applies check-in logic to the attendance record
-// Students can check in starting 30 minutes before event start until event ends
+// Students can check in starting 30 minutes before event start until event ends,
+// or at any time while the event has been started manually (status ongoing)
 // Faculty/Admin can check in anytime (for setup/testing)
 func applyCheckIn(att *models.Attendance, now time.Time, event models.Event, student models.User, markedByRole string) error {
 	if att.CheckInTime != nil {
 		return errors.New("already checked in")
 	}
 
-	// Faculty and admin can scan anytime (for setup/testing purposes)
-	if markedByRole == "faculty" || markedByRole == "admin" || markedByRole == "superadmin" {
+	// Roles with attendance.override can scan anytime (for setup/testing purposes)
+	if HasPermission(markedByRole, models.PermAttendanceOverride) {
 		// Admins/faculty can scan anytime, but still within event availability
 		// Allow scanning up to 1 day after event ends
 		if now.After(event.EndTime.Add(24 * time.Hour)) {
 			return errors.New("event has ended more than 24 hours ago. Check-in is no longer allowed")
 		}
-	} else {
+	} else if event.Status == models.EventStatusCompleted {
+		return errors.New("event has already ended. Check-in is no longer allowed")
+	} else if event.Status != models.EventStatusOngoing {
 		// Students: Allow check-in 30 minutes before event start until event ends (real-time scanning)
 		earliestCheckIn := event.StartTime.Add(-30 * time.Minute)
 		if now.Before(earliestCheckIn) {
@@ -169,6 +194,9 @@ func loadEventByID(eventID uint) (models.Event, error) {
 	if !event.IsActive {
 		return event, errors.New("event is not active")
 	}
+	if event.Status == models.EventStatusDraft {
+		return event, errors.New("event has not been published yet")
+	}
 	return event, nil
 }
 
@@ -243,6 +271,14 @@ func enforceEventCourseAccess(event models.Event, student models.User, markedByR
 		return nil
 	}
 
+	// Class offering events are limited to the enrollment list
+	if event.OfferingID != nil {
+		if !isEnrolled(*event.OfferingID, student.StudentID) {
+			return ErrEventAccessDenied
+		}
+		return nil
+	}
+
 	// STRICT VALIDATION: Student must belong to a course
 	if student.Course == "" {
 		return errors.New("student course is not set. contact admin")
@@ -326,55 +362,36 @@ func persistAttendance(att *models.Attendance, isNew bool) error {
 
 // sendCheckInNotification sends email to superadmin, admin, and event creator when student checks in
 func sendCheckInNotification(event models.Event, student models.User, checkInTime time.Time, status string) {
-	// Get superadmin and admin emails
-	var admins []models.User
-	connection.DB.Where("role IN ?", []string{"superadmin", "admin"}).Find(&admins)
-
-	// Add event creator if not already in list
-	var creator models.User
-	if connection.DB.Where(StudentWhere, event.CreatedBy).First(&creator).Error == nil {
-		found := false
-		for _, admin := range admins {
-			if admin.StudentID == creator.StudentID {
-				found = true
-				break
-			}
-		}
-		if !found {
-			admins = append(admins, creator)
-		}
-	}
-
-	// Format time
-	timeStr := checkInTime.Format("January 2, 2006 3:04 PM")
-	statusText := map[string]string{
-		"early":   "Early (arrived before scheduled time)",
-		"on_time": "On Time",
-		"late":    "Late",
-	}[status]
-
-	content := fmt.Sprintf(`<p><strong>Event:</strong> %s</p>
-		<p><strong>Student:</strong> %s %s (%s)</p>
-		<p><strong>Student ID:</strong> %s</p>
-		<p><strong>Check-In Time:</strong> %s</p>
-		<p><strong>Status:</strong> %s</p>
-		<p><strong>Location:</strong> %s</p>`, event.Title, student.FirstName, student.LastName, student.Username, student.StudentID, timeStr, statusText, event.Location)
-
-	footer := `<p class="muted">This is an automated notification from the Attendance System.</p>`
+	sendAttendanceNotification(EmailTemplateCheckIn, event, student, checkInTime, status)
+}
 
-	htmlBody := BuildHTMLEmail("Student check-in", "Student Check-In Notification", content, footer)
+// sendCheckOutNotification sends email to superadmin, admin, and event creator when student checks out
+func sendCheckOutNotification(event models.Event, student models.User, checkOutTime time.Time, status string) {
+	sendAttendanceNotification(EmailTemplateCheckOut, event, student, checkOutTime, status)
+}
 
-	// Send email to all admins
-	for _, admin := range admins {
-		if admin.Email != "" {
-			SendEmail(admin.Email, fmt.Sprintf("Check-In: %s %s - %s", student.FirstName, student.LastName, event.Title), htmlBody)
+// sendAttendanceNotification renders the check-in/out template in each recipient's
+// locale. Event and student fields are escaped by the template engine.
+func sendAttendanceNotification(templateName string, event models.Event, student models.User, at time.Time, status string) {
+	data := map[string]interface{}{
+		"EventTitle":  event.Title,
+		"StudentName": strings.TrimSpace(student.FirstName + " " + student.LastName),
+		"Username":    student.Username,
+		"StudentID":   student.StudentID,
+		"Time":        at.Format("January 2, 2006 3:04 PM"),
+		"Status":      status,
+		"Location":    event.Location,
+	}
+
+	for _, recipient := range attendanceNotificationRecipients(event) {
+		if recipient.Email != "" {
+			SendTemplatedEmail(recipient.Email, templateName, recipient.Locale, data)
 		}
 	}
 }
 
-// sendCheckOutNotification sends email to superadmin, admin, and event creator when student checks out
-func sendCheckOutNotification(event models.Event, student models.User, checkOutTime time.Time, status string) {
-	// Get superadmin and admin emails
+// attendanceNotificationRecipients returns superadmins, admins and the event creator
+func attendanceNotificationRecipients(event models.Event) []models.User {
 	var admins []models.User
 	connection.DB.Where("role IN ?", []string{"superadmin", "admin"}).Find(&admins)
 
@@ -392,36 +409,20 @@ func sendCheckOutNotification(event models.Event, student models.User, checkOutT
 			admins = append(admins, creator)
 		}
 	}
+	return admins
+}
 
-	// Format time
-	timeStr := checkOutTime.Format("January 2, 2006 3:04 PM")
-	statusText := map[string]string{
-		"early":   "Left Early",
-		"on_time": "On Time",
-		"late":    "Left Late",
-	}[status]
-
-	content := fmt.Sprintf(`<p><strong>Event:</strong> %s</p>
-		<p><strong>Student:</strong> %s %s (%s)</p>
-		<p><strong>Student ID:</strong> %s</p>
-		<p><strong>Check-Out Time:</strong> %s</p>
-		<p><strong>Status:</strong> %s</p>
-		<p><strong>Location:</strong> %s</p>`, event.Title, student.FirstName, student.LastName, student.Username, student.StudentID, timeStr, statusText, event.Location)
-
-	footer := `<p class="muted">This is an automated notification from the Attendance System.</p>`
-
-	htmlBody := BuildHTMLEmail("Student check-out", "Student Check-Out Notification", content, footer)
-
-	// Send email to all admins
-	for _, admin := range admins {
-		if admin.Email != "" {
-			SendEmail(admin.Email, fmt.Sprintf("Check-Out: %s %s - %s", student.FirstName, student.LastName, event.Title), htmlBody)
-		}
+// GetAttendanceByEvent retrieves all attendance records for an event the
+// viewer may see (its owner, staff and admins over it)
+func GetAttendanceByEvent(eventID uint, viewer models.User) ([]models.Attendance, error) {
+	var event models.Event
+	if err := connection.DB.First(&event, eventID).Error; err != nil {
+		return nil, errors.New(errEventNotFound)
+	}
+	if !CanViewEventAttendance(viewer, &event) {
+		return nil, ErrForbidden
 	}
-}
 
-// GetAttendanceByEvent retrieves all attendance records for an event
-func GetAttendanceByEvent(eventID uint) ([]models.Attendance, error) {
 	var attendances []models.Attendance
 	if err := connection.DB.Preload("Student").Preload("Event").
 		Where("event_id = ?", eventID).
@@ -451,6 +452,9 @@ func GetAttendanceByStudent(studentID string, filters map[string]interface{}) ([
 	if endDate, ok := filters["end_date"].(string); ok && endDate != "" {
 		query = query.Where("created_at <= ?", endDate)
 	}
+	if termID, ok := filters["term_id"].(uint); ok && termID > 0 {
+		query = query.Where("event_id IN (?)", connection.DB.Model(&models.Event{}).Select("id").Where("term_id = ?", termID))
+	}
 
 	if err := query.Order("marked_at DESC").Find(&attendances).Error; err != nil {
 		return nil, fmt.Errorf("failed to fetch attendance: %v", err)
@@ -458,8 +462,14 @@ func GetAttendanceByStudent(studentID string, filters map[string]interface{}) ([
 	return attendances, nil
 }
 
-// GetAttendanceStats calculates attendance statistics
-func GetAttendanceStats(studentID string, eventID *uint) (*models.AttendanceStats, error) {
+// GetAttendanceStats calculates a student's attendance statistics, optionally
+// limited to one event and/or academic term. Viewers other than the student
+// need access to the student under the row-level policy.
+func GetAttendanceStats(viewer models.User, studentID string, eventID *uint, termID *uint) (*models.AttendanceStats, error) {
+	if studentID != viewer.StudentID && !CanViewStudent(viewer, studentID) {
+		return nil, ErrForbidden
+	}
+
 	query := connection.DB.Model(&models.Attendance{})
 
 	if eventID != nil {
@@ -470,6 +480,13 @@ func GetAttendanceStats(studentID string, eventID *uint) (*models.AttendanceStat
 		query = query.Where(StudentWhere, studentID)
 	}
 
+	if termID != nil {
+		query = query.Where("event_id IN (?)", connection.DB.Model(&models.Event{}).Select("id").Where("term_id = ?", *termID))
+	}
+
+	// Each count starts from the shared filters
+	query = query.Session(&gorm.Session{})
+
 	var total int64
 	var presentCount, absentCount, lateCount, excusedCount int64
 
@@ -504,14 +521,21 @@ func UpdateAttendanceStatus(attendanceID uint, status, notes string, updatedBy s
 		return nil, errors.New("attendance record not found")
 	}
 
-	// Check permissions
+	// Check permissions: event editors with attendance.override
 	var user models.User
 	if err := connection.DB.Where(StudentWhere, updatedBy).First(&user).Error; err != nil {
 		return nil, errors.New("unauthorized")
 	}
 
-	if user.Role != "superadmin" && user.Role != "admin" && user.Role != "faculty" {
-		return nil, errors.New("unauthorized: only admin or faculty can update attendance")
+	var event models.Event
+	if err := connection.DB.First(&event, attendance.EventID).Error; err != nil {
+		return nil, errors.New("event not found")
+	}
+	if !canOverrideAttendance(&event, user) {
+		return nil, errors.New("unauthorized: updating attendance requires event edit access and the attendance.override permission")
+	}
+	if err := ensureEventTermOpen(event); err != nil {
+		return nil, err
 	}
 
 	// Update status
@@ -0,0 +1,455 @@
+// services/audit_chain_service.go
+package services
+
+import (
+	"attendance-system/connection"
+	"attendance-system/logging"
+	"attendance-system/models"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// auditChainLock is the Postgres advisory lock that serializes appends to the
+// audit chain, across goroutines and app instances
+const auditChainLock = 7_410_150
+
+// auditChainBatch is how many entries are read at a time while walking the chain
+const auditChainBatch = 1000
+
+// ErrNoAuditSigningKey is returned when checkpoints or redactions cannot be signed
+var ErrNoAuditSigningKey = errors.New("AUDIT_SIGNING_KEY (or JWT_SECRET) is not configured")
+
+// auditSigningKey is the HMAC key for checkpoints and redactions
+// (AUDIT_SIGNING_KEY, falling back to JWT_SECRET)
+func auditSigningKey() ([]byte, error) {
+	key := os.Getenv("AUDIT_SIGNING_KEY")
+	if key == "" {
+		key = os.Getenv("JWT_SECRET")
+	}
+	if key == "" {
+		return nil, ErrNoAuditSigningKey
+	}
+	return []byte(key), nil
+}
+
+// lockAuditChain holds the chain lock until tx ends
+func lockAuditChain(tx *gorm.DB) error {
+	return tx.Exec("SELECT pg_advisory_xact_lock(?)", auditChainLock).Error
+}
+
+// auditEntryHash hashes an entry's content together with the previous hash.
+// Fields are JSON encoded so no two entries share an encoding.
+func auditEntryHash(prevHash string, l AuditLog) string {
+	content, _ := json.Marshal([]string{
+		prevHash, l.Action, l.ActorID, l.TargetID, l.Details, l.IPAddress, l.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	return sha256Hex(content)
+}
+
+// redactionSignature binds an entry's redacted content to its original chain hashes
+func redactionSignature(key []byte, l AuditLog) string {
+	content, _ := json.Marshal([]string{
+		"redaction", strconv.FormatUint(uint64(l.ID), 10), l.PrevHash, l.Hash,
+		l.Action, l.ActorID, l.TargetID, l.Details, l.IPAddress, l.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	return hex.EncodeToString(hmacSHA256(key, string(content)))
+}
+
+// checkpointSignature signs what a checkpoint vouches for
+func checkpointSignature(key []byte, cp models.AuditCheckpoint) string {
+	return hex.EncodeToString(hmacSHA256(key, fmt.Sprintf("checkpoint\n%d\n%s\n%d", cp.LastEntryID, cp.LastHash, cp.PrunedCount)))
+}
+
+// headSignature signs the position of the newest entry
+func headSignature(key []byte, id uint, hash string) string {
+	return hex.EncodeToString(hmacSHA256(key, fmt.Sprintf("head\n%d\n%s", id, hash)))
+}
+
+// signedAuditChainHead loads the signed chain head, or nil before the log was anchored
+func signedAuditChainHead(db *gorm.DB) (*models.AuditChainHead, error) {
+	var heads []models.AuditChainHead
+	if err := db.Where("id = ?", 1).Limit(1).Find(&heads).Error; err != nil {
+		return nil, err
+	}
+	if len(heads) == 0 {
+		return nil, nil
+	}
+	return &heads[0], nil
+}
+
+// auditLogSequenceUsed reports whether an audit entry was ever inserted, even
+// if every row has since been deleted
+func auditLogSequenceUsed(db *gorm.DB) (bool, error) {
+	var used bool
+	err := db.Raw("SELECT pg_sequence_last_value(pg_get_serial_sequence('audit_logs', 'id')::regclass) IS NOT NULL").Scan(&used).Error
+	return used, err
+}
+
+// latestAuditCheckpoint returns the newest checkpoint, or nil when nothing was pruned
+func latestAuditCheckpoint(db *gorm.DB) (*models.AuditCheckpoint, error) {
+	var cps []models.AuditCheckpoint
+	if err := db.Order("last_entry_id DESC").Limit(1).Find(&cps).Error; err != nil {
+		return nil, err
+	}
+	if len(cps) == 0 {
+		return nil, nil
+	}
+	return &cps[0], nil
+}
+
+// auditChainEnd returns the ID and hash of the newest entry, or the latest
+// checkpoint's when every entry was pruned
+func auditChainEnd(tx *gorm.DB) (uint, string, error) {
+	var last []AuditLog
+	if err := tx.Select("id", "hash").Order("id DESC").Limit(1).Find(&last).Error; err != nil {
+		return 0, "", err
+	}
+	if len(last) > 0 {
+		return last[0].ID, last[0].Hash, nil
+	}
+	cp, err := latestAuditCheckpoint(tx)
+	if err != nil || cp == nil {
+		return 0, "", err
+	}
+	return cp.LastEntryID, cp.LastHash, nil
+}
+
+// appendAuditEntry links entry to the chain head and inserts it. A new entry
+// links to the signed head rather than the newest row, so deleted newest
+// entries leave a visible gap, and the head moves to the new entry.
+func appendAuditEntry(entry *AuditLog) error {
+	return connection.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockAuditChain(tx); err != nil {
+			return err
+		}
+		head, err := signedAuditChainHead(tx)
+		if err != nil {
+			return err
+		}
+		// Without a key the head cannot move, so it is left behind
+		key, keyErr := auditSigningKey()
+		if keyErr != nil {
+			head = nil
+		}
+		var prev string
+		if head != nil {
+			prev = head.LastHash
+		} else if _, prev, err = auditChainEnd(tx); err != nil {
+			return err
+		}
+		// Postgres keeps microseconds; hash exactly what will be read back
+		entry.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+		entry.PrevHash = prev
+		entry.Hash = auditEntryHash(prev, *entry)
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+
+		// Only SealAuditLog creates the head; a missing one stays missing
+		// so verification reports it
+		if head == nil {
+			return nil
+		}
+		return tx.Model(&models.AuditChainHead{}).Where("id = ?", head.ID).Updates(map[string]interface{}{
+			"last_entry_id": entry.ID,
+			"last_hash":     entry.Hash,
+			"signature":     headSignature(key, entry.ID, entry.Hash),
+			"updated_at":    time.Now(),
+		}).Error
+	})
+}
+
+// SealAuditLog chains the entries written before hash chaining existed and
+// creates the signed head at the end of them. It only runs while no entry is
+// sealed yet, so unsealed rows inserted later, and a head deleted later, are
+// reported by verification instead of being silently accepted.
+func SealAuditLog() {
+	key, err := auditSigningKey()
+	if err != nil {
+		logging.Logger.Error("Failed to seal audit log", zap.Error(err))
+		return
+	}
+	sealed := 0
+	err = connection.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockAuditChain(tx); err != nil {
+			return err
+		}
+		var chained, checkpoints int64
+		if err := tx.Model(&AuditLog{}).Where("hash <> ''").Count(&chained).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.AuditCheckpoint{}).Count(&checkpoints).Error; err != nil {
+			return err
+		}
+		head, err := signedAuditChainHead(tx)
+		if err != nil {
+			return err
+		}
+		if chained > 0 || checkpoints > 0 || head != nil {
+			return nil
+		}
+
+		prev := ""
+		var cursor uint
+		for {
+			var logs []AuditLog
+			if err := tx.Where("id > ?", cursor).Order("id ASC").Limit(auditChainBatch).Find(&logs).Error; err != nil {
+				return err
+			}
+			for _, l := range logs {
+				hash := auditEntryHash(prev, l)
+				if err := tx.Model(&AuditLog{}).Where("id = ?", l.ID).
+					Updates(map[string]interface{}{"prev_hash": prev, "hash": hash}).Error; err != nil {
+					return err
+				}
+				prev, cursor = hash, l.ID
+				sealed++
+			}
+			if len(logs) < auditChainBatch {
+				break
+			}
+		}
+
+		// An empty log whose IDs were already handed out was wiped; anchoring
+		// it would make that look like a fresh install
+		if sealed == 0 {
+			used, err := auditLogSequenceUsed(tx)
+			if err != nil {
+				return err
+			}
+			if used {
+				return errors.New("the audit log is empty but entries were written before; not anchoring it")
+			}
+		}
+		return tx.Create(&models.AuditChainHead{
+			ID:          1,
+			LastEntryID: cursor,
+			LastHash:    prev,
+			Signature:   headSignature(key, cursor, prev),
+		}).Error
+	})
+	if err != nil {
+		logging.Logger.Error("Failed to seal audit log", zap.Error(err))
+	} else if sealed > 0 {
+		logging.Logger.Info("Existing audit log entries sealed", zap.Int("entries", sealed))
+	}
+}
+
+// VerifyAuditChain walks the whole audit chain from the latest checkpoint to
+// the signed head and reports the first entry that was modified, deleted,
+// reordered or inserted outside the chain
+func VerifyAuditChain() (*models.AuditChainReport, error) {
+	return verifyAuditChain(connection.DB, 0)
+}
+
+// verifyAuditChain walks the chain up to entry upTo (0 for all of it, which
+// must reach the signed head)
+func verifyAuditChain(db *gorm.DB, upTo uint) (*models.AuditChainReport, error) {
+	report := &models.AuditChainReport{Valid: true, VerifiedAt: time.Now()}
+	fail := func(id uint, reason string) (*models.AuditChainReport, error) {
+		report.Valid, report.BreakID, report.BreakReason = false, id, reason
+		return report, nil
+	}
+
+	// The head is read first: entries appended while walking only extend past it
+	var head *models.AuditChainHead
+	if upTo == 0 {
+		var err error
+		if head, err = signedAuditChainHead(db); err != nil {
+			return nil, fmt.Errorf("failed to load audit chain head: %v", err)
+		}
+		if head == nil {
+			used, err := auditLogSequenceUsed(db)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read audit log sequence: %v", err)
+			}
+			if used {
+				return fail(0, "the signed chain head is missing (entries or the whole log were deleted)")
+			}
+		} else {
+			key, err := auditSigningKey()
+			if err != nil {
+				return nil, err
+			}
+			report.SignedHead = head
+			if headSignature(key, head.LastEntryID, head.LastHash) != head.Signature {
+				return fail(0, "the signed chain head has an invalid signature")
+			}
+		}
+	}
+
+	cp, err := latestAuditCheckpoint(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load audit checkpoint: %v", err)
+	}
+	prev, prevLabel := "", "the start of the log"
+	var cursor uint
+	if cp != nil {
+		key, err := auditSigningKey()
+		if err != nil {
+			return nil, err
+		}
+		report.Checkpoint = cp
+		if checkpointSignature(key, *cp) != cp.Signature {
+			return fail(0, fmt.Sprintf("checkpoint %d has an invalid signature", cp.ID))
+		}
+		prev, prevLabel, cursor = cp.LastHash, fmt.Sprintf("checkpoint %d", cp.ID), cp.LastEntryID
+	}
+	report.HeadID, report.HeadHash = cursor, prev
+
+	// reachedHead: the walk passed the signed head (or it lies in pruned history)
+	reachedHead := head == nil || head.LastEntryID == 0
+	if !reachedHead && cp != nil && head.LastEntryID <= cp.LastEntryID {
+		if head.LastEntryID == cp.LastEntryID && head.LastHash != cp.LastHash {
+			return fail(0, fmt.Sprintf("checkpoint %d does not match the signed chain head", cp.ID))
+		}
+		reachedHead = true
+	}
+
+	var key []byte
+	for {
+		query := db.Where("id > ?", cursor)
+		if upTo > 0 {
+			query = query.Where("id <= ?", upTo)
+		}
+		var logs []AuditLog
+		if err := query.Order("id ASC").Limit(auditChainBatch).Find(&logs).Error; err != nil {
+			return nil, fmt.Errorf("failed to read audit logs: %v", err)
+		}
+		for _, l := range logs {
+			if l.Hash == "" {
+				return fail(l.ID, "entry is not part of the chain (inserted without a hash)")
+			}
+			if l.PrevHash != prev {
+				return fail(l.ID, "entry does not link to "+prevLabel+" (an entry was deleted, inserted or reordered)")
+			}
+			if auditEntryHash(l.PrevHash, l) != l.Hash {
+				if l.RedactionSig == "" {
+					return fail(l.ID, "entry content does not match its hash (entry was modified)")
+				}
+				if key == nil {
+					if key, err = auditSigningKey(); err != nil {
+						return nil, err
+					}
+				}
+				if redactionSignature(key, l) != l.RedactionSig {
+					return fail(l.ID, "redacted entry has an invalid signature (entry was modified)")
+				}
+				report.Redacted++
+			}
+			if !reachedHead && l.ID >= head.LastEntryID {
+				if l.ID != head.LastEntryID || l.Hash != head.LastHash {
+					return fail(l.ID, fmt.Sprintf("entry %d, the signed chain head, is missing or was replaced", head.LastEntryID))
+				}
+				reachedHead = true
+			}
+			report.Checked++
+			report.HeadID, report.HeadHash = l.ID, l.Hash
+			prev, prevLabel, cursor = l.Hash, fmt.Sprintf("entry %d", l.ID), l.ID
+		}
+		if len(logs) < auditChainBatch {
+			if !reachedHead {
+				return fail(report.HeadID, fmt.Sprintf("the log ends before entry %d, the signed chain head (the newest entries were deleted)", head.LastEntryID))
+			}
+			return report, nil
+		}
+	}
+}
+
+// PruneAuditLogs deletes the entries created before cutoff and records a
+// signed checkpoint of the last one, so the remaining chain still verifies.
+// Nothing is deleted while the chain up to that entry is broken.
+func PruneAuditLogs(cutoff time.Time) (int64, *models.AuditCheckpoint, error) {
+	key, err := auditSigningKey()
+	if err != nil {
+		return 0, nil, err
+	}
+	var checkpoint *models.AuditCheckpoint
+	err = connection.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockAuditChain(tx); err != nil {
+			return err
+		}
+		var last []AuditLog
+		if err := tx.Where("created_at < ?", cutoff).Order("id DESC").Limit(1).Find(&last).Error; err != nil {
+			return err
+		}
+		if len(last) == 0 {
+			return nil
+		}
+
+		// Signing over a broken chain would hide the tampering for good
+		report, err := verifyAuditChain(tx, last[0].ID)
+		if err != nil {
+			return err
+		}
+		if !report.Valid {
+			return fmt.Errorf("audit chain is broken at entry %d (%s); nothing was pruned", report.BreakID, report.BreakReason)
+		}
+
+		result := tx.Where("id <= ?", last[0].ID).Delete(&AuditLog{})
+		if result.Error != nil {
+			return result.Error
+		}
+		cp := models.AuditCheckpoint{
+			LastEntryID: last[0].ID,
+			LastHash:    last[0].Hash,
+			LastEntryAt: last[0].CreatedAt,
+			PrunedCount: result.RowsAffected,
+		}
+		cp.Signature = checkpointSignature(key, cp)
+		if err := tx.Create(&cp).Error; err != nil {
+			return err
+		}
+		checkpoint = &cp
+		return nil
+	})
+	if err != nil || checkpoint == nil {
+		return 0, nil, err
+	}
+	return checkpoint.PrunedCount, checkpoint, nil
+}
+
+// redactAuditEntries removes a person from the audit log during erasure:
+// entries about them lose their details and entries by them lose their IP.
+// Each changed entry is signed so the chain still verifies.
+func redactAuditEntries(tx *gorm.DB, ids []string, pseudonym string) error {
+	key, err := auditSigningKey()
+	if err != nil {
+		return err
+	}
+	var logs []AuditLog
+	if err := tx.Where("target_id IN ? OR actor_id IN ?", ids, ids).Find(&logs).Error; err != nil {
+		return err
+	}
+	matches := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		matches[id] = true
+	}
+	for _, l := range logs {
+		if matches[l.TargetID] {
+			l.TargetID, l.Details = pseudonym, ""
+		}
+		if matches[l.ActorID] {
+			l.ActorID, l.IPAddress = pseudonym, ""
+		}
+		l.RedactionSig = redactionSignature(key, l)
+		if err := tx.Model(&AuditLog{}).Where("id = ?", l.ID).Updates(map[string]interface{}{
+			"target_id":     l.TargetID,
+			"details":       l.Details,
+			"actor_id":      l.ActorID,
+			"ip_address":    l.IPAddress,
+			"redaction_sig": l.RedactionSig,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
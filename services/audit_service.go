@@ -4,7 +4,13 @@ package services
 import (
 	"attendance-system/connection"
 	"attendance-system/logging"
+	"attendance-system/models"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
+	"strconv"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -20,6 +26,10 @@ type AuditLog struct {
 	Details   string    `gorm:"type:text" json:"details"`                   // JSON details of action
 	IPAddress string    `gorm:"type:varchar(255)" json:"ip_address"`        // Request IP
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	// Hash chain (see audit_chain_service.go): Hash covers the fields above and PrevHash
+	PrevHash     string `gorm:"type:varchar(64);not null;default:''" json:"prev_hash"`
+	Hash         string `gorm:"type:varchar(64);not null;default:''" json:"hash"`
+	RedactionSig string `gorm:"type:varchar(64);not null;default:''" json:"redaction_sig,omitempty"` // set when erasure redacted the entry
 }
 
 // LogAuditAction logs an admin action for security audit trail
@@ -36,7 +46,7 @@ func LogAuditAction(action, actorID, targetID, details, ipAddress string) error
 		IPAddress: ipAddress,
 	}
 
-	if err := connection.DB.Create(&auditLog).Error; err != nil {
+	if err := appendAuditEntry(&auditLog); err != nil {
 		logging.Logger.Error("Failed to log audit action",
 			zap.String("action", action),
 			zap.String("actor_id", actorID),
@@ -55,14 +65,17 @@ func LogAuditAction(action, actorID, targetID, details, ipAddress string) error
 	return nil
 }
 
-// GetAuditLogs retrieves audit logs with filtering
-func GetAuditLogs(filters map[string]interface{}, limit int, offset int) ([]AuditLog, int64, error) {
-	var auditLogs []AuditLog
-	var total int64
-
-	query := connection.DB
+// auditLogQuery applies the audit log filters. Supported keys: action (one
+// action or a comma-separated list), actor_id, target_id, ip_address, q
+// (search in details), start_date and end_date (time.Time, end exclusive).
+func auditLogQuery(filters map[string]interface{}) *gorm.DB {
+	query := connection.DB.Model(&AuditLog{})
 	if action, ok := filters["action"].(string); ok && action != "" {
-		query = query.Where("action = ?", action)
+		actions := strings.Split(action, ",")
+		for i := range actions {
+			actions[i] = strings.ToUpper(strings.TrimSpace(actions[i]))
+		}
+		query = query.Where("action IN ?", actions)
 	}
 	if actorID, ok := filters["actor_id"].(string); ok && actorID != "" {
 		query = query.Where("actor_id = ?", actorID)
@@ -70,14 +83,29 @@ func GetAuditLogs(filters map[string]interface{}, limit int, offset int) ([]Audi
 	if targetID, ok := filters["target_id"].(string); ok && targetID != "" {
 		query = query.Where("target_id = ?", targetID)
 	}
+	if ip, ok := filters["ip_address"].(string); ok && ip != "" {
+		query = query.Where("ip_address = ?", ip)
+	}
+	if q, ok := filters["q"].(string); ok && strings.TrimSpace(q) != "" {
+		query = query.Where("details ILIKE ?", "%"+escapeLike(strings.TrimSpace(q))+"%")
+	}
 	if startDate, ok := filters["start_date"].(time.Time); ok && !startDate.IsZero() {
 		query = query.Where("created_at >= ?", startDate)
 	}
 	if endDate, ok := filters["end_date"].(time.Time); ok && !endDate.IsZero() {
-		query = query.Where("created_at <= ?", endDate)
+		query = query.Where("created_at < ?", endDate)
 	}
+	return query
+}
+
+// GetAuditLogs retrieves the audit logs the viewer may read with filtering:
+// entries by the viewer or, for department admins, by their department
+func GetAuditLogs(viewer models.User, filters map[string]interface{}, limit int, offset int) ([]AuditLog, int64, error) {
+	var auditLogs []AuditLog
+	var total int64
 
-	if err := query.Model(&AuditLog{}).Count(&total).Error; err != nil {
+	query := scopeOwnedQuery(auditLogQuery(filters), viewer, "actor_id")
+	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -88,13 +116,109 @@ func GetAuditLogs(filters map[string]interface{}, limit int, offset int) ([]Audi
 		limit = 500 // max limit
 	}
 
-	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&auditLogs).Error; err != nil {
+	if err := scopeOwnedQuery(auditLogQuery(filters), viewer, "actor_id").Order("id DESC").Limit(limit).Offset(offset).Find(&auditLogs).Error; err != nil {
 		return nil, 0, err
 	}
 
 	return auditLogs, total, nil
 }
 
+// ListAuditLogs returns up to limit entries matching filters using keyset
+// pagination on the entry ID. Newest first, entries with an ID below cursor
+// are returned; with ascending, oldest first above cursor. nextCursor is 0
+// on the last page.
+func ListAuditLogs(filters map[string]interface{}, cursor uint, limit int, ascending bool) (logs []AuditLog, nextCursor uint, err error) {
+	query := auditLogQuery(filters)
+	if ascending {
+		if cursor > 0 {
+			query = query.Where("id > ?", cursor)
+		}
+		query = query.Order("id ASC")
+	} else {
+		if cursor > 0 {
+			query = query.Where("id < ?", cursor)
+		}
+		query = query.Order("id DESC")
+	}
+	// One extra row tells whether another page exists
+	if err := query.Limit(limit + 1).Find(&logs).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch audit logs: %v", err)
+	}
+	if len(logs) > limit {
+		logs = logs[:limit]
+		nextCursor = logs[limit-1].ID
+	}
+	return logs, nextCursor, nil
+}
+
+// CountAuditLogs returns the number of entries matching filters
+func CountAuditLogs(filters map[string]interface{}) (int64, error) {
+	var total int64
+	err := auditLogQuery(filters).Count(&total).Error
+	return total, err
+}
+
+// auditCSVHeader is the column order of the audit log CSV export
+var auditCSVHeader = []string{"id", "created_at", "action", "actor_id", "target_id", "ip_address", "details", "prev_hash", "hash"}
+
+// ExportAuditLogs writes every entry matching filters, newest first, as CSV
+// or JSON Lines ("csv" or "jsonl") and returns the number of entries written.
+// The export itself is audited.
+func ExportAuditLogs(w io.Writer, format string, filters map[string]interface{}, actor, ipAddress string) (int, error) {
+	var write func(AuditLog) error
+	var flush func() error
+	switch format {
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write(auditCSVHeader); err != nil {
+			return 0, err
+		}
+		write = func(l AuditLog) error {
+			return cw.Write([]string{
+				strconv.FormatUint(uint64(l.ID), 10), l.CreatedAt.UTC().Format(time.RFC3339Nano),
+				csvSafe(l.Action), csvSafe(l.ActorID), csvSafe(l.TargetID), csvSafe(l.IPAddress), csvSafe(l.Details),
+				l.PrevHash, l.Hash,
+			})
+		}
+		flush = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+	case "jsonl":
+		enc := json.NewEncoder(w)
+		write = func(l AuditLog) error { return enc.Encode(l) }
+		flush = func() error { return nil }
+	default:
+		return 0, fmt.Errorf("invalid format %q. Use csv or jsonl", format)
+	}
+
+	const batch = 1000
+	rows := 0
+	var cursor uint
+	for {
+		logs, next, err := ListAuditLogs(filters, cursor, batch, false)
+		if err != nil {
+			return rows, err
+		}
+		for _, l := range logs {
+			if err := write(l); err != nil {
+				return rows, err
+			}
+			rows++
+		}
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+	if err := flush(); err != nil {
+		return rows, err
+	}
+
+	go LogAuditAction(AuditLogExported, actor, "audit_logs", fmt.Sprintf("%d entries exported as %s", rows, format), ipAddress)
+	return rows, nil
+}
+
 // Audit action constants
 const (
 	AuditUserPromoted       = "USER_PROMOTED"
@@ -103,11 +227,46 @@ const (
 	AuditEventCreated       = "EVENT_CREATED"
 	AuditEventUpdated       = "EVENT_UPDATED"
 	AuditEventDeleted       = "EVENT_DELETED"
+	AuditEventStatusChanged = "EVENT_STATUS_CHANGED"
+	AuditEventStaffAssigned = "EVENT_STAFF_ASSIGNED"
+	AuditEventStaffRemoved  = "EVENT_STAFF_REMOVED"
 	AuditUserVerified       = "USER_VERIFIED"
 	AuditUserRegistered     = "USER_REGISTERED"
 	AuditAttendanceMarked   = "ATTENDANCE_MARKED"
 	AuditAttendanceUpdated  = "ATTENDANCE_UPDATED"
 	AuditAdminAccessAttempt = "ADMIN_ACCESS_ATTEMPT"
+	AuditTermClosed         = "TERM_CLOSED"
+	AuditTermReopened       = "TERM_REOPENED"
+	AuditEnrollmentChanged  = "ENROLLMENT_CHANGED"
+	AuditRoleCreated        = "ROLE_CREATED"
+	AuditRoleDeleted        = "ROLE_DELETED"
+	AuditRolePermissions    = "ROLE_PERMISSIONS_CHANGED"
+	AuditRefreshTokenReuse  = "REFRESH_TOKEN_REUSE"
+	AuditSessionsRevoked    = "SESSIONS_REVOKED"
+	AuditSvcAccountCreated  = "SERVICE_ACCOUNT_CREATED"
+	AuditSvcAccountDisabled = "SERVICE_ACCOUNT_DISABLED"
+	AuditAPIKeyCreated      = "API_KEY_CREATED"
+	AuditAPIKeyRevoked      = "API_KEY_REVOKED"
+	AuditTwoFactorEnabled   = "TWO_FACTOR_ENABLED"
+	AuditTwoFactorDisabled  = "TWO_FACTOR_DISABLED"
+	AuditRecoveryCodesReset = "RECOVERY_CODES_REGENERATED"
+	AuditAccountLocked      = "ACCOUNT_LOCKED"
+	AuditAccountUnlocked    = "ACCOUNT_UNLOCKED"
+	AuditProfileUpdated     = "PROFILE_UPDATED"
+	AuditProfileLocksSet    = "PROFILE_LOCKS_CHANGED"
+	AuditEmailChanged       = "EMAIL_CHANGED"
+	AuditPhotoUpdated       = "PROFILE_PICTURE_UPDATED"
+	AuditPhotoRemoved       = "PROFILE_PICTURE_REMOVED"
+	AuditAccountStatus      = "ACCOUNT_STATUS_CHANGED"
+	AuditUserDeleted        = "USER_DELETED"
+	AuditUserRestored       = "USER_RESTORED"
+	AuditDataExported       = "PERSONAL_DATA_EXPORTED"
+	AuditErasureRequested   = "ERASURE_REQUESTED"
+	AuditErasureCancelled   = "ERASURE_CANCELLED"
+	AuditErasureReviewed    = "ERASURE_REVIEWED"
+	AuditUserErased         = "USER_ERASED"
+	AuditUsersExported      = "USER_DIRECTORY_EXPORTED"
+	AuditLogExported        = "AUDIT_LOG_EXPORTED"
 )
 
 // TableName ensures the audit_logs table is used in queries
@@ -120,18 +279,24 @@ func MigrateAuditLog() error {
 	return connection.DB.AutoMigrate(&AuditLog{})
 }
 
-// DeleteOldAuditLogs removes audit logs older than specified duration
+// DeleteOldAuditLogs removes audit logs older than specified duration,
+// leaving a signed checkpoint so the remaining hash chain still verifies.
 // This should be run periodically (e.g., monthly via cron job)
 func DeleteOldAuditLogs(olderThanDays int) error {
 	cutoffDate := time.Now().AddDate(0, 0, -olderThanDays)
-	result := connection.DB.Where("created_at < ?", cutoffDate).Delete(&AuditLog{})
-	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
-		return result.Error
+	pruned, checkpoint, err := PruneAuditLogs(cutoffDate)
+	if err != nil {
+		return err
+	}
+	if checkpoint == nil {
+		return nil
 	}
 
 	logging.Logger.Info("Old audit logs deleted",
-		zap.Int64("rows_affected", result.RowsAffected),
+		zap.Int64("rows_affected", pruned),
 		zap.Time("cutoff_date", cutoffDate),
+		zap.Uint("checkpoint_id", checkpoint.ID),
+		zap.Uint("last_entry_id", checkpoint.LastEntryID),
 	)
 
 	return nil
@@ -0,0 +1,416 @@
+// services/data_privacy_service.go
+package services
+
+import (
+	"archive/zip"
+	"attendance-system/connection"
+	"attendance-system/logging"
+	"attendance-system/models"
+	"attendance-system/utils"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrNoErasureRequest is returned when there is no matching erasure request
+var ErrNoErasureRequest = errors.New("erasure request not found")
+
+// erasedEmailDomain is a reserved domain, so erased accounts can never receive mail
+const erasedEmailDomain = "erased.invalid"
+
+const exportReadme = `This archive contains the personal data the attendance system holds about you.
+
+profile.json            your profile and account state
+attendance.json         every attendance record, with the event it belongs to
+excused_attendance.json attendance records marked excused (the system keeps
+                        excuses as attendance records with status "excused"
+                        and their notes)
+enrollments.json        class offerings you are enrolled in
+event_staff.json        events you were assigned to as staff
+reminder_opt_outs.json  events you muted reminders for
+sessions.json           your login sessions
+audit_log.json          audit log entries about your account
+profile_picture.jpg     your profile photo, if you set one
+`
+
+// exportedEvent is the part of an event included with each attendance record
+type exportedEvent struct {
+	ID        uint      `json:"id"`
+	Title     string    `json:"title"`
+	EventDate time.Time `json:"event_date"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Location  string    `json:"location"`
+	Course    string    `json:"course,omitempty"`
+	Section   string    `json:"section,omitempty"`
+	Status    string    `json:"status"`
+}
+
+type exportedAttendance struct {
+	ID             uint          `json:"id"`
+	Status         string        `json:"status"`
+	MarkedAt       time.Time     `json:"marked_at"`
+	MarkedBy       string        `json:"marked_by"`
+	Method         string        `json:"method"`
+	Latitude       float64       `json:"latitude,omitempty"`
+	Longitude      float64       `json:"longitude,omitempty"`
+	Notes          string        `json:"notes,omitempty"`
+	CheckInTime    *time.Time    `json:"check_in_time,omitempty"`
+	CheckOutTime   *time.Time    `json:"check_out_time,omitempty"`
+	CheckInStatus  string        `json:"check_in_status,omitempty"`
+	CheckOutStatus string        `json:"check_out_status,omitempty"`
+	Event          exportedEvent `json:"event"`
+}
+
+// ExportPersonalData builds a ZIP archive of everything stored about user
+func ExportPersonalData(user models.User, ipAddress string) ([]byte, error) {
+	var attendances []models.Attendance
+	if err := connection.DB.Preload("Event").Where(studentWhere, user.StudentID).Order("marked_at").Find(&attendances).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch attendance: %v", err)
+	}
+	records := make([]exportedAttendance, 0, len(attendances))
+	excused := []exportedAttendance{}
+	for _, a := range attendances {
+		r := exportedAttendance{
+			ID: a.ID, Status: a.Status, MarkedAt: a.MarkedAt, MarkedBy: a.MarkedBy, Method: a.Method,
+			Latitude: a.Latitude, Longitude: a.Longitude, Notes: a.Notes,
+			CheckInTime: a.CheckInTime, CheckOutTime: a.CheckOutTime,
+			CheckInStatus: a.CheckInStatus, CheckOutStatus: a.CheckOutStatus,
+			Event: exportedEvent{
+				ID: a.Event.ID, Title: a.Event.Title, EventDate: a.Event.EventDate,
+				StartTime: a.Event.StartTime, EndTime: a.Event.EndTime, Location: a.Event.Location,
+				Course: a.Event.Course, Section: a.Event.Section, Status: a.Event.Status,
+			},
+		}
+		records = append(records, r)
+		if a.Status == models.AttendanceStatusExcused {
+			excused = append(excused, r)
+		}
+	}
+
+	var enrollments []models.Enrollment
+	var staff []models.EventStaff
+	var optOuts []models.EventReminderOptOut
+	var sessions []models.AuthSession
+	var auditLogs []AuditLog
+	for _, q := range []struct {
+		out   interface{}
+		query *gorm.DB
+	}{
+		{&enrollments, connection.DB.Where(studentWhere, user.StudentID)},
+		{&staff, connection.DB.Where(studentWhere, user.StudentID)},
+		{&optOuts, connection.DB.Where(studentWhere, user.StudentID)},
+		{&sessions, connection.DB.Where(studentWhere, user.StudentID).Order("created_at")},
+		{&auditLogs, connection.DB.Where("target_id IN ?", personalIdentifiers(user)).Order("created_at")},
+	} {
+		if err := q.query.Find(q.out).Error; err != nil {
+			return nil, fmt.Errorf("failed to collect personal data: %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := []struct {
+		name string
+		v    interface{}
+	}{
+		{"profile.json", GetProfile(user)},
+		{"attendance.json", records},
+		{"excused_attendance.json", excused},
+		{"enrollments.json", enrollments},
+		{"event_staff.json", staff},
+		{"reminder_opt_outs.json", optOuts},
+		{"sessions.json", sessions},
+		{"audit_log.json", auditLogs},
+	}
+	if err := writeZipFile(zw, "README.txt", []byte(exportReadme)); err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		data, err := json.MarshalIndent(f.v, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %v", f.name, err)
+		}
+		if err := writeZipFile(zw, f.name, data); err != nil {
+			return nil, err
+		}
+	}
+	if strings.HasPrefix(user.ProfilePicture, profilePicturePrefix) {
+		data, _, err := GetFileStorage().Get(profilePictureKey(user.ProfilePicture, ProfilePictureSize))
+		if err == nil {
+			err = writeZipFile(zw, "profile_picture.jpg", data)
+		}
+		if err != nil {
+			logging.Logger.Warn("Profile picture left out of data export", zap.String("student_id", user.StudentID), zap.Error(err))
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build archive: %v", err)
+	}
+
+	go LogAuditAction(AuditDataExported, user.StudentID, user.StudentID, fmt.Sprintf("%d attendance records", len(records)), ipAddress)
+	return buf.Bytes(), nil
+}
+
+func writeZipFile(zw *zip.Writer, name string, data []byte) error {
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %v", name, err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to add %s: %v", name, err)
+	}
+	return nil
+}
+
+// personalIdentifiers are the forms of a user's identity that appear in other
+// tables (lockout counters and their audit entries use lowercased keys)
+func personalIdentifiers(user models.User) []string {
+	ids := []string{user.StudentID}
+	for _, id := range []string{lockoutKey(user.StudentID), lockoutKey(user.Email)} {
+		if id != "" && id != user.StudentID {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// ---------------- Erasure requests ----------------
+
+// openErasureStatuses are the states in which a request still awaits processing
+var openErasureStatuses = []string{models.ErasurePending, models.ErasureApproved}
+
+// FileErasureRequest records a request to erase studentID's personal data,
+// filed by the user themself or by an administrator on their behalf
+func FileErasureRequest(studentID, reason, requestedBy, ipAddress string) (*models.ErasureRequest, error) {
+	var user models.User
+	if err := GetUserByStudentID(studentID, &user); err != nil {
+		return nil, errors.New(models.ErrUserNotFound)
+	}
+	if user.IsServiceAccount || user.Role == models.RoleSuperAdmin {
+		return nil, errors.New("this account cannot be erased")
+	}
+	reason = strings.TrimSpace(reason)
+	if len(reason) > 1000 {
+		return nil, errors.New("reason must be at most 1000 characters")
+	}
+
+	var count int64
+	connection.DB.Model(&models.ErasureRequest{}).Where("student_id = ? AND status IN ?", studentID, openErasureStatuses).Count(&count)
+	if count > 0 {
+		return nil, errors.New("an erasure request for this account is already open")
+	}
+
+	req := models.ErasureRequest{
+		StudentID:   studentID,
+		Status:      models.ErasurePending,
+		Reason:      reason,
+		RequestedBy: requestedBy,
+	}
+	if err := connection.DB.Omit("id").Create(&req).Error; err != nil {
+		return nil, fmt.Errorf("failed to create erasure request: %v", err)
+	}
+
+	go LogAuditAction(AuditErasureRequested, requestedBy, studentID, fmt.Sprintf("request #%d", req.ID), ipAddress)
+	return &req, nil
+}
+
+// GetOpenErasureRequest returns the user's pending or approved request
+func GetOpenErasureRequest(studentID string) (*models.ErasureRequest, error) {
+	var req models.ErasureRequest
+	if err := connection.DB.Where("student_id = ? AND status IN ?", studentID, openErasureStatuses).First(&req).Error; err != nil {
+		return nil, ErrNoErasureRequest
+	}
+	return &req, nil
+}
+
+// CancelErasureRequest withdraws the user's open request
+func CancelErasureRequest(studentID, ipAddress string) error {
+	req, err := GetOpenErasureRequest(studentID)
+	if err != nil {
+		return err
+	}
+	if err := connection.DB.Model(req).Update("status", models.ErasureCancelled).Error; err != nil {
+		return fmt.Errorf("failed to cancel erasure request: %v", err)
+	}
+	go LogAuditAction(AuditErasureCancelled, studentID, studentID, fmt.Sprintf("request #%d", req.ID), ipAddress)
+	return nil
+}
+
+// ListErasureRequests returns erasure requests, optionally filtered by status
+func ListErasureRequests(status string) ([]models.ErasureRequest, error) {
+	var requests []models.ErasureRequest
+	query := connection.DB.Order("created_at DESC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Find(&requests).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch erasure requests: %v", err)
+	}
+	return requests, nil
+}
+
+// ReviewErasureRequest approves or rejects a pending request
+func ReviewErasureRequest(id uint, approve bool, note, actor, ipAddress string) (*models.ErasureRequest, error) {
+	var req models.ErasureRequest
+	if err := connection.DB.First(&req, id).Error; err != nil {
+		return nil, ErrNoErasureRequest
+	}
+	if req.Status != models.ErasurePending {
+		return nil, fmt.Errorf("request is %s, only pending requests can be reviewed", req.Status)
+	}
+	if req.StudentID == actor {
+		return nil, errors.New("you cannot review your own erasure request")
+	}
+
+	now := time.Now()
+	req.Status = models.ErasureRejected
+	if approve {
+		req.Status = models.ErasureApproved
+	}
+	req.ReviewedBy, req.ReviewNote, req.ReviewedAt = actor, strings.TrimSpace(note), &now
+	if err := connection.DB.Model(&req).Updates(map[string]interface{}{
+		"status":      req.Status,
+		"reviewed_by": req.ReviewedBy,
+		"review_note": req.ReviewNote,
+		"reviewed_at": now,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to review erasure request: %v", err)
+	}
+
+	go LogAuditAction(AuditErasureReviewed, actor, req.StudentID, fmt.Sprintf("request #%d %s", req.ID, req.Status), ipAddress)
+	return &req, nil
+}
+
+// ExecuteErasure anonymizes the user of an approved request. The caller must
+// repeat the student ID as confirmation. Attendance rows are kept under a
+// random pseudonym so counts and statistics stay correct; names, contact
+// details, notes, locations, sessions, 2FA, photos and other personal rows are
+// removed, and no link between the old ID and the pseudonym is stored.
+func ExecuteErasure(id uint, confirmStudentID, actor, ipAddress string) (*models.ErasureRequest, error) {
+	var req models.ErasureRequest
+	if err := connection.DB.First(&req, id).Error; err != nil {
+		return nil, ErrNoErasureRequest
+	}
+	if req.Status != models.ErasureApproved {
+		return nil, fmt.Errorf("request is %s, only approved requests can be executed", req.Status)
+	}
+	if strings.TrimSpace(confirmStudentID) != req.StudentID {
+		return nil, errors.New("confirm_student_id does not match the request")
+	}
+	if req.StudentID == actor {
+		return nil, errors.New("you cannot erase your own account")
+	}
+	var user models.User
+	if err := GetUserByStudentID(req.StudentID, &user); err != nil {
+		return nil, errors.New(models.ErrUserNotFound)
+	}
+
+	token, err := utils.GenerateRandomToken(8)
+	if err != nil {
+		return nil, err
+	}
+	pseudonym := "erased-" + token
+	now := time.Now()
+
+	err = connection.DB.Transaction(func(tx *gorm.DB) error {
+		return anonymizeUser(tx, user, pseudonym, now, map[string]interface{}{
+			"status":       models.ErasureCompleted,
+			"completed_by": actor,
+			"completed_at": now,
+		}, req.ID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to erase user: %v", err)
+	}
+	deleteProfilePictureFiles(user.ProfilePicture)
+
+	go LogAuditAction(AuditUserErased, actor, pseudonym, fmt.Sprintf("request #%d", req.ID), ipAddress)
+	req.StudentID, req.Status, req.CompletedBy, req.CompletedAt = pseudonym, models.ErasureCompleted, actor, &now
+	return &req, nil
+}
+
+// anonymizeUser moves the user's kept records to pseudonym and deletes the rest
+func anonymizeUser(tx *gorm.DB, user models.User, pseudonym string, now time.Time, completion map[string]interface{}, requestID uint) error {
+	old := user.StudentID
+	ids := personalIdentifiers(user)
+
+	// The placeholder keeps the fields attendance statistics group by
+	anon := models.User{
+		StudentID:       pseudonym,
+		Email:           pseudonym + "@" + erasedEmailDomain,
+		Password:        "!", // not a bcrypt hash, so no password ever matches
+		Username:        pseudonym,
+		Role:            user.Role,
+		FirstName:       "Erased",
+		LastName:        "User",
+		Course:          user.Course,
+		YearLevel:       user.YearLevel,
+		Department:      user.Department,
+		College:         user.College,
+		Status:          models.AccountDeactivated,
+		StatusReason:    "personal data erased",
+		StatusChangedAt: &now,
+		DeletedAt:       &now,
+		QRType:          models.QRTypeStudentID,
+	}
+	anon.CollegeID, anon.DepartmentID, anon.CourseID = user.CollegeID, user.DepartmentID, user.CourseID
+	if err := tx.Omit("id").Create(&anon).Error; err != nil {
+		return err
+	}
+
+	// Audit entries stay, but no longer name the person or keep their details and IPs
+	if err := redactAuditEntries(tx, ids, pseudonym); err != nil {
+		return err
+	}
+
+	// Each statement runs as the list is built; the first error rolls everything back
+	steps := []*gorm.DB{
+		tx.Model(&models.Attendance{}).Where(studentWhere, old).
+			Updates(map[string]interface{}{"student_id": pseudonym, "notes": "", "latitude": 0, "longitude": 0}),
+		tx.Model(&models.Attendance{}).Where("marked_by = ?", old).Update("marked_by", pseudonym),
+		tx.Model(&models.Enrollment{}).Where(studentWhere, old).Update("student_id", pseudonym),
+		tx.Model(&models.Enrollment{}).Where("enrolled_by = ?", old).Update("enrolled_by", pseudonym),
+		tx.Model(&models.Event{}).Where("created_by = ?", old).Update("created_by", pseudonym),
+		tx.Model(&models.EventTemplate{}).Where("owner_id = ?", old).Update("owner_id", pseudonym),
+		tx.Model(&models.ClassOffering{}).Where("faculty_id = ?", old).Update("faculty_id", pseudonym),
+		tx.Model(&models.EventStaff{}).Where("assigned_by = ?", old).Update("assigned_by", pseudonym),
+		tx.Model(&models.EventChange{}).Where("changed_by = ?", old).Update("changed_by", pseudonym),
+		tx.Model(&models.AcademicTerm{}).Where("closed_by = ?", old).Update("closed_by", pseudonym),
+		tx.Model(&models.ProfileFieldLock{}).Where("locked_by = ?", old).Update("locked_by", pseudonym),
+		tx.Model(&models.ServiceAccount{}).Where("created_by = ?", old).Update("created_by", pseudonym),
+		tx.Model(&models.APIKey{}).Where("created_by = ?", old).Update("created_by", pseudonym),
+		tx.Model(&models.JobRun{}).Where("triggered_by = ?", old).Update("triggered_by", pseudonym),
+		tx.Model(&models.ErasureRequest{}).Where("requested_by = ?", old).Update("requested_by", pseudonym),
+		tx.Model(&models.ErasureRequest{}).Where("reviewed_by = ?", old).Update("reviewed_by", pseudonym),
+		tx.Model(&models.ErasureRequest{}).Where("id = ?", requestID).Updates(completion),
+		tx.Model(&models.ErasureRequest{}).Where(studentWhere, old).Update("student_id", pseudonym),
+
+		tx.Where("session_id IN (?)", tx.Model(&models.AuthSession{}).Select("id").Where(studentWhere, old)).Delete(&models.RefreshToken{}),
+		tx.Where(studentWhere, old).Delete(&models.AuthSession{}),
+		tx.Where(studentWhere, old).Delete(&models.TwoFactor{}),
+		tx.Where(studentWhere, old).Delete(&models.RecoveryCode{}),
+		tx.Where(studentWhere, old).Delete(&models.PasswordHistory{}),
+		tx.Where(studentWhere, old).Delete(&models.EmailChange{}),
+		tx.Where(studentWhere, old).Delete(&models.EventStaff{}),
+		tx.Where(studentWhere, old).Delete(&models.EventReminder{}),
+		tx.Where(studentWhere, old).Delete(&models.EventReminderOptOut{}),
+		tx.Where(studentWhere, old).Delete(&models.EventChangeNotification{}),
+		tx.Where("identifier IN ?", ids).Delete(&models.AuthFailure{}),
+		tx.Where("LOWER(email) = ?", lockoutKey(user.Email)).Delete(&models.PasswordReset{}),
+		tx.Where("student_id = ? OR LOWER(email) = ?", old, lockoutKey(user.Email)).Delete(&models.PendingUser{}),
+		tx.Where(studentWhere, old).Delete(&models.User{}),
+	}
+	for _, step := range steps {
+		if step.Error != nil {
+			return step.Error
+		}
+	}
+	return nil
+}
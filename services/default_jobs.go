@@ -0,0 +1,145 @@
+// services/default_jobs.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// jobHistoryRetentionDays is how long job_runs rows are kept
+const jobHistoryRetentionDays = 30
+
+// RegisterDefaultJobs registers the built-in background jobs. Call once at
+// startup before StartJobScheduler.
+func RegisterDefaultJobs() {
+	RegisterJob(Job{
+		Name:        "event_status",
+		Description: "Mark ended events as completed and revert their QR codes",
+		Schedule:    "*/5 * * * *",
+		Run: func(ctx context.Context) (string, error) {
+			n, err := CheckAndUpdateCompletedEvents()
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("updated %d events", n), nil
+		},
+	})
+
+	RegisterJob(Job{
+		Name:        "event_reminders",
+		Description: "Email students about upcoming events",
+		Schedule:    "* * * * *",
+		Run: func(ctx context.Context) (string, error) {
+			n, err := SendDueEventReminders()
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("sent %d reminders", n), nil
+		},
+	})
+
+	RegisterJob(Job{
+		Name:        "event_change_notifications",
+		Description: "Email students about cancelled, postponed or moved events",
+		Schedule:    "* * * * *",
+		Run: func(ctx context.Context) (string, error) {
+			n, err := DeliverPendingEventChanges()
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("sent %d change notices", n), nil
+		},
+	})
+
+	RegisterJob(Job{
+		Name:        "audit_log_retention",
+		Description: "Delete audit logs older than AUDIT_LOG_RETENTION_DAYS, leaving a signed checkpoint",
+		Schedule:    "0 3 * * *",
+		Run: func(ctx context.Context) (string, error) {
+			days := auditLogRetentionDays()
+			if err := DeleteOldAuditLogs(days); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("deleted audit logs older than %d days", days), nil
+		},
+	})
+
+	RegisterJob(Job{
+		Name:        "pending_user_cleanup",
+		Description: "Delete unverified registrations and email changes whose code has expired",
+		Schedule:    "@hourly",
+		Run: func(ctx context.Context) (string, error) {
+			n, err := DeleteExpiredPendingUsers()
+			if err != nil {
+				return "", err
+			}
+			changes, err := DeleteExpiredEmailChanges()
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("deleted %d pending users, %d email changes", n, changes), nil
+		},
+	})
+
+	RegisterJob(Job{
+		Name:        "password_reset_cleanup",
+		Description: "Delete expired or used password reset codes",
+		Schedule:    "@hourly",
+		Run: func(ctx context.Context) (string, error) {
+			n, err := DeleteExpiredPasswordResets()
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("deleted %d password resets", n), nil
+		},
+	})
+
+	RegisterJob(Job{
+		Name:        "session_cleanup",
+		Description: "Delete expired refresh tokens and old login sessions",
+		Schedule:    "@hourly",
+		Run: func(ctx context.Context) (string, error) {
+			n, err := DeleteExpiredSessions()
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("deleted %d sessions", n), nil
+		},
+	})
+
+	RegisterJob(Job{
+		Name:        "auth_failure_cleanup",
+		Description: "Delete failed-attempt counters with no failures in the last 24 hours",
+		Schedule:    "@hourly",
+		Run: func(ctx context.Context) (string, error) {
+			n, err := DeleteStaleAuthFailures()
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("deleted %d counters", n), nil
+		},
+	})
+
+	RegisterJob(Job{
+		Name:        "job_history_cleanup",
+		Description: "Delete job run history older than 30 days",
+		Schedule:    "30 3 * * *",
+		Run: func(ctx context.Context) (string, error) {
+			n, err := DeleteOldJobRuns(jobHistoryRetentionDays)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("deleted %d job runs", n), nil
+		},
+	})
+}
+
+// auditLogRetentionDays reads AUDIT_LOG_RETENTION_DAYS (default 365)
+func auditLogRetentionDays() int {
+	if days, err := strconv.Atoi(os.Getenv("AUDIT_LOG_RETENTION_DAYS")); err == nil && days > 0 {
+		return days
+	}
+	return 365
+}
@@ -0,0 +1,179 @@
+// services/email_templates.go
+package services
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+)
+
+// Email template names (files under templates/email/<locale>/)
+const (
+	EmailTemplateVerification    = "verification"
+	EmailTemplatePasswordReset   = "password_reset"
+	EmailTemplatePasswordChanged = "password_changed"
+	EmailTemplateCheckIn         = "check_in"
+	EmailTemplateCheckOut        = "check_out"
+	EmailTemplateEventReminder   = "event_reminder"
+	EmailTemplateEventStatus     = "event_status_changed"
+	EmailTemplateEventChanged    = "event_changed"
+	EmailTemplateAccountLocked   = "account_locked"
+	EmailTemplateEmailChange     = "email_change"
+	EmailTemplateEmailChanged    = "email_changed"
+)
+
+// Supported email locales
+const (
+	LocaleEnglish  = "en"
+	LocaleFilipino = "fil"
+)
+
+const emailLayoutFile = "layout.html.tmpl"
+
+//go:embed templates/email
+var emailTemplateFS embed.FS
+
+// RenderedEmail holds a rendered subject plus HTML and plain-text bodies
+type RenderedEmail struct {
+	Subject  string
+	HTMLBody string
+	TextBody string
+}
+
+type compiledEmailTemplate struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+// compiledEmailTemplates caches embedded templates by "<locale>/<name>".
+// Templates loaded from EMAIL_TEMPLATE_DIR are not cached so admins can edit
+// them without restarting the server.
+var compiledEmailTemplates sync.Map
+
+// NormalizeLocale maps a user or Accept-Language value to a supported locale.
+func NormalizeLocale(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	// Accept-Language may contain a list ("fil-PH,fil;q=0.9,en;q=0.8")
+	if i := strings.IndexAny(locale, ",;"); i >= 0 {
+		locale = locale[:i]
+	}
+	if i := strings.IndexAny(locale, "-_"); i >= 0 {
+		locale = locale[:i]
+	}
+
+	switch locale {
+	case "fil", "tl":
+		return LocaleFilipino
+	case "en":
+		return LocaleEnglish
+	}
+	return defaultEmailLocale()
+}
+
+func defaultEmailLocale() string {
+	switch strings.ToLower(os.Getenv("EMAIL_DEFAULT_LOCALE")) {
+	case LocaleFilipino, "tl":
+		return LocaleFilipino
+	}
+	return LocaleEnglish
+}
+
+// readEmailTemplate returns the template source, preferring an admin override
+// in EMAIL_TEMPLATE_DIR and falling back to the embedded copy.
+func readEmailTemplate(locale, file string) ([]byte, error) {
+	if dir := os.Getenv("EMAIL_TEMPLATE_DIR"); dir != "" {
+		if b, err := os.ReadFile(filepath.Join(dir, locale, file)); err == nil {
+			return b, nil
+		}
+	}
+	return emailTemplateFS.ReadFile(path.Join("templates/email", locale, file))
+}
+
+func loadEmailTemplate(name, locale string) (*compiledEmailTemplate, error) {
+	key := locale + "/" + name
+	if os.Getenv("EMAIL_TEMPLATE_DIR") == "" {
+		if cached, ok := compiledEmailTemplates.Load(key); ok {
+			return cached.(*compiledEmailTemplate), nil
+		}
+	}
+
+	layoutSrc, err := readEmailTemplate("", emailLayoutFile)
+	if err != nil {
+		return nil, fmt.Errorf("email layout not found: %w", err)
+	}
+	htmlSrc, err := readEmailTemplate(locale, name+".html.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("email template %s/%s.html.tmpl not found: %w", locale, name, err)
+	}
+	textSrc, err := readEmailTemplate(locale, name+".txt.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("email template %s/%s.txt.tmpl not found: %w", locale, name, err)
+	}
+
+	funcs := htmltemplate.FuncMap{"locale": func() string { return locale }}
+	h, err := htmltemplate.New("layout").Funcs(funcs).Parse(string(layoutSrc))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse email layout: %w", err)
+	}
+	if _, err := h.Parse(string(htmlSrc)); err != nil {
+		return nil, fmt.Errorf("failed to parse email template %s/%s: %w", locale, name, err)
+	}
+
+	t, err := texttemplate.New(name).Parse(string(textSrc))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse text email template %s/%s: %w", locale, name, err)
+	}
+
+	compiled := &compiledEmailTemplate{html: h, text: t}
+	if os.Getenv("EMAIL_TEMPLATE_DIR") == "" {
+		compiledEmailTemplates.Store(key, compiled)
+	}
+	return compiled, nil
+}
+
+// RenderEmail renders the named template for the given locale. Unknown locales
+// and templates missing in a locale fall back to English.
+func RenderEmail(name, locale string, data interface{}) (*RenderedEmail, error) {
+	locale = NormalizeLocale(locale)
+
+	tmpl, err := loadEmailTemplate(name, locale)
+	if err != nil && locale != LocaleEnglish {
+		tmpl, err = loadEmailTemplate(name, LocaleEnglish)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var subject, text, htmlBody bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, fmt.Errorf("failed to render email subject: %w", err)
+	}
+	if err := tmpl.text.ExecuteTemplate(&text, "body", data); err != nil {
+		return nil, fmt.Errorf("failed to render text email: %w", err)
+	}
+	if err := tmpl.html.ExecuteTemplate(&htmlBody, "layout", data); err != nil {
+		return nil, fmt.Errorf("failed to render html email: %w", err)
+	}
+
+	return &RenderedEmail{
+		Subject:  strings.TrimSpace(subject.String()),
+		HTMLBody: htmlBody.String(),
+		TextBody: strings.TrimSpace(text.String()),
+	}, nil
+}
+
+// SendTemplatedEmail renders the named template and sends it to a recipient
+func SendTemplatedEmail(to, name, locale string, data interface{}) error {
+	rendered, err := RenderEmail(name, locale, data)
+	if err != nil {
+		return err
+	}
+	return SendEmailMessage(to, rendered.Subject, rendered.HTMLBody, rendered.TextBody)
+}
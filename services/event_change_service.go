@@ -0,0 +1,173 @@
+// services/event_change_service.go
+package services
+
+import (
+	"attendance-system/connection"
+	"attendance-system/logging"
+	"attendance-system/models"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm/clause"
+)
+
+// eventChangeMaxAttempts is how many delivery runs retry failed sends before
+// a change is marked delivered anyway.
+const eventChangeMaxAttempts = 5
+
+// recordEventChange stores a change notice for affected students. Changes that
+// students never saw (drafts) or that don't alter time/location are ignored.
+// Pending reminders are reset when the time moves so they fire for the new slot.
+func recordEventChange(before, after models.Event, kind, changedBy, reason string) {
+	if eventStatus(&before) == models.EventStatusDraft {
+		return
+	}
+
+	timeChanged := !before.StartTime.Equal(after.StartTime) || !before.EndTime.Equal(after.EndTime)
+	locationChanged := strings.TrimSpace(before.Location) != strings.TrimSpace(after.Location)
+	if kind == models.EventChangeUpdated && !timeChanged && !locationChanged {
+		return
+	}
+
+	change := &models.EventChange{
+		EventID:      after.ID,
+		Kind:         kind,
+		OldStartTime: before.StartTime,
+		OldEndTime:   before.EndTime,
+		NewStartTime: after.StartTime,
+		NewEndTime:   after.EndTime,
+		OldLocation:  before.Location,
+		NewLocation:  after.Location,
+		Reason:       strings.TrimSpace(reason),
+		ChangedBy:    changedBy,
+	}
+	if err := CreateWithoutID(change); err != nil {
+		logging.Logger.Error("Failed to record event change", zap.Uint("event_id", after.ID), zap.Error(err))
+		return
+	}
+
+	if timeChanged && kind != models.EventChangeCancelled {
+		connection.DB.Where(EventWhere, after.ID).Delete(&models.EventReminder{})
+	}
+}
+
+// DeliverPendingEventChanges emails every undelivered change notice. It is run
+// by the event_change_notifications job.
+func DeliverPendingEventChanges() (int, error) {
+	var changes []models.EventChange
+	if err := connection.DB.Where("delivered_at IS NULL").Order("id").Find(&changes).Error; err != nil {
+		return 0, fmt.Errorf("failed to load event changes: %v", err)
+	}
+
+	total := 0
+	for i := range changes {
+		sent, failed := deliverEventChange(&changes[i])
+		total += sent
+
+		updates := map[string]interface{}{"attempts": changes[i].Attempts + 1}
+		if failed == 0 || changes[i].Attempts+1 >= eventChangeMaxAttempts {
+			updates["delivered_at"] = time.Now()
+		}
+		connection.DB.Model(&changes[i]).Updates(updates)
+	}
+	return total, nil
+}
+
+// deliverEventChange sends one change to its recipients. Each (change, student)
+// is claimed before sending so no student gets the same notice twice.
+func deliverEventChange(change *models.EventChange) (sent, failed int) {
+	var event models.Event
+	if err := connection.DB.First(&event, change.EventID).Error; err != nil {
+		return 0, 0
+	}
+
+	recipients, err := eventChangeRecipients(event)
+	if err != nil {
+		logging.Logger.Error("Failed to load event change recipients", zap.Uint("event_id", event.ID), zap.Error(err))
+		return 0, 1
+	}
+
+	for _, student := range recipients {
+		if student.Email == "" {
+			continue
+		}
+
+		record := models.EventChangeNotification{ChangeID: change.ID, StudentID: student.StudentID}
+		result := connection.DB.Clauses(clause.OnConflict{DoNothing: true}).Omit("id").Create(&record)
+		if result.Error != nil {
+			failed++
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		if err := sendEventChangeEmail(event, change, student); err != nil {
+			// Release the claim so the next run retries this student
+			connection.DB.Delete(&record)
+			failed++
+			continue
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		logging.Logger.Info("Event change notices sent",
+			zap.Uint("event_id", event.ID),
+			zap.String("kind", change.Kind),
+			zap.Int("count", sent),
+		)
+	}
+	return sent, failed
+}
+
+// eventChangeRecipients returns eligible students plus anyone with an
+// attendance record for the event, without duplicates.
+func eventChangeRecipients(event models.Event) ([]models.User, error) {
+	eligible, err := EligibleStudentsForEvent(event)
+	if err != nil {
+		return nil, err
+	}
+
+	var attendees []models.User
+	if err := connection.DB.Where("student_id IN (?)",
+		connection.DB.Model(&models.Attendance{}).Select("student_id").Where(EventWhere, event.ID)).
+		Find(&attendees).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch event attendees: %v", err)
+	}
+
+	seen := make(map[string]bool, len(eligible)+len(attendees))
+	recipients := make([]models.User, 0, len(eligible)+len(attendees))
+	for _, list := range [][]models.User{eligible, attendees} {
+		for _, u := range list {
+			if seen[u.StudentID] {
+				continue
+			}
+			seen[u.StudentID] = true
+			recipients = append(recipients, u)
+		}
+	}
+	return recipients, nil
+}
+
+func sendEventChangeEmail(event models.Event, change *models.EventChange, student models.User) error {
+	const layout = "Monday, January 2, 2006 3:04 PM"
+	data := map[string]interface{}{
+		"StudentName":     strings.TrimSpace(student.FirstName + " " + student.LastName),
+		"EventTitle":      event.Title,
+		"Kind":            change.Kind,
+		"Reason":          change.Reason,
+		"OldStartTime":    change.OldStartTime.Local().Format(layout),
+		"OldEndTime":      change.OldEndTime.Local().Format("3:04 PM"),
+		"NewStartTime":    change.NewStartTime.Local().Format(layout),
+		"NewEndTime":      change.NewEndTime.Local().Format("3:04 PM"),
+		"OldLocation":     change.OldLocation,
+		"NewLocation":     change.NewLocation,
+		"TimeChanged":     !change.OldStartTime.Equal(change.NewStartTime) || !change.OldEndTime.Equal(change.NewEndTime),
+		"LocationChanged": change.OldLocation != change.NewLocation,
+		"EventLink":       fmt.Sprintf("%s/events/%d", AppBaseURL(), event.ID),
+	}
+	return SendTemplatedEmail(student.Email, EmailTemplateEventChanged, student.Locale, data)
+}
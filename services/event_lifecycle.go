@@ -0,0 +1,306 @@
+// services/event_lifecycle.go
+package services
+
+import (
+	"attendance-system/connection"
+	"attendance-system/logging"
+	"attendance-system/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SystemActor is recorded as the actor for clock-driven status changes
+const SystemActor = "system"
+
+// eventTransitions lists the allowed lifecycle moves:
+// draft → scheduled → ongoing → completed, plus cancelled and postponed.
+var eventTransitions = map[string][]string{
+	models.EventStatusDraft:     {models.EventStatusScheduled, models.EventStatusCancelled},
+	models.EventStatusScheduled: {models.EventStatusOngoing, models.EventStatusCompleted, models.EventStatusPostponed, models.EventStatusCancelled},
+	models.EventStatusPostponed: {models.EventStatusOngoing, models.EventStatusCompleted, models.EventStatusPostponed, models.EventStatusCancelled},
+	models.EventStatusOngoing:   {models.EventStatusCompleted, models.EventStatusCancelled},
+	models.EventStatusCompleted: {models.EventStatusOngoing},
+	models.EventStatusCancelled: {models.EventStatusScheduled},
+}
+
+// EventTransition describes a requested status change
+type EventTransition struct {
+	To        string
+	ActorID   string
+	IPAddress string
+	Reason    string
+
+	// New schedule, required when postponing
+	EventDate time.Time
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// eventStatus treats rows created before the lifecycle existed as scheduled
+func eventStatus(event *models.Event) string {
+	if event.Status == "" {
+		return models.EventStatusScheduled
+	}
+	return event.Status
+}
+
+// CanTransitionEvent reports whether an event may move from one status to another
+func CanTransitionEvent(from, to string) bool {
+	for _, allowed := range eventTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// StartEvent opens an event for check-in now, even before its start time
+func StartEvent(eventID uint, t EventTransition) (*models.Event, error) {
+	t.To = models.EventStatusOngoing
+	return transitionEventAs(eventID, t, []string{models.EventStatusScheduled, models.EventStatusPostponed})
+}
+
+// EndEvent completes an event now, before or after its end time
+func EndEvent(eventID uint, t EventTransition) (*models.Event, error) {
+	t.To = models.EventStatusCompleted
+	return transitionEventAs(eventID, t, nil)
+}
+
+// PublishEvent moves a draft to scheduled
+func PublishEvent(eventID uint, t EventTransition) (*models.Event, error) {
+	t.To = models.EventStatusScheduled
+	return transitionEventAs(eventID, t, []string{models.EventStatusDraft})
+}
+
+// PostponeEvent moves an event to a new schedule
+func PostponeEvent(eventID uint, req models.EventPostponeRequest, t EventTransition) (*models.Event, error) {
+	eventDate, start, end, err := parseEventDateTimes(models.EventRequest{
+		EventDate: req.EventDate,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !end.After(time.Now()) {
+		return nil, errors.New("new end_time must be in the future")
+	}
+
+	t.To = models.EventStatusPostponed
+	t.Reason = req.Reason
+	t.EventDate, t.StartTime, t.EndTime = eventDate, start, end
+	return transitionEventAs(eventID, t, nil)
+}
+
+// ReopenEvent brings a completed event back to ongoing (it then stays open until
+// ended manually) or restores a cancelled event to scheduled.
+func ReopenEvent(eventID uint, t EventTransition) (*models.Event, error) {
+	var event models.Event
+	if err := connection.DB.First(&event, eventID).Error; err != nil {
+		return nil, errors.New(errEventNotFound)
+	}
+
+	switch eventStatus(&event) {
+	case models.EventStatusCompleted:
+		t.To = models.EventStatusOngoing
+	case models.EventStatusCancelled:
+		if !event.EndTime.After(time.Now()) {
+			return nil, errors.New("event has already ended; only upcoming cancelled events can be reopened")
+		}
+		t.To = models.EventStatusScheduled
+	default:
+		return nil, fmt.Errorf("only completed or cancelled events can be reopened (current status: %s)", eventStatus(&event))
+	}
+	return transitionEventAs(eventID, t, nil)
+}
+
+// transitionEventAs checks that the actor may manage the event and, when from
+// is given, limits a named action to those source statuses before applying the
+// general transition rules.
+func transitionEventAs(eventID uint, t EventTransition, from []string) (*models.Event, error) {
+	var event models.Event
+	if err := connection.DB.First(&event, eventID).Error; err != nil {
+		return nil, errors.New(errEventNotFound)
+	}
+	if err := ensureUpdatePermission(&event, t.ActorID); err != nil {
+		return nil, err
+	}
+
+	if len(from) > 0 {
+		current := eventStatus(&event)
+		allowed := false
+		for _, s := range from {
+			if s == current {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return nil, fmt.Errorf("cannot change event from %s to %s", current, t.To)
+		}
+	}
+	return TransitionEvent(eventID, t)
+}
+
+// TransitionEvent validates and applies a status change, then records an audit
+// entry and runs its side effects (QR assignment/revert, organizer emails).
+// The update is conditional on the current status so concurrent changes (e.g.
+// the scheduler and a faculty member) cannot both apply.
+func TransitionEvent(eventID uint, t EventTransition) (*models.Event, error) {
+	var event models.Event
+	if err := connection.DB.First(&event, eventID).Error; err != nil {
+		return nil, errors.New(errEventNotFound)
+	}
+
+	from := eventStatus(&event)
+	if !CanTransitionEvent(from, t.To) {
+		return nil, fmt.Errorf("cannot change event from %s to %s", from, t.To)
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":        t.To,
+		"status_reason": strings.TrimSpace(t.Reason),
+	}
+	switch t.To {
+	case models.EventStatusOngoing:
+		if from == models.EventStatusCompleted {
+			// Reopened: stay open until someone ends it
+			updates["ended_at"] = nil
+			updates["manual_end"] = true
+		} else {
+			updates["started_at"] = now
+		}
+	case models.EventStatusCompleted:
+		updates["ended_at"] = now
+		updates["manual_end"] = false
+	case models.EventStatusPostponed:
+		if t.StartTime.IsZero() || t.EndTime.IsZero() {
+			return nil, errors.New("a new start and end time are required to postpone")
+		}
+		termID, err := eventTermFor(event.TermID, t.EventDate)
+		if err != nil {
+			return nil, err
+		}
+		updates["event_date"] = t.EventDate
+		updates["start_time"] = t.StartTime
+		updates["end_time"] = t.EndTime
+		updates["term_id"] = termID
+	case models.EventStatusCancelled:
+		updates["is_active"] = false
+	case models.EventStatusScheduled:
+		updates["is_active"] = true
+	}
+
+	result := connection.DB.Model(&models.Event{}).
+		Where("id = ? AND status = ?", event.ID, event.Status).
+		Updates(updates)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to update event status: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("event status was changed by someone else; please reload and try again")
+	}
+
+	before := event
+	if err := connection.DB.First(&event, eventID).Error; err != nil {
+		return nil, errors.New(errEventNotFound)
+	}
+
+	actor := t.ActorID
+	if actor == "" {
+		actor = SystemActor
+	}
+	details, _ := json.Marshal(map[string]string{
+		"from":   from,
+		"to":     t.To,
+		"reason": strings.TrimSpace(t.Reason),
+	})
+	go LogAuditAction(AuditEventStatusChanged, actor, strconv.FormatUint(uint64(event.ID), 10), string(details), t.IPAddress)
+
+	applyEventTransitionEffects(event, t.To)
+
+	// Affected students are told about cancellations and new schedules
+	switch t.To {
+	case models.EventStatusCancelled:
+		recordEventChange(before, event, models.EventChangeCancelled, actor, t.Reason)
+	case models.EventStatusPostponed:
+		recordEventChange(before, event, models.EventChangePostponed, actor, t.Reason)
+	}
+
+	if actor != SystemActor {
+		go sendEventStatusNotification(event, from, t.To, actor)
+	}
+
+	return &event, nil
+}
+
+// applyEventTransitionEffects keeps student QR codes in step with the event.
+// It runs synchronously so a quick start/end sequence cannot interleave an
+// assignment with a revert.
+func applyEventTransitionEffects(event models.Event, to string) {
+	switch to {
+	case models.EventStatusCompleted, models.EventStatusCancelled:
+		if err := RevertStudentQRCodesForEvent(event.ID); err != nil {
+			logging.Logger.Error("Failed to revert QR codes after status change",
+				zap.Uint("event_id", event.ID), zap.String("to", to), zap.Error(err))
+		}
+		if to == models.EventStatusCompleted {
+			FinalizeOfferingAbsences(event)
+		}
+	case models.EventStatusScheduled, models.EventStatusOngoing:
+		// Publishing, reopening or starting: (re)assign event QR codes. Students
+		// who already hold this event's code are skipped.
+		assignEventQRCodes(event)
+	}
+}
+
+// assignEventQRCodes gives eligible students the event-specific QR code
+func assignEventQRCodes(event models.Event) {
+	if event.OfferingID != nil {
+		var students []models.User
+		if err := enrolledStudentsQuery(*event.OfferingID).Find(&students).Error; err == nil {
+			setStudentEventQRCodes(event.ID, students)
+		}
+		return
+	}
+
+	courses := parseTaggedCoursesCSV(event.TaggedCoursesCSV)
+	if len(courses) == 0 {
+		if event.Course == "" || event.YearLevel == "" {
+			return
+		}
+		courses = []string{event.Course}
+	}
+	updateStudentQRCodesForEvent(event.ID, courses, event.YearLevel, event.Section)
+}
+
+// sendEventStatusNotification tells admins and the event creator about a manual status change
+func sendEventStatusNotification(event models.Event, from, to, actorID string) {
+	data := map[string]interface{}{
+		"EventTitle": event.Title,
+		"FromStatus": from,
+		"ToStatus":   to,
+		"ChangedBy":  actorID,
+		"Reason":     event.StatusReason,
+		"StartTime":  event.StartTime.Local().Format("Monday, January 2, 2006 3:04 PM"),
+		"EndTime":    event.EndTime.Local().Format("3:04 PM"),
+		"Location":   event.Location,
+	}
+
+	for _, recipient := range attendanceNotificationRecipients(event) {
+		if recipient.Email == "" || recipient.StudentID == actorID {
+			continue
+		}
+		if err := SendTemplatedEmail(recipient.Email, EmailTemplateEventStatus, recipient.Locale, data); err != nil {
+			logging.Logger.Error("Failed to send event status email",
+				zap.Uint("event_id", event.ID), zap.Error(err))
+		}
+	}
+}
@@ -8,11 +8,13 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/skip2/go-qrcode"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 const (
@@ -58,14 +60,33 @@ func CreateEvent(req models.EventRequest, createdBy, createdByRole string) (*mod
 		College:       req.College,
 		CreatedBy:     createdBy,
 		CreatedByRole: createdByRole,
-		Status:        "scheduled",
+		Status:        models.EventStatusScheduled,
 		IsActive:      true,
 		QRCodeData:    qrCodeBase64,
 	}
 
+	if req.Draft {
+		event.Status = models.EventStatusDraft
+	}
+
 	// Normalize and set tagged courses (helper handles trimming/uppercasing)
 	setTaggedCoursesFromRequest(event, req)
 
+	// Class events target the offering's enrollment list
+	if req.OfferingID != 0 {
+		if err := applyEventOffering(event, req.OfferingID, createdBy); err != nil {
+			return nil, err
+		}
+	}
+
+	// Validate audience against the institution hierarchy
+	if err := resolveEventInstitution(event); err != nil {
+		return nil, err
+	}
+	if err := assignEventTerm(event); err != nil {
+		return nil, err
+	}
+
 	// Ensure ID is zero so DB assigns it
 	event.ID = 0
 
@@ -74,12 +95,9 @@ func CreateEvent(req models.EventRequest, createdBy, createdByRole string) (*mod
 		return nil, err
 	}
 
-	// If event has tagged courses, update student QR codes to event-specific
-	if len(req.TaggedCourses) > 0 {
-		go updateStudentQRCodesForEvent(event.ID, req.TaggedCourses, req.YearLevel, req.Section)
-	} else if req.Course != "" && req.YearLevel != "" {
-		// Fallback to single course for backward compatibility
-		go updateStudentQRCodesForEvent(event.ID, []string{req.Course}, req.YearLevel, req.Section)
+	// Update student QR codes to event-specific (drafts wait until published)
+	if !req.Draft {
+		go assignEventQRCodes(*event)
 	}
 
 	return event, nil
@@ -181,12 +199,18 @@ func GetEventQRCode(eventID uint) (string, error) {
 	return qrCodeBase64, nil
 }
 
-// updateStudentQRCodesForEvent updates QR codes for students matching event criteria
-func updateStudentQRCodesForEvent(eventID uint, courses []string, yearLevel, section string) {
-	var students []models.User
+// eligibleStudentsQuery builds the course/year/section student match shared by
+// event QR assignment and reminders.
+func eligibleStudentsQuery(courses []string, yearLevel, section string) *gorm.DB {
+	query := connection.DB.Model(&models.User{})
 
-	// Build query for multiple courses
-	query := connection.DB.Where("course IN ?", courses)
+	if len(courses) > 0 {
+		// Build query for multiple courses
+		query = query.Where("course IN ?", courses)
+	} else {
+		// Untagged events are open to every student
+		query = query.Where("role = ?", models.RoleStudent)
+	}
 
 	// Add year level filter if specified
 	if yearLevel != "" {
@@ -195,21 +219,50 @@ func updateStudentQRCodesForEvent(eventID uint, courses []string, yearLevel, sec
 
 	// Add section filter if specified
 	if section != "" {
-		query = query.Where("section = ?", section)
+		query = query.Where(sectionWhere, section)
 	}
 
-	if err := query.Find(&students).Error; err != nil {
-		// Log error without exposing sensitive information
-		return
+	return query
+}
+
+// EligibleStudentsForEvent returns the students an event targets: the
+// enrollment list for class offering events, otherwise the same
+// course/tag/year/section rules as event QR assignment.
+func EligibleStudentsForEvent(event models.Event) ([]models.User, error) {
+	var query *gorm.DB
+	if event.OfferingID != nil {
+		query = enrolledStudentsQuery(*event.OfferingID)
+	} else {
+		courses := parseTaggedCoursesCSV(event.TaggedCoursesCSV)
+		if len(courses) == 0 && event.Course != "" {
+			courses = []string{event.Course}
+		}
+		query = eligibleStudentsQuery(courses, event.YearLevel, event.Section)
 	}
 
-	// Get event details
-	var event models.Event
-	if err := connection.DB.First(&event, eventID).Error; err != nil {
+	var students []models.User
+	if err := query.
+		Where("is_verified = ?", true).
+		Find(&students).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch eligible students: %v", err)
+	}
+	return students, nil
+}
+
+// updateStudentQRCodesForEvent updates QR codes for students matching event criteria
+func updateStudentQRCodesForEvent(eventID uint, courses []string, yearLevel, section string) {
+	var students []models.User
+
+	if err := eligibleStudentsQuery(courses, yearLevel, section).Find(&students).Error; err != nil {
 		// Log error without exposing sensitive information
 		return
 	}
 
+	setStudentEventQRCodes(eventID, students)
+}
+
+// setStudentEventQRCodes gives each student the event-specific QR code
+func setStudentEventQRCodes(eventID uint, students []models.User) {
 	// Generate event-specific QR code for each student
 	for _, student := range students {
 		// Skip if student already has an active event
@@ -289,49 +342,61 @@ func RevertStudentQRCodesForEvent(eventID uint) error {
 	return nil
 }
 
-// CheckAndUpdateCompletedEvents checks for completed events and reverts QR codes
-func CheckAndUpdateCompletedEvents() {
+// CheckAndUpdateCompletedEvents applies clock-driven lifecycle changes: events
+// past their end time are completed (unless reopened for a manual end) and
+// events whose start time has arrived become ongoing. It returns the number of
+// events changed; an error means some could not be loaded or changed.
+func CheckAndUpdateCompletedEvents() (int, error) {
 	now := time.Now()
 	var events []models.Event
 
-	// Find events that have ended but status is still ongoing/scheduled
-	if err := connection.DB.Where("end_time < ? AND status IN ? AND is_active = ?",
-		now, []string{"scheduled", "ongoing"}, true).Find(&events).Error; err != nil {
-		// Log error without exposing sensitive information
-		return
+	// Find events that have ended but status is still ongoing/scheduled/postponed
+	if err := connection.DB.Where("end_time < ? AND status IN ? AND is_active = ? AND (manual_end = ? OR manual_end IS NULL)",
+		now, []string{models.EventStatusScheduled, models.EventStatusOngoing, models.EventStatusPostponed}, true, false).
+		Find(&events).Error; err != nil {
+		return 0, fmt.Errorf("failed to load ended events: %v", err)
 	}
 
+	changed, failed := 0, 0
 	for _, event := range events {
-		// Update event status
-		if err := connection.DB.Model(&models.Event{}).Where("id = ?", event.ID).Updates(map[string]interface{}{"status": "completed"}).Error; err != nil {
-			// Log error without exposing sensitive information
+		if _, err := TransitionEvent(event.ID, EventTransition{To: models.EventStatusCompleted, ActorID: SystemActor}); err != nil {
+			logging.Logger.Warn("Failed to complete event", zap.Uint("event_id", event.ID), zap.Error(err))
+			failed++
 			continue
 		}
-
-		// Revert student QR codes back to student_id
-		if err := RevertStudentQRCodesForEvent(event.ID); err != nil {
-			// Log error without exposing sensitive information
-		}
+		changed++
 	}
 
 	// Also check for events that should be marked as "ongoing"
 	var ongoingEvents []models.Event
-	if err := connection.DB.Where("start_time <= ? AND end_time >= ? AND status = ? AND is_active = ?",
-		now, now, "scheduled", true).Find(&ongoingEvents).Error; err == nil {
-		for _, event := range ongoingEvents {
-			if err := connection.DB.Model(&models.Event{}).Where("id = ?", event.ID).Updates(map[string]interface{}{"status": "ongoing"}).Error; err != nil {
-				// Log error if needed
-			}
+	if err := connection.DB.Where("start_time <= ? AND end_time >= ? AND status IN ? AND is_active = ?",
+		now, now, []string{models.EventStatusScheduled, models.EventStatusPostponed}, true).Find(&ongoingEvents).Error; err != nil {
+		return changed, fmt.Errorf("failed to load starting events: %v", err)
+	}
+	for _, event := range ongoingEvents {
+		if _, err := TransitionEvent(event.ID, EventTransition{To: models.EventStatusOngoing, ActorID: SystemActor}); err != nil {
+			logging.Logger.Warn("Failed to start event", zap.Uint("event_id", event.ID), zap.Error(err))
+			failed++
+			continue
 		}
+		changed++
 	}
+
+	if failed > 0 {
+		return changed, fmt.Errorf("failed to update %d events", failed)
+	}
+	return changed, nil
 }
 
 // GetEvent retrieves an event by ID
-func GetEvent(eventID uint) (*models.Event, error) {
+func GetEvent(eventID uint, viewer models.User) (*models.Event, error) {
 	var event models.Event
 	if err := connection.DB.First(&event, eventID).Error; err != nil {
 		return nil, errors.New(errEventNotFound)
 	}
+	if !CanViewEvent(viewer, &event) {
+		return nil, ErrForbidden
+	}
 
 	// Calculate attendee count
 	var count int64
@@ -370,11 +435,31 @@ func GetAllEvents(filters map[string]interface{}) ([]models.Event, error) {
 	if isActive, ok := filters["is_active"].(bool); ok {
 		query = query.Where("is_active = ?", isActive)
 	}
+	if hideDrafts, ok := filters["hide_drafts"].(bool); ok && hideDrafts {
+		query = query.Where("status <> ?", models.EventStatusDraft)
+	}
+	// Institution hierarchy, academic term and class offering IDs
+	for _, column := range []string{"college_id", "department_id", "course_id", "section_id", "term_id", "offering_id"} {
+		if id, ok := filters[column].(uint); ok && id != 0 {
+			query = query.Where(column+" = ?", id)
+		}
+	}
 
 	if err := query.Order("event_date DESC, start_time DESC").Find(&events).Error; err != nil {
 		return nil, fmt.Errorf("failed to fetch events: %v", err)
 	}
 
+	// Drop drafts the viewer may not see
+	if viewer, ok := filters["viewer"].(models.User); ok {
+		visible := events[:0]
+		for i := range events {
+			if CanViewEvent(viewer, &events[i]) {
+				visible = append(visible, events[i])
+			}
+		}
+		events = visible
+	}
+
 	// Calculate attendee count for each event
 	for i := range events {
 		var count int64
@@ -408,14 +493,27 @@ func UpdateEvent(eventID uint, req models.EventRequest, updatedBy string) (*mode
 		return nil, err
 	}
 
+	before := event
 	if err := applyEventUpdates(&event, req); err != nil {
 		return nil, err
 	}
+	if err := resolveEventInstitution(&event); err != nil {
+		return nil, err
+	}
+	if err := assignEventTerm(&event); err != nil {
+		return nil, err
+	}
 
 	if err := connection.DB.Save(&event).Error; err != nil {
 		return nil, fmt.Errorf("failed to update event: %v", err)
 	}
 
+	// Tell students when the time or location of an upcoming/running event moves
+	switch eventStatus(&event) {
+	case models.EventStatusScheduled, models.EventStatusPostponed, models.EventStatusOngoing:
+		recordEventChange(before, event, models.EventChangeUpdated, updatedBy, "")
+	}
+
 	// If tagged courses were updated, revert existing QR codes and generate new ones
 	if len(req.TaggedCourses) > 0 {
 		go func() {
@@ -429,7 +527,8 @@ func UpdateEvent(eventID uint, req models.EventRequest, updatedBy string) (*mode
 	return &event, nil
 }
 
-// ensureUpdatePermission returns nil when updatedBy is allowed to modify event.
+// ensureUpdatePermission returns nil when updatedBy is allowed to modify event
+// (the creator, a co-organizer, or an admin).
 func ensureUpdatePermission(event *models.Event, updatedBy string) error {
 	if event.CreatedBy == updatedBy {
 		return nil
@@ -438,8 +537,8 @@ func ensureUpdatePermission(event *models.Event, updatedBy string) error {
 	if err := connection.DB.Where(studentWhere, updatedBy).First(&user).Error; err != nil {
 		return errors.New("unauthorized")
 	}
-	if user.Role != "superadmin" && user.Role != "admin" && user.Role != "faculty" {
-		return errors.New("unauthorized: only event creator, admin, or faculty can update")
+	if !canEditEvent(event, user) {
+		return errors.New("unauthorized: only the event creator, a co-organizer, or an admin can update")
 	}
 	return nil
 }
@@ -527,8 +626,8 @@ func setTaggedCoursesFromRequest(event *models.Event, req models.EventRequest) {
 	}
 }
 
-// DeleteEvent deletes an event (soft delete by setting is_active to false)
-func DeleteEvent(eventID uint, deletedBy string) error {
+// DeleteEvent deletes an event (soft delete: the event is cancelled and is_active set to false)
+func DeleteEvent(eventID uint, deletedBy, ipAddress string) error {
 	var event models.Event
 	if err := connection.DB.First(&event, eventID).Error; err != nil {
 		return errors.New(errEventNotFound)
@@ -540,20 +639,28 @@ func DeleteEvent(eventID uint, deletedBy string) error {
 		if err := connection.DB.Where(studentWhere, deletedBy).First(&user).Error; err != nil {
 			return errors.New("unauthorized")
 		}
-		if user.Role != "superadmin" && user.Role != "admin" {
-			return errors.New("unauthorized: only event creator or admin can delete")
+		if !canDeleteEvent(&event, user) {
+			return errors.New("unauthorized: only the event creator or a user with the event.delete.any permission can delete")
 		}
 	}
 
-	// Soft delete
-	event.IsActive = false
-	event.Status = "cancelled"
-	if err := connection.DB.Save(&event).Error; err != nil {
-		return fmt.Errorf("failed to delete event: %v", err)
+	// Finished events are only hidden; their status stays as it was
+	if status := eventStatus(&event); status == models.EventStatusCompleted || status == models.EventStatusCancelled {
+		if err := connection.DB.Model(&event).Update("is_active", false).Error; err != nil {
+			return fmt.Errorf("failed to delete event: %v", err)
+		}
+		go LogAuditAction(AuditEventDeleted, deletedBy, strconv.FormatUint(uint64(eventID), 10), "status: "+status, ipAddress)
+		return nil
 	}
 
-	// Revert student QR codes back to original when event is deleted
-	go RevertStudentQRCodesForEvent(eventID)
+	// Soft delete through the lifecycle so QR codes are reverted and the change audited
+	if _, err := TransitionEvent(eventID, EventTransition{
+		To:        models.EventStatusCancelled,
+		ActorID:   deletedBy,
+		IPAddress: ipAddress,
+	}); err != nil {
+		return fmt.Errorf("failed to delete event: %v", err)
+	}
 
 	return nil
 }
@@ -567,7 +674,7 @@ func GetEventsByStudent(studentID string) ([]models.Event, error) {
 
 	// Return all active events but compute whether the student is allowed to enter
 	var events []models.Event
-	query := connection.DB.Where("is_active = ?", true)
+	query := connection.DB.Where("is_active = ? AND status <> ?", true, models.EventStatusDraft)
 	if err := query.Order("event_date DESC, start_time DESC").Find(&events).Error; err != nil {
 		return nil, fmt.Errorf("failed to fetch events: %v", err)
 	}
@@ -589,10 +696,16 @@ func GetEventsByStudent(studentID string) ([]models.Event, error) {
 
 // populateTaggedCoursesAndAllowed fills transient TaggedCourses and Allowed fields
 // for a slice of events given a user. This keeps the logic out of GetEventsByStudent
-// and reduces its cognitive complexity.
+// and reduces its cognitive complexity. Class offering events are allowed only
+// for enrolled students.
 func populateTaggedCoursesAndAllowed(events []models.Event, user *models.User) {
+	enrolled := enrolledOfferingIDs(user.StudentID)
 	for i := range events {
 		events[i].TaggedCourses = parseTaggedCoursesCSV(events[i].TaggedCoursesCSV)
+		if events[i].OfferingID != nil {
+			events[i].Allowed = enrolled[*events[i].OfferingID]
+			continue
+		}
 		events[i].Allowed = isUserAllowedForEvent(events[i], user)
 	}
 }
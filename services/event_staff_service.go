@@ -0,0 +1,115 @@
+// services/event_staff_service.go
+package services
+
+import (
+	"attendance-system/connection"
+	"attendance-system/models"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+// ListEventStaff returns the staff assigned to an event
+func ListEventStaff(eventID uint, requester models.User) ([]models.EventStaff, error) {
+	var event models.Event
+	if err := connection.DB.First(&event, eventID).Error; err != nil {
+		return nil, errors.New(errEventNotFound)
+	}
+	if !canScanEvent(&event, requester) {
+		return nil, errors.New("unauthorized: only event staff can view assignments")
+	}
+
+	var staff []models.EventStaff
+	if err := connection.DB.Where(EventWhere, eventID).Order("role, student_id").Find(&staff).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch event staff: %v", err)
+	}
+
+	ids := make([]string, 0, len(staff))
+	for _, s := range staff {
+		ids = append(ids, s.StudentID)
+	}
+	var users []models.User
+	if len(ids) > 0 {
+		connection.DB.Select("student_id", "first_name", "last_name", "role").Where("student_id IN ?", ids).Find(&users)
+	}
+	byID := make(map[string]models.User, len(users))
+	for _, u := range users {
+		byID[u.StudentID] = u
+	}
+	for i := range staff {
+		if u, ok := byID[staff[i].StudentID]; ok {
+			staff[i].Name = strings.TrimSpace(u.FirstName + " " + u.LastName)
+			staff[i].UserRole = u.Role
+		}
+	}
+	return staff, nil
+}
+
+// AssignEventStaff adds a user to an event's staff or changes their role
+func AssignEventStaff(eventID uint, req models.EventStaffRequest, owner models.User, ipAddress string) (*models.EventStaff, error) {
+	var event models.Event
+	if err := connection.DB.First(&event, eventID).Error; err != nil {
+		return nil, errors.New(errEventNotFound)
+	}
+	if err := ensureEventOwner(&event, owner); err != nil {
+		return nil, err
+	}
+
+	if req.Role != models.EventStaffCoOrganizer && req.Role != models.EventStaffScanner {
+		return nil, errors.New("invalid role. Must be 'co_organizer' or 'scanner'")
+	}
+	if req.StudentID == event.CreatedBy {
+		return nil, errors.New("the event owner already has full access")
+	}
+
+	var user models.User
+	if err := connection.DB.Where(studentWhere, req.StudentID).First(&user).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	staff := models.EventStaff{
+		EventID:    eventID,
+		StudentID:  user.StudentID,
+		Role:       req.Role,
+		AssignedBy: owner.StudentID,
+	}
+	if err := connection.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "event_id"}, {Name: "student_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "assigned_by", "updated_at"}),
+	}).Omit("id").Create(&staff).Error; err != nil {
+		return nil, fmt.Errorf("failed to assign event staff: %v", err)
+	}
+
+	go LogAuditAction(AuditEventStaffAssigned, owner.StudentID, user.StudentID,
+		fmt.Sprintf("event %d: %s", eventID, req.Role), ipAddress)
+
+	staff.Name = strings.TrimSpace(user.FirstName + " " + user.LastName)
+	staff.UserRole = user.Role
+	return &staff, nil
+}
+
+// RemoveEventStaff removes a user from an event's staff
+func RemoveEventStaff(eventID uint, studentID string, owner models.User, ipAddress string) error {
+	var event models.Event
+	if err := connection.DB.First(&event, eventID).Error; err != nil {
+		return errors.New(errEventNotFound)
+	}
+	if err := ensureEventOwner(&event, owner); err != nil {
+		return err
+	}
+
+	result := connection.DB.Where("event_id = ? AND student_id = ?", eventID, studentID).Delete(&models.EventStaff{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove event staff: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("staff assignment not found")
+	}
+
+	go LogAuditAction(AuditEventStaffRemoved, owner.StudentID, studentID,
+		"event "+strconv.FormatUint(uint64(eventID), 10), ipAddress)
+	return nil
+}
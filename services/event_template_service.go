@@ -0,0 +1,323 @@
+// services/event_template_service.go
+package services
+
+import (
+	"attendance-system/connection"
+	"attendance-system/models"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const errEventTemplateNotFound = "event template not found"
+
+// visibleTemplatesQuery limits templates to the user's own (their
+// department's for department admins) plus those shared with the user's
+// department.
+func visibleTemplatesQuery(user models.User) *gorm.DB {
+	query := connection.DB.Model(&models.EventTemplate{})
+	if isSuperAdmin(user) {
+		return query
+	}
+	owned := scopeOwnedQuery(connection.DB, user, "owner_id")
+	if user.Department == "" {
+		return query.Where(owned)
+	}
+	return query.Where(connection.DB.Where(owned).Or("shared = ? AND department = ?", true, user.Department))
+}
+
+// validateTemplateTime accepts an empty value or HH:MM
+func validateTemplateTime(field, value string) error {
+	if value == "" {
+		return nil
+	}
+	if _, err := time.Parse("15:04", value); err != nil {
+		return fmt.Errorf("invalid %s format. Use HH:MM", field)
+	}
+	return nil
+}
+
+// CreateEventTemplate saves a new template owned by the user
+func CreateEventTemplate(req models.EventTemplateRequest, owner models.User) (*models.EventTemplate, error) {
+	tmpl := &models.EventTemplate{OwnerID: owner.StudentID}
+
+	if req.FromEventID != 0 {
+		var event models.Event
+		if err := connection.DB.First(&event, req.FromEventID).Error; err != nil {
+			return nil, errors.New(errEventNotFound)
+		}
+		if !CanViewEvent(owner, &event) {
+			return nil, ErrForbidden
+		}
+		copyEventIntoTemplate(tmpl, event)
+	}
+
+	applyTemplateRequest(tmpl, req)
+	if strings.TrimSpace(tmpl.Name) == "" {
+		tmpl.Name = tmpl.Title
+	}
+	if strings.TrimSpace(tmpl.Name) == "" {
+		return nil, errors.New("name is required")
+	}
+	if err := validateTemplate(tmpl, owner); err != nil {
+		return nil, err
+	}
+
+	if err := CreateWithoutID(tmpl); err != nil {
+		return nil, fmt.Errorf("failed to create event template: %v", err)
+	}
+	tmpl.TaggedCourses = parseTaggedCoursesCSV(tmpl.TaggedCoursesCSV)
+	return tmpl, nil
+}
+
+// copyEventIntoTemplate fills template fields from an existing event
+func copyEventIntoTemplate(tmpl *models.EventTemplate, event models.Event) {
+	tmpl.Name = event.Title
+	tmpl.Title = event.Title
+	tmpl.Description = event.Description
+	tmpl.Location = event.Location
+	tmpl.Course = event.Course
+	tmpl.Section = event.Section
+	tmpl.YearLevel = event.YearLevel
+	tmpl.Department = event.Department
+	tmpl.College = event.College
+	tmpl.TaggedCoursesCSV = event.TaggedCoursesCSV
+	tmpl.StartTime = event.StartTime.Local().Format("15:04")
+	tmpl.EndTime = event.EndTime.Local().Format("15:04")
+}
+
+// applyTemplateRequest copies provided non-empty fields from req
+func applyTemplateRequest(tmpl *models.EventTemplate, req models.EventTemplateRequest) {
+	if req.Name != "" {
+		tmpl.Name = req.Name
+	}
+	if req.Shared != nil {
+		tmpl.Shared = *req.Shared
+	}
+	if req.Title != "" {
+		tmpl.Title = req.Title
+	}
+	if req.Description != "" {
+		tmpl.Description = req.Description
+	}
+	if req.Location != "" {
+		tmpl.Location = req.Location
+	}
+	if req.Course != "" {
+		tmpl.Course = req.Course
+	}
+	if req.Section != "" {
+		tmpl.Section = req.Section
+	}
+	if req.YearLevel != "" {
+		tmpl.YearLevel = req.YearLevel
+	}
+	if req.Department != "" {
+		tmpl.Department = req.Department
+	}
+	if req.College != "" {
+		tmpl.College = req.College
+	}
+	if req.StartTime != "" {
+		tmpl.StartTime = req.StartTime
+	}
+	if req.EndTime != "" {
+		tmpl.EndTime = req.EndTime
+	}
+	if len(req.TaggedCourses) > 0 {
+		var event models.Event
+		setTaggedCoursesFromRequest(&event, models.EventRequest{TaggedCourses: req.TaggedCourses})
+		tmpl.TaggedCoursesCSV = event.TaggedCoursesCSV
+	}
+}
+
+// validateTemplate checks times and defaults the sharing department to the owner's
+func validateTemplate(tmpl *models.EventTemplate, owner models.User) error {
+	if err := validateTemplateTime("start_time", tmpl.StartTime); err != nil {
+		return err
+	}
+	if err := validateTemplateTime("end_time", tmpl.EndTime); err != nil {
+		return err
+	}
+	if tmpl.StartTime != "" && tmpl.EndTime != "" && tmpl.EndTime <= tmpl.StartTime {
+		return errors.New("end_time must be after start_time")
+	}
+	if tmpl.Shared && tmpl.Department == "" {
+		if owner.Department == "" {
+			return errors.New("department is required to share a template")
+		}
+		tmpl.Department = owner.Department
+	}
+	return nil
+}
+
+// ListEventTemplates returns templates the user can use
+func ListEventTemplates(user models.User) ([]models.EventTemplate, error) {
+	var templates []models.EventTemplate
+	if err := visibleTemplatesQuery(user).Order("name ASC").Find(&templates).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch event templates: %v", err)
+	}
+	for i := range templates {
+		templates[i].TaggedCourses = parseTaggedCoursesCSV(templates[i].TaggedCoursesCSV)
+	}
+	return templates, nil
+}
+
+// GetEventTemplate returns a template visible to the user
+func GetEventTemplate(templateID uint, user models.User) (*models.EventTemplate, error) {
+	var tmpl models.EventTemplate
+	if err := visibleTemplatesQuery(user).Where("id = ?", templateID).First(&tmpl).Error; err != nil {
+		return nil, errors.New(errEventTemplateNotFound)
+	}
+	tmpl.TaggedCourses = parseTaggedCoursesCSV(tmpl.TaggedCoursesCSV)
+	return &tmpl, nil
+}
+
+// getOwnedEventTemplate loads a template the user may modify (owner or admin)
+func getOwnedEventTemplate(templateID uint, user models.User) (*models.EventTemplate, error) {
+	tmpl, err := GetEventTemplate(templateID, user)
+	if err != nil {
+		return nil, err
+	}
+	if tmpl.OwnerID != user.StudentID && !administersStudentID(user, tmpl.OwnerID) {
+		return nil, errors.New("unauthorized: only the template owner or an admin can modify it")
+	}
+	return tmpl, nil
+}
+
+// UpdateEventTemplate applies non-empty fields to a template
+func UpdateEventTemplate(templateID uint, req models.EventTemplateRequest, user models.User) (*models.EventTemplate, error) {
+	tmpl, err := getOwnedEventTemplate(templateID, user)
+	if err != nil {
+		return nil, err
+	}
+
+	applyTemplateRequest(tmpl, req)
+	if err := validateTemplate(tmpl, user); err != nil {
+		return nil, err
+	}
+
+	if err := connection.DB.Save(tmpl).Error; err != nil {
+		return nil, fmt.Errorf("failed to update event template: %v", err)
+	}
+	tmpl.TaggedCourses = parseTaggedCoursesCSV(tmpl.TaggedCoursesCSV)
+	return tmpl, nil
+}
+
+// DeleteEventTemplate removes a template
+func DeleteEventTemplate(templateID uint, user models.User) error {
+	tmpl, err := getOwnedEventTemplate(templateID, user)
+	if err != nil {
+		return err
+	}
+	if err := connection.DB.Delete(tmpl).Error; err != nil {
+		return fmt.Errorf("failed to delete event template: %v", err)
+	}
+	return nil
+}
+
+// CreateEventFromTemplate creates a new event on the given date from a template
+func CreateEventFromTemplate(templateID uint, req models.EventFromTemplateRequest, user models.User) (*models.Event, error) {
+	tmpl, err := GetEventTemplate(templateID, user)
+	if err != nil {
+		return nil, err
+	}
+
+	eventReq := models.EventRequest{
+		Title:         tmpl.Title,
+		Description:   tmpl.Description,
+		EventDate:     req.EventDate,
+		StartTime:     tmpl.StartTime,
+		EndTime:       tmpl.EndTime,
+		Location:      tmpl.Location,
+		Course:        tmpl.Course,
+		Section:       tmpl.Section,
+		YearLevel:     tmpl.YearLevel,
+		Department:    tmpl.Department,
+		College:       tmpl.College,
+		TaggedCourses: tmpl.TaggedCourses,
+		Draft:         req.Draft,
+	}
+	if eventReq.Title == "" {
+		eventReq.Title = tmpl.Name
+	}
+	if req.StartTime != "" {
+		eventReq.StartTime = req.StartTime
+	}
+	if req.EndTime != "" {
+		eventReq.EndTime = req.EndTime
+	}
+	if eventReq.EventDate == "" || eventReq.StartTime == "" || eventReq.EndTime == "" {
+		return nil, errors.New("event_date, start_time, and end_time are required")
+	}
+
+	return CreateEvent(eventReq, user.StudentID, user.Role)
+}
+
+// CloneEvent copies an event's fields into a new draft on a shifted date. The
+// clone gets its own event QR code and no attendance records.
+func CloneEvent(eventID uint, req models.CloneEventRequest, user models.User) (*models.Event, error) {
+	var source models.Event
+	if err := connection.DB.First(&source, eventID).Error; err != nil {
+		return nil, errors.New(errEventNotFound)
+	}
+	// Cloning copies the whole event, so it needs the same access as reading it
+	if !CanViewEvent(user, &source) {
+		return nil, ErrForbidden
+	}
+
+	shiftDays := req.ShiftDays
+	if req.EventDate != "" {
+		newDate, err := time.Parse("2006-01-02", req.EventDate)
+		if err != nil {
+			return nil, errors.New("invalid event_date format. Use YYYY-MM-DD")
+		}
+		oldDate := time.Date(source.EventDate.Year(), source.EventDate.Month(), source.EventDate.Day(), 0, 0, 0, 0, time.UTC)
+		shiftDays = int(newDate.Sub(oldDate).Hours() / 24)
+	}
+	if shiftDays == 0 {
+		return nil, errors.New("event_date or shift_days is required and must move the event")
+	}
+
+	qrCodeBase64, err := generateEventQRCode(user.StudentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate QR code: %v", err)
+	}
+
+	clone := &models.Event{
+		Title:            source.Title,
+		Description:      source.Description,
+		EventDate:        source.EventDate.AddDate(0, 0, shiftDays),
+		StartTime:        source.StartTime.AddDate(0, 0, shiftDays),
+		EndTime:          source.EndTime.AddDate(0, 0, shiftDays),
+		Location:         source.Location,
+		Course:           source.Course,
+		Section:          source.Section,
+		YearLevel:        source.YearLevel,
+		Department:       source.Department,
+		College:          source.College,
+		InstitutionRefs:  source.InstitutionRefs,
+		OfferingID:       source.OfferingID,
+		TaggedCoursesCSV: source.TaggedCoursesCSV,
+		CreatedBy:        user.StudentID,
+		CreatedByRole:    user.Role,
+		Status:           models.EventStatusDraft,
+		IsActive:         true,
+		QRCodeData:       qrCodeBase64,
+	}
+	if req.Title != "" {
+		clone.Title = req.Title
+	}
+	if err := assignEventTerm(clone); err != nil {
+		return nil, err
+	}
+
+	if err := persistEvent(clone); err != nil {
+		return nil, err
+	}
+	clone.TaggedCourses = parseTaggedCoursesCSV(clone.TaggedCoursesCSV)
+	return clone, nil
+}
@@ -0,0 +1,338 @@
+// services/file_storage.go
+package services
+
+import (
+	"attendance-system/logging"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Storage backend names accepted by STORAGE_BACKEND
+const (
+	StorageBackendLocal = "local"
+	StorageBackendS3    = "s3"
+)
+
+// ErrFileNotFound is returned by FileStorage.Get for a missing key
+var ErrFileNotFound = errors.New("file not found")
+
+// FileStorage keeps uploaded files such as profile pictures. Keys are
+// slash-separated paths; implementations are selected by the STORAGE_BACKEND
+// environment variable (local or s3).
+type FileStorage interface {
+	Name() string
+	Put(key string, data []byte, contentType string) error
+	Get(key string) ([]byte, string, error)
+	Delete(key string) error
+}
+
+var (
+	fileStorage     FileStorage
+	fileStorageOnce sync.Once
+	fileStorageMu   sync.RWMutex
+)
+
+// GetFileStorage returns the configured storage, building it on first use
+func GetFileStorage() FileStorage {
+	fileStorageOnce.Do(func() {
+		s, err := newFileStorageFromEnv()
+		if err != nil {
+			logging.Logger.Sugar().Errorf("Invalid storage config, falling back to local: %v", err)
+			s, _ = NewLocalStorage(localStorageDir())
+		}
+		fileStorageMu.Lock()
+		if fileStorage == nil {
+			fileStorage = s
+		}
+		fileStorageMu.Unlock()
+	})
+
+	fileStorageMu.RLock()
+	defer fileStorageMu.RUnlock()
+	return fileStorage
+}
+
+// SetFileStorage overrides the configured storage (e.g. for tools/tests).
+func SetFileStorage(s FileStorage) {
+	fileStorageOnce.Do(func() {})
+	fileStorageMu.Lock()
+	fileStorage = s
+	fileStorageMu.Unlock()
+}
+
+func newFileStorageFromEnv() (FileStorage, error) {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("STORAGE_BACKEND"))) {
+	case "", StorageBackendLocal:
+		return NewLocalStorage(localStorageDir())
+	case StorageBackendS3:
+		return newS3StorageFromEnv()
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q", os.Getenv("STORAGE_BACKEND"))
+	}
+}
+
+func localStorageDir() string {
+	if dir := os.Getenv("STORAGE_DIR"); dir != "" {
+		return dir
+	}
+	return "uploads"
+}
+
+// validStorageKey rejects keys that could escape the storage root
+func validStorageKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// ---------------- Local filesystem ----------------
+
+// LocalStorage writes files below Dir on the local filesystem
+type LocalStorage struct {
+	Dir string
+}
+
+// NewLocalStorage creates dir if needed
+func NewLocalStorage(dir string) (*LocalStorage, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create storage dir %s: %w", dir, err)
+	}
+	return &LocalStorage{Dir: dir}, nil
+}
+
+func (s *LocalStorage) Name() string { return StorageBackendLocal }
+
+func (s *LocalStorage) path(key string) (string, error) {
+	if !validStorageKey(key) {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return filepath.Join(s.Dir, filepath.FromSlash(key)), nil
+}
+
+func (s *LocalStorage) Put(key string, data []byte, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create storage dir: %w", err)
+	}
+	// Write to a temp file and rename so readers never see a partial file
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *LocalStorage) Get(key string) ([]byte, string, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, "", ErrFileNotFound
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read file: %w", err)
+	}
+	return data, http.DetectContentType(data), nil
+}
+
+func (s *LocalStorage) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
+
+// ---------------- S3-compatible ----------------
+
+// S3Storage stores files in an S3-compatible bucket (AWS S3, MinIO, ...)
+// using path-style requests signed with AWS Signature Version 4.
+type S3Storage struct {
+	Endpoint  string // e.g. http://localhost:9000 or https://s3.ap-southeast-1.amazonaws.com
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	client    *http.Client
+}
+
+func newS3StorageFromEnv() (*S3Storage, error) {
+	s := &S3Storage{
+		Endpoint:  strings.TrimRight(os.Getenv("S3_ENDPOINT"), "/"),
+		Region:    os.Getenv("S3_REGION"),
+		Bucket:    os.Getenv("S3_BUCKET"),
+		AccessKey: os.Getenv("S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("S3_SECRET_KEY"),
+	}
+	if s.Region == "" {
+		s.Region = "us-east-1"
+	}
+	if s.Endpoint == "" || s.Bucket == "" || s.AccessKey == "" || s.SecretKey == "" {
+		return nil, errors.New("S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY and S3_SECRET_KEY are required")
+	}
+	if _, err := url.Parse(s.Endpoint); err != nil {
+		return nil, fmt.Errorf("invalid S3_ENDPOINT: %w", err)
+	}
+	s.client = &http.Client{Timeout: 30 * time.Second}
+	return s, nil
+}
+
+func (s *S3Storage) Name() string { return StorageBackendS3 }
+
+func (s *S3Storage) Put(key string, data []byte, contentType string) error {
+	resp, err := s.do(http.MethodPut, key, data, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s.responseError("upload", resp)
+	}
+	return nil
+}
+
+func (s *S3Storage) Get(key string) ([]byte, string, error) {
+	resp, err := s.do(http.MethodGet, key, nil, "")
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, "", ErrFileNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", s.responseError("download", resp)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read S3 object: %w", err)
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+func (s *S3Storage) Delete(key string) error {
+	resp, err := s.do(http.MethodDelete, key, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return s.responseError("delete", resp)
+	}
+	return nil
+}
+
+func (s *S3Storage) responseError(op string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("S3 %s failed: %s: %s", op, resp.Status, strings.TrimSpace(string(body)))
+}
+
+// do sends a signed path-style request for bucket/key
+func (s *S3Storage) do(method, key string, body []byte, contentType string) (*http.Response, error) {
+	if !validStorageKey(key) {
+		return nil, fmt.Errorf("invalid storage key %q", key)
+	}
+	segments := strings.Split(s.Bucket+"/"+key, "/")
+	for i, seg := range segments {
+		segments[i] = s3Escape(seg)
+	}
+	req, err := http.NewRequest(method, s.Endpoint+"/"+strings.Join(segments, "/"), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build S3 request: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	s.sign(req, body, time.Now().UTC())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("S3 request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// sign adds AWS Signature Version 4 headers to req
+func (s *S3Storage) sign(req *http.Request, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		"", // no query string
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretKey), date)
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKey, scope, signedHeaders, signature))
+}
+
+// s3Escape URI-encodes a path segment the way SigV4 expects (RFC 3986 unreserved kept)
+func s3Escape(segment string) string {
+	var b strings.Builder
+	for i := 0; i < len(segment); i++ {
+		c := segment[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,643 @@
+// services/institution_service.go
+package services
+
+import (
+	"attendance-system/connection"
+	"attendance-system/logging"
+	"attendance-system/models"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Institution hierarchy levels used in admin routes (/admin/institution/:level)
+const (
+	InstitutionColleges    = "colleges"
+	InstitutionDepartments = "departments"
+	InstitutionCourses     = "courses"
+	InstitutionSections    = "sections"
+)
+
+// defaultColleges seeds an empty hierarchy with the values the registration
+// dropdown used to hardcode. They were offered as "departments", so each one
+// becomes a college with a department of the same name.
+var defaultColleges = []string{
+	"College of Education",
+	"College of Engineering",
+	"College of Science",
+	"College of Arts and Sciences",
+	"College of Business and Management",
+	"College of Social Sciences",
+	"College of Health Sciences",
+	"College of Law",
+	"College of Agriculture",
+	"College of Medicine",
+}
+
+const upperNameWhere = "UPPER(name) = ?"
+
+// InstitutionSectionNode, InstitutionCourseNode, InstitutionDepartmentNode and
+// InstitutionCollegeNode make up the nested dropdown tree.
+type InstitutionSectionNode struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	YearLevel string `json:"year_level,omitempty"`
+}
+
+type InstitutionCourseNode struct {
+	ID       uint                     `json:"id"`
+	Code     string                   `json:"code"`
+	Name     string                   `json:"name"`
+	Sections []InstitutionSectionNode `json:"sections"`
+}
+
+type InstitutionDepartmentNode struct {
+	ID      uint                    `json:"id"`
+	Code    string                  `json:"code,omitempty"`
+	Name    string                  `json:"name"`
+	Courses []InstitutionCourseNode `json:"courses"`
+}
+
+type InstitutionCollegeNode struct {
+	ID          uint                        `json:"id"`
+	Code        string                      `json:"code,omitempty"`
+	Name        string                      `json:"name"`
+	Departments []InstitutionDepartmentNode `json:"departments"`
+}
+
+// InstitutionDropdowns is served by /registration-dropdowns and
+// /events/creation-dropdowns. Departments and Sections keep the flat name
+// lists older clients expect.
+type InstitutionDropdowns struct {
+	Hierarchy   []InstitutionCollegeNode `json:"hierarchy"`
+	Colleges    []string                 `json:"colleges"`
+	Departments []string                 `json:"departments"`
+	Courses     []string                 `json:"courses"`
+	Sections    []string                 `json:"sections"`
+}
+
+// IsInstitutionLevel reports whether level names a hierarchy table
+func IsInstitutionLevel(level string) bool {
+	_, err := institutionModel(level)
+	return err == nil
+}
+
+// institutionModel returns an empty model for a hierarchy level
+func institutionModel(level string) (interface{}, error) {
+	switch level {
+	case InstitutionColleges:
+		return &models.College{}, nil
+	case InstitutionDepartments:
+		return &models.Department{}, nil
+	case InstitutionCourses:
+		return &models.Course{}, nil
+	case InstitutionSections:
+		return &models.Section{}, nil
+	}
+	return nil, fmt.Errorf("unknown institution level %q", level)
+}
+
+// ListInstitutionItems lists one level, optionally under a parent
+func ListInstitutionItems(level string, parentID uint, includeInactive bool) (interface{}, error) {
+	query := connection.DB
+	if !includeInactive {
+		query = query.Where("is_active = ?", true)
+	}
+
+	var err error
+	switch level {
+	case InstitutionColleges:
+		var items []models.College
+		err = query.Order("name").Find(&items).Error
+		return items, err
+	case InstitutionDepartments:
+		var items []models.Department
+		if parentID != 0 {
+			query = query.Where("college_id = ?", parentID)
+		}
+		err = query.Order("name").Find(&items).Error
+		return items, err
+	case InstitutionCourses:
+		var items []models.Course
+		if parentID != 0 {
+			query = query.Where("department_id = ?", parentID)
+		}
+		err = query.Order("code").Find(&items).Error
+		return items, err
+	case InstitutionSections:
+		var items []models.Section
+		if parentID != 0 {
+			query = query.Where("course_id = ?", parentID)
+		}
+		err = query.Order("name").Find(&items).Error
+		return items, err
+	}
+	return nil, fmt.Errorf("unknown institution level %q", level)
+}
+
+// ensureInstitutionParent checks the parent row exists for a level
+func ensureInstitutionParent(level string, parentID uint) error {
+	var parent interface{}
+	switch level {
+	case InstitutionDepartments:
+		parent = &models.College{}
+	case InstitutionCourses:
+		parent = &models.Department{}
+	case InstitutionSections:
+		parent = &models.Course{}
+	default:
+		return nil
+	}
+	if parentID == 0 {
+		return errors.New("parent_id is required")
+	}
+	if err := connection.DB.First(parent, parentID).Error; err != nil {
+		return errors.New("parent not found")
+	}
+	return nil
+}
+
+// CreateInstitutionItem adds a college, department, course or section
+func CreateInstitutionItem(level string, req models.InstitutionRequest) (interface{}, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	req.Code = strings.TrimSpace(req.Code)
+	if err := ensureInstitutionParent(level, req.ParentID); err != nil {
+		return nil, err
+	}
+
+	var item interface{}
+	switch level {
+	case InstitutionColleges:
+		if req.Name == "" {
+			return nil, errors.New("name is required")
+		}
+		item = &models.College{Code: req.Code, Name: req.Name, IsActive: true}
+	case InstitutionDepartments:
+		if req.Name == "" {
+			return nil, errors.New("name is required")
+		}
+		item = &models.Department{CollegeID: req.ParentID, Code: req.Code, Name: req.Name, IsActive: true}
+	case InstitutionCourses:
+		if req.Code == "" {
+			return nil, errors.New("code is required")
+		}
+		item = &models.Course{DepartmentID: req.ParentID, Code: strings.ToUpper(req.Code), Name: req.Name, IsActive: true}
+	case InstitutionSections:
+		if req.Name == "" {
+			return nil, errors.New("name is required")
+		}
+		item = &models.Section{CourseID: req.ParentID, Name: req.Name, YearLevel: req.YearLevel, IsActive: true}
+	default:
+		return nil, fmt.Errorf("unknown institution level %q", level)
+	}
+
+	if err := CreateWithoutID(item); err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return nil, errors.New("an entry with this name already exists")
+		}
+		return nil, fmt.Errorf("failed to create %s entry: %v", level, err)
+	}
+	return item, nil
+}
+
+// UpdateInstitutionItem renames, re-parents or (de)activates an entry. IDs
+// stay stable, so users and events keep pointing at the same row.
+func UpdateInstitutionItem(level string, id uint, req models.InstitutionRequest) (interface{}, error) {
+	item, err := institutionModel(level)
+	if err != nil {
+		return nil, err
+	}
+	if err := connection.DB.First(item, id).Error; err != nil {
+		return nil, errors.New("entry not found")
+	}
+
+	updates := map[string]interface{}{}
+	if name := strings.TrimSpace(req.Name); name != "" {
+		updates["name"] = name
+	}
+	if code := strings.TrimSpace(req.Code); code != "" {
+		if level == InstitutionCourses {
+			code = strings.ToUpper(code)
+		}
+		updates["code"] = code
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+	if level == InstitutionSections && req.YearLevel != "" {
+		updates["year_level"] = req.YearLevel
+	}
+	if req.ParentID != 0 {
+		if err := ensureInstitutionParent(level, req.ParentID); err != nil {
+			return nil, err
+		}
+		switch level {
+		case InstitutionDepartments:
+			updates["college_id"] = req.ParentID
+		case InstitutionCourses:
+			updates["department_id"] = req.ParentID
+		case InstitutionSections:
+			updates["course_id"] = req.ParentID
+		}
+	}
+	if len(updates) == 0 {
+		return item, nil
+	}
+
+	if err := connection.DB.Model(item).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update %s entry: %v", level, err)
+	}
+	connection.DB.First(item, id)
+	return item, nil
+}
+
+// DeactivateInstitutionItem hides an entry from dropdowns and validation
+// without deleting it, so existing references stay valid.
+func DeactivateInstitutionItem(level string, id uint) error {
+	item, err := institutionModel(level)
+	if err != nil {
+		return err
+	}
+	result := connection.DB.Model(item).Where("id = ?", id).Update("is_active", false)
+	if result.Error != nil {
+		return fmt.Errorf("failed to deactivate %s entry: %v", level, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("entry not found")
+	}
+	return nil
+}
+
+// GetInstitutionDropdowns returns the active hierarchy as a tree plus flat lists
+func GetInstitutionDropdowns() (*InstitutionDropdowns, error) {
+	var colleges []models.College
+	var departments []models.Department
+	var courses []models.Course
+	var sections []models.Section
+	// A new session per Find, so each list gets its own statement and table
+	db := connection.DB.Where("is_active = ?", true).Session(&gorm.Session{})
+	if err := db.Order("name").Find(&colleges).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Order("name").Find(&departments).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Order("code").Find(&courses).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Order("name").Find(&sections).Error; err != nil {
+		return nil, err
+	}
+
+	sectionsByCourse := make(map[uint][]InstitutionSectionNode)
+	sectionNames := make([]string, 0, len(sections))
+	seenSection := make(map[string]bool)
+	for _, s := range sections {
+		sectionsByCourse[s.CourseID] = append(sectionsByCourse[s.CourseID], InstitutionSectionNode{ID: s.ID, Name: s.Name, YearLevel: s.YearLevel})
+		if !seenSection[s.Name] {
+			seenSection[s.Name] = true
+			sectionNames = append(sectionNames, s.Name)
+		}
+	}
+
+	coursesByDept := make(map[uint][]InstitutionCourseNode)
+	courseCodes := make([]string, 0, len(courses))
+	for _, c := range courses {
+		node := InstitutionCourseNode{ID: c.ID, Code: c.Code, Name: c.Name, Sections: sectionsByCourse[c.ID]}
+		if node.Sections == nil {
+			node.Sections = []InstitutionSectionNode{}
+		}
+		coursesByDept[c.DepartmentID] = append(coursesByDept[c.DepartmentID], node)
+		courseCodes = append(courseCodes, c.Code)
+	}
+
+	deptsByCollege := make(map[uint][]InstitutionDepartmentNode)
+	deptNames := make([]string, 0, len(departments))
+	seenDept := make(map[string]bool)
+	for _, d := range departments {
+		node := InstitutionDepartmentNode{ID: d.ID, Code: d.Code, Name: d.Name, Courses: coursesByDept[d.ID]}
+		if node.Courses == nil {
+			node.Courses = []InstitutionCourseNode{}
+		}
+		deptsByCollege[d.CollegeID] = append(deptsByCollege[d.CollegeID], node)
+		if !seenDept[d.Name] {
+			seenDept[d.Name] = true
+			deptNames = append(deptNames, d.Name)
+		}
+	}
+
+	out := &InstitutionDropdowns{
+		Hierarchy:   make([]InstitutionCollegeNode, 0, len(colleges)),
+		Colleges:    make([]string, 0, len(colleges)),
+		Departments: deptNames,
+		Courses:     courseCodes,
+		Sections:    sectionNames,
+	}
+	for _, c := range colleges {
+		node := InstitutionCollegeNode{ID: c.ID, Code: c.Code, Name: c.Name, Departments: deptsByCollege[c.ID]}
+		if node.Departments == nil {
+			node.Departments = []InstitutionDepartmentNode{}
+		}
+		out.Hierarchy = append(out.Hierarchy, node)
+		out.Colleges = append(out.Colleges, c.Name)
+	}
+	return out, nil
+}
+
+// levelConfigured reports whether a level has any active rows; validation of
+// that level is skipped until an admin has set it up.
+func levelConfigured(model interface{}) bool {
+	var count int64
+	connection.DB.Model(model).Where("is_active = ?", true).Count(&count)
+	return count > 0
+}
+
+// ResolveInstitution validates free-text college/department/course/section
+// values against the hierarchy, replaces them with the canonical names and
+// returns their IDs. Missing parents are filled in from the most specific
+// value given (e.g. a course implies its department and college).
+func ResolveInstitution(college, department, course, section *string) (models.InstitutionRefs, error) {
+	var refs models.InstitutionRefs
+	*college = strings.TrimSpace(*college)
+	*department = strings.TrimSpace(*department)
+	*course = strings.TrimSpace(*course)
+	*section = strings.TrimSpace(*section)
+
+	active := connection.DB.Where("is_active = ?", true).Session(&gorm.Session{})
+
+	// Course (most specific level with a globally unique code)
+	var resolvedCourse *models.Course
+	if *course != "" && levelConfigured(&models.Course{}) {
+		var c models.Course
+		upper := strings.ToUpper(*course)
+		if err := active.Where("UPPER(code) = ? OR UPPER(name) = ?", upper, upper).First(&c).Error; err != nil {
+			return refs, fmt.Errorf("unknown course %q", *course)
+		}
+		*course = c.Code
+		refs.CourseID = &c.ID
+		resolvedCourse = &c
+	}
+
+	// Department: must match the course's department when both are known
+	var resolvedDept *models.Department
+	if resolvedCourse != nil {
+		var d models.Department
+		if err := connection.DB.First(&d, resolvedCourse.DepartmentID).Error; err == nil {
+			if *department != "" && !strings.EqualFold(*department, d.Name) {
+				return refs, fmt.Errorf("course %s does not belong to department %q", *course, *department)
+			}
+			resolvedDept = &d
+		}
+	} else if *department != "" && levelConfigured(&models.Department{}) {
+		var d models.Department
+		query := connection.DB.Where("departments.is_active = ? AND UPPER(departments.name) = ?", true, strings.ToUpper(*department))
+		if *college != "" {
+			query = query.Joins("JOIN colleges ON colleges.id = departments.college_id").
+				Where("UPPER(colleges.name) = ?", strings.ToUpper(*college))
+		}
+		if err := query.First(&d).Error; err != nil {
+			return refs, fmt.Errorf("unknown department %q", *department)
+		}
+		resolvedDept = &d
+	}
+	if resolvedDept != nil {
+		*department = resolvedDept.Name
+		refs.DepartmentID = &resolvedDept.ID
+	}
+
+	// College: must match the department's college when both are known
+	if resolvedDept != nil {
+		var c models.College
+		if err := connection.DB.First(&c, resolvedDept.CollegeID).Error; err == nil {
+			if *college != "" && !strings.EqualFold(*college, c.Name) {
+				return refs, fmt.Errorf("department %q does not belong to college %q", *department, *college)
+			}
+			*college = c.Name
+			refs.CollegeID = &c.ID
+		}
+	} else if *college != "" && levelConfigured(&models.College{}) {
+		var c models.College
+		if err := active.Where(upperNameWhere, strings.ToUpper(*college)).First(&c).Error; err != nil {
+			return refs, fmt.Errorf("unknown college %q", *college)
+		}
+		*college = c.Name
+		refs.CollegeID = &c.ID
+	}
+
+	// Section: looked up within the course when known
+	if *section != "" && levelConfigured(&models.Section{}) {
+		var s models.Section
+		query := connection.DB.Where("is_active = ? AND UPPER(name) = ?", true, strings.ToUpper(*section))
+		if resolvedCourse != nil {
+			query = query.Where("course_id = ?", resolvedCourse.ID)
+		}
+		if err := query.First(&s).Error; err != nil {
+			return refs, fmt.Errorf("unknown section %q", *section)
+		}
+		*section = s.Name
+		if resolvedCourse != nil {
+			refs.SectionID = &s.ID
+		}
+	}
+
+	return refs, nil
+}
+
+// LookupInstitutionRefs returns the hierarchy IDs for already-validated values,
+// leaving unknown levels unset.
+func LookupInstitutionRefs(college, department, course, section string) models.InstitutionRefs {
+	refs, _ := ResolveInstitution(&college, &department, &course, &section)
+	return refs
+}
+
+// resolveEventInstitution validates an event's audience fields and course tags
+// and links the event to the hierarchy.
+func resolveEventInstitution(event *models.Event) error {
+	refs, err := ResolveInstitution(&event.College, &event.Department, &event.Course, &event.Section)
+	if err != nil {
+		return err
+	}
+	event.InstitutionRefs = refs
+
+	courses, err := ValidateCourseCodes(parseTaggedCoursesCSV(event.TaggedCoursesCSV))
+	if err != nil {
+		return err
+	}
+	if len(courses) > 0 {
+		event.TaggedCoursesCSV = strings.Join(courses, ",")
+		event.TaggedCourses = courses
+	}
+	return nil
+}
+
+// ValidateCourseCodes checks event course tags against the course list and
+// returns them in canonical form.
+func ValidateCourseCodes(codes []string) ([]string, error) {
+	if len(codes) == 0 || !levelConfigured(&models.Course{}) {
+		return codes, nil
+	}
+	out := make([]string, 0, len(codes))
+	for _, code := range codes {
+		var c models.Course
+		upper := strings.ToUpper(strings.TrimSpace(code))
+		if err := connection.DB.Where("is_active = ? AND (UPPER(code) = ? OR UPPER(name) = ?)", true, upper, upper).First(&c).Error; err != nil {
+			return nil, fmt.Errorf("unknown course %q", code)
+		}
+		out = append(out, c.Code)
+	}
+	return out, nil
+}
+
+// MigrateInstitutionHierarchy seeds the hierarchy and maps existing free-text
+// college/department/course/section values on users and events to rows,
+// creating rows for values that are not known yet. Already-mapped rows
+// (college_id set) are skipped, so it is safe to run on every start.
+func MigrateInstitutionHierarchy() {
+	db := connection.DB
+
+	var colleges int64
+	db.Model(&models.College{}).Count(&colleges)
+	if colleges == 0 {
+		for _, name := range defaultColleges {
+			if _, _, err := findOrCreateDepartment(db, name, name); err != nil {
+				logging.Logger.Error("Failed to seed institution hierarchy", zap.String("college", name), zap.Error(err))
+			}
+		}
+	}
+
+	mapped := 0
+	for _, table := range []string{"users", "events"} {
+		var tuples []struct {
+			College    string
+			Department string
+			Course     string
+			Section    string
+		}
+		if err := db.Table(table).
+			Select("DISTINCT COALESCE(college, '') AS college, COALESCE(department, '') AS department, COALESCE(course, '') AS course, COALESCE(section, '') AS section").
+			Where("college_id IS NULL").
+			Scan(&tuples).Error; err != nil {
+			logging.Logger.Error("Failed to read institution values", zap.String("table", table), zap.Error(err))
+			continue
+		}
+
+		for _, t := range tuples {
+			refs, err := mapLegacyInstitution(db, t.College, t.Department, t.Course, t.Section)
+			if err != nil {
+				logging.Logger.Warn("Failed to map institution values", zap.String("table", table), zap.Error(err))
+				continue
+			}
+			if refs.CollegeID == nil {
+				continue
+			}
+			result := db.Table(table).
+				Where("college_id IS NULL AND COALESCE(college, '') = ? AND COALESCE(department, '') = ? AND COALESCE(course, '') = ? AND COALESCE(section, '') = ?",
+					t.College, t.Department, t.Course, t.Section).
+				Updates(map[string]interface{}{
+					"college_id":    refs.CollegeID,
+					"department_id": refs.DepartmentID,
+					"course_id":     refs.CourseID,
+					"section_id":    refs.SectionID,
+				})
+			mapped += int(result.RowsAffected)
+		}
+	}
+
+	if mapped > 0 {
+		logging.Logger.Info("Mapped institution values to hierarchy", zap.Int("rows", mapped))
+	}
+}
+
+// mapLegacyInstitution finds or creates hierarchy rows for one free-text
+// tuple. Older data stored colleges in the department field, so a missing
+// college falls back to the department name and vice versa.
+func mapLegacyInstitution(db *gorm.DB, college, department, course, section string) (models.InstitutionRefs, error) {
+	var refs models.InstitutionRefs
+	college = strings.TrimSpace(college)
+	department = strings.TrimSpace(department)
+	course = strings.ToUpper(strings.TrimSpace(course))
+	section = strings.TrimSpace(section)
+	if college == "" && department == "" && course == "" {
+		return refs, nil
+	}
+
+	// An existing course keeps its department
+	if course != "" {
+		var c models.Course
+		if err := db.Where("UPPER(code) = ?", course).First(&c).Error; err == nil {
+			var d models.Department
+			if err := db.First(&d, c.DepartmentID).Error; err != nil {
+				return refs, err
+			}
+			refs.CollegeID, refs.DepartmentID, refs.CourseID = &d.CollegeID, &d.ID, &c.ID
+			return mapLegacySection(db, refs, c.ID, section)
+		}
+	}
+
+	collegeName, deptName := college, department
+	if collegeName == "" {
+		collegeName = department
+	}
+	if collegeName == "" {
+		collegeName = "Unassigned"
+	}
+	if deptName == "" {
+		deptName = collegeName
+	}
+
+	collegeRow, deptRow, err := findOrCreateDepartment(db, collegeName, deptName)
+	if err != nil {
+		return refs, err
+	}
+	refs.CollegeID, refs.DepartmentID = &collegeRow.ID, &deptRow.ID
+	if course == "" {
+		return refs, nil
+	}
+
+	c := models.Course{DepartmentID: deptRow.ID, Code: course, Name: course, IsActive: true}
+	if err := db.Omit("id").Create(&c).Error; err != nil {
+		return refs, err
+	}
+	refs.CourseID = &c.ID
+	return mapLegacySection(db, refs, c.ID, section)
+}
+
+func mapLegacySection(db *gorm.DB, refs models.InstitutionRefs, courseID uint, section string) (models.InstitutionRefs, error) {
+	if section == "" {
+		return refs, nil
+	}
+	var s models.Section
+	err := db.Where("course_id = ? AND UPPER(name) = ?", courseID, strings.ToUpper(section)).First(&s).Error
+	if err != nil {
+		s = models.Section{CourseID: courseID, Name: section, IsActive: true}
+		if err := db.Omit("id").Create(&s).Error; err != nil {
+			return refs, err
+		}
+	}
+	refs.SectionID = &s.ID
+	return refs, nil
+}
+
+// findOrCreateDepartment returns the college and department rows with the
+// given names, creating them if needed.
+func findOrCreateDepartment(db *gorm.DB, collegeName, deptName string) (*models.College, *models.Department, error) {
+	var c models.College
+	if err := db.Where(upperNameWhere, strings.ToUpper(collegeName)).First(&c).Error; err != nil {
+		c = models.College{Name: collegeName, IsActive: true}
+		if err := db.Omit("id").Create(&c).Error; err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var d models.Department
+	if err := db.Where("college_id = ? AND UPPER(name) = ?", c.ID, strings.ToUpper(deptName)).First(&d).Error; err != nil {
+		d = models.Department{CollegeID: c.ID, Name: deptName, IsActive: true}
+		if err := db.Omit("id").Create(&d).Error; err != nil {
+			return nil, nil, err
+		}
+	}
+	return &c, &d, nil
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"attendance-system/connection"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunDB swaps connection.DB for a Postgres dialect that builds SQL without
+// a server and returns every generated query statement in order
+func dryRunDB(t *testing.T) *[]*gorm.Statement {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost dbname=test"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("open dry-run db: %v", err)
+	}
+	var statements []*gorm.Statement
+	db.Callback().Query().After("gorm:query").Register("test:capture", func(tx *gorm.DB) {
+		statements = append(statements, tx.Statement)
+	})
+
+	previous := connection.DB
+	connection.DB = db
+	t.Cleanup(func() { connection.DB = previous })
+	return &statements
+}
+
+func TestGetInstitutionDropdownsQueriesEachTable(t *testing.T) {
+	statements := dryRunDB(t)
+	if _, err := GetInstitutionDropdowns(); err != nil {
+		t.Fatalf("GetInstitutionDropdowns: %v", err)
+	}
+
+	want := []string{"colleges", "departments", "courses", "sections"}
+	if len(*statements) != len(want) {
+		t.Fatalf("got %d queries, want %d", len(*statements), len(want))
+	}
+	for i, stmt := range *statements {
+		sql := stmt.SQL.String()
+		if stmt.Table != want[i] || !strings.Contains(sql, `FROM "`+want[i]+`"`) {
+			t.Errorf("query %d: got table %q (%s), want %q", i, stmt.Table, sql, want[i])
+		}
+		if !strings.Contains(sql, "is_active") {
+			t.Errorf("query %d does not filter active rows: %s", i, sql)
+		}
+		if strings.Count(sql, "ORDER BY") != 1 || strings.Contains(sql, "ORDER BY name,") || strings.Contains(sql, "ORDER BY code,") {
+			t.Errorf("query %d carries ordering from another query: %s", i, sql)
+		}
+	}
+}
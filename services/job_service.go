@@ -0,0 +1,374 @@
+// services/job_service.go
+package services
+
+import (
+	"attendance-system/connection"
+	"attendance-system/logging"
+	"attendance-system/models"
+	"attendance-system/utils"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// JobFunc runs a background job and returns a short result summary
+type JobFunc func(ctx context.Context) (string, error)
+
+// Job is a registered background job with a cron schedule
+type Job struct {
+	Name        string
+	Description string
+	Schedule    string
+	Run         JobFunc
+
+	cron *utils.CronSchedule
+}
+
+// JobInfo describes a registered job for the admin API
+type JobInfo struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Schedule    string         `json:"schedule"`
+	NextRunAt   time.Time      `json:"next_run_at"`
+	LastRun     *models.JobRun `json:"last_run,omitempty"`
+}
+
+var (
+	ErrJobNotFound       = errors.New("job not found")
+	ErrJobAlreadyRunning = errors.New("job is already running")
+)
+
+// schedulerLeaderLockKey is the advisory lock held by the instance that runs
+// scheduled jobs. Other instances only serve manual triggers.
+const schedulerLeaderLockKey int64 = 0x61747464_6a6f6273 // "attdjobs"
+
+var (
+	jobsMu sync.RWMutex
+	jobs   = make(map[string]*Job)
+
+	instanceID = buildInstanceID()
+
+	leaderMu   sync.Mutex
+	leaderConn *sql.Conn
+)
+
+func buildInstanceID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// RegisterJob adds a job to the scheduler. It panics on an invalid schedule or
+// duplicate name since jobs are registered at startup.
+func RegisterJob(job Job) {
+	cron, err := utils.ParseCron(job.Schedule)
+	if err != nil {
+		panic(fmt.Sprintf("job %s: %v", job.Name, err))
+	}
+	job.cron = cron
+
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+	if _, exists := jobs[job.Name]; exists {
+		panic(fmt.Sprintf("job %s registered twice", job.Name))
+	}
+	jobs[job.Name] = &job
+}
+
+func getJob(name string) (*Job, error) {
+	jobsMu.RLock()
+	defer jobsMu.RUnlock()
+	job, ok := jobs[name]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return job, nil
+}
+
+// StartJobScheduler runs forever, checking once per minute. Only the instance
+// holding the Postgres advisory leader lock runs scheduled jobs, so scaling to
+// several replicas does not run the same job twice.
+func StartJobScheduler() {
+	for {
+		now := time.Now()
+		if ensureSchedulerLeader() {
+			failStaleJobRuns()
+			runDueJobs(now)
+		}
+		// Sleep until the start of the next minute
+		next := time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute()+1, 0, 0, now.Location())
+		time.Sleep(time.Until(next))
+	}
+}
+
+func runDueJobs(now time.Time) {
+	jobsMu.RLock()
+	var due []*Job
+	for _, job := range jobs {
+		if job.cron.Matches(now) {
+			due = append(due, job)
+		}
+	}
+	jobsMu.RUnlock()
+
+	for _, job := range due {
+		go func(job *Job) {
+			if _, err := startJobRun(job, models.JobTriggerSchedule, ""); err != nil && !errors.Is(err, ErrJobAlreadyRunning) {
+				logging.Logger.Error("Failed to start scheduled job", zap.String("job", job.Name), zap.Error(err))
+			}
+		}(job)
+	}
+}
+
+// failStaleJobRuns marks runs left "running" by an instance that died as
+// failed. A live run always holds its job's advisory lock, and Postgres drops
+// the lock with the connection, so a running row whose lock is free is stale.
+// Holding the lock while updating also keeps a new run from starting meanwhile.
+func failStaleJobRuns() {
+	var names []string
+	if err := connection.DB.Model(&models.JobRun{}).Where("status = ?", models.JobRunStatusRunning).
+		Distinct().Pluck("job_name", &names).Error; err != nil || len(names) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	conn, err := dedicatedConn(ctx)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	for _, name := range names {
+		lockKey := jobLockKey(name)
+		var locked bool
+		if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", lockKey).Scan(&locked); err != nil || !locked {
+			continue
+		}
+		now := time.Now()
+		result := connection.DB.Model(&models.JobRun{}).
+			Where("job_name = ? AND status = ?", name, models.JobRunStatusRunning).
+			Updates(map[string]interface{}{
+				"status":      models.JobRunStatusFailed,
+				"error":       "run was interrupted: its instance stopped before the job finished",
+				"finished_at": now,
+			})
+		conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", lockKey)
+		if result.Error != nil {
+			logging.Logger.Error("Failed to mark stale job runs", zap.String("job", name), zap.Error(result.Error))
+		} else if result.RowsAffected > 0 {
+			logging.Logger.Warn("Marked interrupted job runs as failed",
+				zap.String("job", name), zap.Int64("runs", result.RowsAffected))
+		}
+	}
+}
+
+// ensureSchedulerLeader acquires or re-checks the leader advisory lock. The
+// lock lives on a dedicated connection; if that connection dies Postgres
+// releases the lock and another instance takes over.
+func ensureSchedulerLeader() bool {
+	leaderMu.Lock()
+	defer leaderMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if leaderConn != nil {
+		if err := leaderConn.PingContext(ctx); err == nil {
+			return true
+		}
+		logging.Logger.Warn("Lost job scheduler leadership", zap.String("instance_id", instanceID))
+		leaderConn.Close()
+		leaderConn = nil
+	}
+
+	conn, err := dedicatedConn(ctx)
+	if err != nil {
+		return false
+	}
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", schedulerLeaderLockKey).Scan(&locked); err != nil || !locked {
+		conn.Close()
+		return false
+	}
+
+	leaderConn = conn
+	logging.Logger.Info("Acquired job scheduler leadership", zap.String("instance_id", instanceID))
+	return true
+}
+
+// IsJobSchedulerLeader reports whether this instance runs scheduled jobs
+func IsJobSchedulerLeader() bool {
+	leaderMu.Lock()
+	defer leaderMu.Unlock()
+	return leaderConn != nil
+}
+
+func dedicatedConn(ctx context.Context) (*sql.Conn, error) {
+	sqlDB, err := connection.DB.DB()
+	if err != nil {
+		return nil, err
+	}
+	return sqlDB.Conn(ctx)
+}
+
+// jobLockKey derives a per-job advisory lock key from the job name
+func jobLockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("job:" + name))
+	return int64(h.Sum64())
+}
+
+// TriggerJob starts a job immediately on this instance (admin action)
+func TriggerJob(name, triggeredBy string) (*models.JobRun, error) {
+	job, err := getJob(name)
+	if err != nil {
+		return nil, err
+	}
+	return startJobRun(job, models.JobTriggerManual, triggeredBy)
+}
+
+// startJobRun takes the per-job advisory lock, records the run and executes
+// the job in the background. It returns ErrJobAlreadyRunning when another
+// run (on any instance) holds the lock.
+func startJobRun(job *Job, trigger, triggeredBy string) (*models.JobRun, error) {
+	ctx := context.Background()
+	conn, err := dedicatedConn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database connection: %v", err)
+	}
+
+	lockKey := jobLockKey(job.Name)
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", lockKey).Scan(&locked); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to acquire job lock: %v", err)
+	}
+	if !locked {
+		conn.Close()
+		return nil, ErrJobAlreadyRunning
+	}
+
+	run := &models.JobRun{
+		JobName:     job.Name,
+		Trigger:     trigger,
+		TriggeredBy: triggeredBy,
+		InstanceID:  instanceID,
+		Status:      models.JobRunStatusRunning,
+		StartedAt:   time.Now(),
+	}
+	if err := CreateWithoutID(run); err != nil {
+		conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", lockKey)
+		conn.Close()
+		return nil, fmt.Errorf("failed to record job run: %v", err)
+	}
+
+	started := *run
+	go func() {
+		defer conn.Close()
+		defer conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", lockKey)
+		executeJob(ctx, job, run)
+	}()
+
+	return &started, nil
+}
+
+func executeJob(ctx context.Context, job *Job, run *models.JobRun) {
+	result, err := safeRunJob(ctx, job)
+
+	finished := time.Now()
+	run.FinishedAt = &finished
+	run.DurationMS = finished.Sub(run.StartedAt).Milliseconds()
+	run.Result = result
+	if err != nil {
+		run.Status = models.JobRunStatusFailed
+		run.Error = err.Error()
+		logging.Logger.Error("Job failed", zap.String("job", job.Name), zap.Error(err))
+	} else {
+		run.Status = models.JobRunStatusSucceeded
+		logging.Logger.Info("Job finished",
+			zap.String("job", job.Name),
+			zap.String("trigger", run.Trigger),
+			zap.Int64("duration_ms", run.DurationMS),
+			zap.String("result", result),
+		)
+	}
+
+	if err := connection.DB.Save(run).Error; err != nil {
+		logging.Logger.Error("Failed to save job run", zap.String("job", job.Name), zap.Error(err))
+	}
+}
+
+// safeRunJob converts a panic inside a job into an error
+func safeRunJob(ctx context.Context, job *Job) (result string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return job.Run(ctx)
+}
+
+// ListJobs returns all registered jobs with their next and last runs
+func ListJobs() ([]JobInfo, error) {
+	jobsMu.RLock()
+	list := make([]*Job, 0, len(jobs))
+	for _, job := range jobs {
+		list = append(list, job)
+	}
+	jobsMu.RUnlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	now := time.Now()
+	infos := make([]JobInfo, 0, len(list))
+	for _, job := range list {
+		info := JobInfo{
+			Name:        job.Name,
+			Description: job.Description,
+			Schedule:    job.Schedule,
+			NextRunAt:   job.cron.Next(now),
+		}
+		var last models.JobRun
+		if err := connection.DB.Where("job_name = ?", job.Name).Order("started_at DESC").First(&last).Error; err == nil {
+			info.LastRun = &last
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// GetJobRuns returns the most recent runs of a job
+func GetJobRuns(name string, limit int) ([]models.JobRun, error) {
+	if _, err := getJob(name); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 200 {
+		limit = 200
+	}
+
+	var runs []models.JobRun
+	if err := connection.DB.Where("job_name = ?", name).Order("started_at DESC").Limit(limit).Find(&runs).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch job runs: %v", err)
+	}
+	return runs, nil
+}
+
+// DeleteOldJobRuns removes run history older than the given number of days
+func DeleteOldJobRuns(olderThanDays int) (int64, error) {
+	cutoff := time.Now().AddDate(0, 0, -olderThanDays)
+	result := connection.DB.Where("started_at < ? AND status <> ?", cutoff, models.JobRunStatusRunning).Delete(&models.JobRun{})
+	return result.RowsAffected, result.Error
+}
@@ -31,8 +31,8 @@ func init() {
 	}
 }
 
-// GenerateAccessToken creates a new JWT access token
-func GenerateAccessToken(user models.User) (string, error) {
+// GenerateAccessToken creates a new JWT access token for a login session
+func GenerateAccessToken(user models.User, sessionID string) (string, error) {
 	if jwtSecret == "" {
 		return "", ErrNoSecretKey
 	}
@@ -42,6 +42,7 @@ func GenerateAccessToken(user models.User) (string, error) {
 		StudentID: user.StudentID,
 		Email:     user.Email,
 		Role:      user.Role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenExpiry)),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -59,8 +60,9 @@ func GenerateAccessToken(user models.User) (string, error) {
 	return tokenString, nil
 }
 
-// GenerateRefreshToken creates a new JWT refresh token
-func GenerateRefreshToken(user models.User) (string, error) {
+// GenerateRefreshToken creates a new JWT refresh token with the given ID
+// (jti) in a login session
+func GenerateRefreshToken(user models.User, sessionID, tokenID string) (string, error) {
 	if jwtSecret == "" {
 		return "", ErrNoSecretKey
 	}
@@ -68,7 +70,9 @@ func GenerateRefreshToken(user models.User) (string, error) {
 	now := time.Now().UTC()
 	claims := RefreshTokenClaims{
 		StudentID: user.StudentID,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
 			ExpiresAt: jwt.NewNumericDate(now.Add(RefreshTokenExpiry)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
@@ -136,15 +140,16 @@ func VerifyRefreshToken(tokenString string) (*RefreshTokenClaims, error) {
 	return claims, nil
 }
 
-// GeneratePasswordResetToken creates a JWT token for password reset verification
-func GeneratePasswordResetToken(email, code string) (string, error) {
+// GeneratePasswordResetToken creates a JWT token for password reset verification.
+// resetID is 0 until a code has been verified.
+func GeneratePasswordResetToken(email string, resetID uint) (string, error) {
 	if jwtSecret == "" {
 		return "", ErrNoSecretKey
 	}
 
 	claims := models.PasswordResetTokenClaims{
-		Email: email,
-		Code:  code,
+		Email:   email,
+		ResetID: resetID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(models.PasswordResetTokenExpiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -188,7 +193,7 @@ func VerifyPasswordResetToken(tokenString string) (*models.PasswordResetTokenCla
 }
 
 // GenerateEmailVerificationToken creates a JWT token for email verification
-func GenerateEmailVerificationToken(email, code string) (string, error) {
+func GenerateEmailVerificationToken(email string) (string, error) {
 	if jwtSecret == "" {
 		return "", ErrNoSecretKey
 	}
@@ -196,7 +201,6 @@ func GenerateEmailVerificationToken(email, code string) (string, error) {
 	now := time.Now().UTC()
 	claims := models.EmailVerificationTokenClaims{
 		Email: email,
-		Code:  code,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(now.Add(models.EmailVerificationTokenExpiry)),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -238,3 +242,106 @@ func VerifyEmailVerificationToken(tokenString string) (*models.EmailVerification
 
 	return claims, nil
 }
+
+// GenerateReminderOptOutToken creates a JWT token for the reminder opt-out link
+func GenerateReminderOptOutToken(eventID uint, studentID string) (string, error) {
+	if jwtSecret == "" {
+		return "", ErrNoSecretKey
+	}
+
+	now := time.Now().UTC()
+	claims := models.ReminderOptOutTokenClaims{
+		EventID:   eventID,
+		StudentID: studentID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(models.ReminderOptOutTokenExpiry)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Subject:   studentID,
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(jwtSecret))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign reminder opt-out token: %w", err)
+	}
+
+	return tokenString, nil
+}
+
+// VerifyReminderOptOutToken verifies and parses a reminder opt-out token
+func VerifyReminderOptOutToken(tokenString string) (*models.ReminderOptOutTokenClaims, error) {
+	if tokenString == "" {
+		return nil, ErrInvalidToken
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &models.ReminderOptOutTokenClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(jwtSecret), nil
+	})
+
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(*models.ReminderOptOutTokenClaims)
+	if !ok || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+// GenerateTwoFactorChallengeToken creates a short-lived token for the second login step
+func GenerateTwoFactorChallengeToken(studentID, purpose string) (string, error) {
+	if jwtSecret == "" {
+		return "", ErrNoSecretKey
+	}
+
+	now := time.Now().UTC()
+	claims := models.TwoFactorChallengeClaims{
+		Purpose: purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(models.TwoFactorChallengeExpiry)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Subject:   studentID,
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(jwtSecret))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign two-factor challenge token: %w", err)
+	}
+
+	return tokenString, nil
+}
+
+// VerifyTwoFactorChallengeToken verifies a challenge token issued for purpose
+func VerifyTwoFactorChallengeToken(tokenString, purpose string) (*models.TwoFactorChallengeClaims, error) {
+	if tokenString == "" {
+		return nil, ErrInvalidToken
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &models.TwoFactorChallengeClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(jwtSecret), nil
+	})
+
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(*models.TwoFactorChallengeClaims)
+	if !ok || !token.Valid || claims.Purpose != purpose || claims.Subject == "" {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,221 @@
+// services/lockout_service.go
+package services
+
+import (
+	"attendance-system/connection"
+	"attendance-system/logging"
+	"attendance-system/models"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// authFailureRetention is how long an unlocked counter is kept after its last
+// failure; the backoff starts over once it is gone
+const authFailureRetention = 24 * time.Hour
+
+// LockoutError is returned while an identifier is locked for an action
+type LockoutError struct {
+	Until time.Time
+}
+
+func (e *LockoutError) Error() string {
+	return fmt.Sprintf("too many failed attempts; try again after %s", e.Until.UTC().Format(time.RFC3339))
+}
+
+// RetryAfterSeconds is the value for the Retry-After header
+func (e *LockoutError) RetryAfterSeconds() int {
+	if s := int(time.Until(e.Until).Seconds()) + 1; s > 0 {
+		return s
+	}
+	return 1
+}
+
+// lockoutMaxAttempts is the number of consecutive failures that locks an
+// identifier and invalidates its outstanding codes (LOCKOUT_MAX_ATTEMPTS, default 5)
+func lockoutMaxAttempts() int {
+	if n, err := strconv.Atoi(os.Getenv("LOCKOUT_MAX_ATTEMPTS")); err == nil && n > 0 {
+		return n
+	}
+	return 5
+}
+
+// lockoutDuration doubles with every lockout, from LOCKOUT_BASE_DURATION
+// (default 1m) up to LOCKOUT_MAX_DURATION (default 24h)
+func lockoutDuration(lockouts int) time.Duration {
+	base := envDuration("LOCKOUT_BASE_DURATION", time.Minute)
+	max := envDuration("LOCKOUT_MAX_DURATION", 24*time.Hour)
+	d := base
+	for i := 1; i < lockouts && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d
+}
+
+func envDuration(name string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(name)); err == nil && d > 0 {
+		return d
+	}
+	return fallback
+}
+
+// lockoutKey normalizes an identifier so "Foo@x" and "foo@x " share a counter
+func lockoutKey(identifier string) string {
+	return strings.ToLower(strings.TrimSpace(identifier))
+}
+
+// CheckLockout returns a *LockoutError while the identifier is locked for scope
+func CheckLockout(scope, identifier string) error {
+	var failure models.AuthFailure
+	err := connection.DB.Where("scope = ? AND identifier = ? AND locked_until > ?", scope, lockoutKey(identifier), time.Now()).
+		First(&failure).Error
+	if err != nil {
+		return nil
+	}
+	return &LockoutError{Until: *failure.LockedUntil}
+}
+
+// RecordAuthFailure counts a failed attempt. When it reaches the limit the
+// identifier is locked, its outstanding codes are invalidated and the account
+// owner is notified; the returned *LockoutError reports that. Other errors are
+// only logged so a failing counter never blocks the caller.
+func RecordAuthFailure(scope, identifier, ipAddress string) error {
+	key := lockoutKey(identifier)
+	if key == "" {
+		return nil
+	}
+	now := time.Now()
+	failure := models.AuthFailure{Scope: scope, Identifier: key, Failures: 1, LastFailureAt: now, LastIP: ipAddress}
+	err := connection.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "scope"}, {Name: "identifier"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"failures":        gorm.Expr("auth_failures.failures + 1"),
+			"last_failure_at": now,
+			"last_ip":         ipAddress,
+		}),
+	}).Omit("id").Create(&failure).Error
+	if err == nil {
+		err = connection.DB.Where("scope = ? AND identifier = ?", scope, key).First(&failure).Error
+	}
+	if err != nil {
+		logging.Logger.Error("Failed to record authentication failure", zap.String("scope", scope), zap.Error(err))
+		return nil
+	}
+
+	max := lockoutMaxAttempts()
+	if failure.Failures < max {
+		return nil
+	}
+
+	lockouts := failure.Lockouts + 1
+	until := now.Add(lockoutDuration(lockouts))
+	result := connection.DB.Model(&models.AuthFailure{}).
+		Where("id = ? AND failures >= ?", failure.ID, max).
+		Updates(map[string]interface{}{"failures": 0, "lockouts": lockouts, "locked_until": until})
+	if result.Error != nil || result.RowsAffected == 0 {
+		// Another request locked it first
+		return &LockoutError{Until: until}
+	}
+
+	logging.Logger.Warn("Identifier locked after repeated failures",
+		zap.String("scope", scope),
+		zap.String("identifier", key),
+		zap.Int("lockouts", lockouts),
+		zap.Time("locked_until", until),
+		zap.String("ip_address", ipAddress),
+	)
+	invalidateCodes(scope, key)
+	go LogAuditAction(AuditAccountLocked, key, key,
+		fmt.Sprintf("%s: %d failed attempts, locked until %s", scope, max, until.UTC().Format(time.RFC3339)), ipAddress)
+	go notifyAccountLocked(scope, key, until, ipAddress)
+	return &LockoutError{Until: until}
+}
+
+// ClearAuthFailures resets the counter after a successful attempt
+func ClearAuthFailures(scope, identifier string) {
+	connection.DB.Where("scope = ? AND identifier = ?", scope, lockoutKey(identifier)).Delete(&models.AuthFailure{})
+}
+
+// invalidateCodes burns the codes that were being guessed
+func invalidateCodes(scope, key string) {
+	var err error
+	switch scope {
+	case models.LockoutScopeResetCode:
+		err = connection.DB.Model(&models.PasswordReset{}).
+			Where("LOWER(email) = ? AND used = ?", key, false).
+			Update("used", true).Error
+	case models.LockoutScopeVerifyEmail:
+		err = connection.DB.Model(&models.PendingUser{}).
+			Where("LOWER(email) = ?", key).
+			Update("expires_at", time.Now()).Error
+		if err == nil {
+			// Email changes of logged-in users are counted by student ID
+			err = connection.DB.Where("LOWER(student_id) = ?", key).Delete(&models.EmailChange{}).Error
+		}
+	}
+	if err != nil {
+		logging.Logger.Error("Failed to invalidate codes after lockout", zap.String("scope", scope), zap.Error(err))
+	}
+}
+
+// notifyAccountLocked emails the owner of the locked account, if there is one
+func notifyAccountLocked(scope, key string, until time.Time, ipAddress string) {
+	var user models.User
+	if err := connection.DB.Where("LOWER(student_id) = ? OR LOWER(email) = ?", key, key).First(&user).Error; err != nil {
+		return
+	}
+	if user.IsServiceAccount || user.Email == "" {
+		return
+	}
+	data := map[string]interface{}{
+		"Scope":     scope,
+		"Until":     until.Format("January 2, 2006 3:04 PM MST"),
+		"IPAddress": ipAddress,
+	}
+	if err := SendTemplatedEmail(user.Email, EmailTemplateAccountLocked, user.Locale, data); err != nil {
+		logging.Logger.Warn("Failed to send account locked email", zap.String("student_id", user.StudentID), zap.Error(err))
+	}
+}
+
+// ListLockouts returns the identifiers that are currently locked
+func ListLockouts() ([]models.AuthFailure, error) {
+	var failures []models.AuthFailure
+	if err := connection.DB.Where("locked_until > ?", time.Now()).Order("locked_until DESC").Find(&failures).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch lockouts: %v", err)
+	}
+	return failures, nil
+}
+
+// UnlockAccount clears every counter and lockout of a user (by student ID and email)
+func UnlockAccount(studentID, actor, ipAddress string) (int64, error) {
+	var user models.User
+	if err := GetUserByStudentID(studentID, &user); err != nil {
+		return 0, errors.New(models.ErrUserNotFound)
+	}
+	result := connection.DB.Where("identifier IN ?", []string{lockoutKey(user.StudentID), lockoutKey(user.Email)}).
+		Delete(&models.AuthFailure{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to unlock account: %v", result.Error)
+	}
+
+	go LogAuditAction(AuditAccountUnlocked, actor, user.StudentID, fmt.Sprintf("%d counters cleared", result.RowsAffected), ipAddress)
+	return result.RowsAffected, nil
+}
+
+// DeleteStaleAuthFailures removes unlocked counters with no recent failures
+func DeleteStaleAuthFailures() (int64, error) {
+	now := time.Now()
+	result := connection.DB.Where("(locked_until IS NULL OR locked_until < ?) AND last_failure_at < ?",
+		now, now.Add(-authFailureRetention)).Delete(&models.AuthFailure{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,278 @@
+// services/mail_transport.go
+package services
+
+import (
+	"attendance-system/logging"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/gomail.v2"
+)
+
+// Mail transport names accepted by MAIL_TRANSPORT
+const (
+	MailTransportSMTP   = "smtp"
+	MailTransportFile   = "file"
+	MailTransportMemory = "memory"
+)
+
+// devMailboxLimit caps how many messages the in-memory mailbox keeps
+const devMailboxLimit = 500
+
+// MailMessage is a single outgoing email handed to a MailTransport
+type MailMessage struct {
+	ID       string    `json:"id"`
+	From     string    `json:"from"`
+	To       string    `json:"to"`
+	Subject  string    `json:"subject"`
+	TextBody string    `json:"text_body"`
+	HTMLBody string    `json:"html_body"`
+	SentAt   time.Time `json:"sent_at"`
+}
+
+// MailTransport delivers outgoing email. Implementations are selected by the
+// MAIL_TRANSPORT environment variable (smtp, file or memory).
+type MailTransport interface {
+	Name() string
+	Send(msg *MailMessage) error
+}
+
+var (
+	mailTransport     MailTransport
+	mailTransportOnce sync.Once
+	mailTransportMu   sync.RWMutex
+)
+
+// GetMailTransport returns the configured transport, building it on first use
+// so that .env values loaded in main are honoured.
+func GetMailTransport() MailTransport {
+	mailTransportOnce.Do(func() {
+		t, err := newMailTransportFromEnv()
+		if err != nil {
+			logging.Logger.Sugar().Errorf("Invalid mail transport config, falling back to smtp: %v", err)
+			t = newSMTPTransportFromEnv()
+		}
+		mailTransportMu.Lock()
+		if mailTransport == nil {
+			mailTransport = t
+		}
+		mailTransportMu.Unlock()
+	})
+
+	mailTransportMu.RLock()
+	defer mailTransportMu.RUnlock()
+	return mailTransport
+}
+
+// SetMailTransport overrides the configured transport (e.g. for tools/tests).
+func SetMailTransport(t MailTransport) {
+	mailTransportOnce.Do(func() {})
+	mailTransportMu.Lock()
+	mailTransport = t
+	mailTransportMu.Unlock()
+}
+
+func newMailTransportFromEnv() (MailTransport, error) {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("MAIL_TRANSPORT"))) {
+	case "", MailTransportSMTP:
+		return newSMTPTransportFromEnv(), nil
+	case MailTransportFile:
+		dir := os.Getenv("MAIL_DIR")
+		if dir == "" {
+			dir = "mail"
+		}
+		return NewFileTransport(dir)
+	case MailTransportMemory:
+		return NewMemoryTransport(devMailboxLimit), nil
+	default:
+		return nil, fmt.Errorf("unknown MAIL_TRANSPORT %q", os.Getenv("MAIL_TRANSPORT"))
+	}
+}
+
+// mailFromAddress returns the sender address for outgoing mail
+func mailFromAddress() string {
+	if from := os.Getenv("MAIL_FROM"); from != "" {
+		return from
+	}
+	return os.Getenv("SMTP_USER")
+}
+
+// buildGomailMessage converts a MailMessage into a multipart gomail message
+func buildGomailMessage(msg *MailMessage) *gomail.Message {
+	m := gomail.NewMessage()
+	m.SetHeader("From", msg.From)
+	m.SetHeader("To", msg.To)
+	m.SetHeader("Subject", msg.Subject)
+	m.SetDateHeader("Date", msg.SentAt)
+
+	// Set plain text as primary body and add HTML as alternative
+	m.SetBody("text/plain", msg.TextBody)
+	if msg.HTMLBody != "" {
+		m.AddAlternative("text/html", msg.HTMLBody)
+	}
+	return m
+}
+
+// ---------------- SMTP ----------------
+
+// SMTPTransport sends mail through an SMTP relay
+type SMTPTransport struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+}
+
+func newSMTPTransportFromEnv() *SMTPTransport {
+	return &SMTPTransport{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     getSMTPPort(),
+		Username: os.Getenv("SMTP_USER"),
+		Password: os.Getenv("SMTP_PASS"),
+	}
+}
+
+func (t *SMTPTransport) Name() string { return MailTransportSMTP }
+
+func (t *SMTPTransport) Send(msg *MailMessage) error {
+	d := gomail.NewDialer(t.Host, t.Port, t.Username, t.Password)
+	d.LocalName = "attendance-system"
+	return d.DialAndSend(buildGomailMessage(msg))
+}
+
+// ---------------- File / maildir ----------------
+
+// FileTransport writes each message as an .eml file into a maildir layout
+// (tmp/, new/, cur/) so it can be opened with any mail client.
+type FileTransport struct {
+	Dir string
+	mu  sync.Mutex
+	seq uint64
+}
+
+// NewFileTransport creates the maildir folders under dir
+func NewFileTransport(dir string) (*FileTransport, error) {
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create maildir %s: %w", dir, err)
+		}
+	}
+	return &FileTransport{Dir: dir}, nil
+}
+
+func (t *FileTransport) Name() string { return MailTransportFile }
+
+func (t *FileTransport) Send(msg *MailMessage) error {
+	t.mu.Lock()
+	t.seq++
+	name := fmt.Sprintf("%d.%d_%d.attendance-system.eml", msg.SentAt.Unix(), os.Getpid(), t.seq)
+	t.mu.Unlock()
+
+	// Write to tmp/ first, then move into new/ (maildir delivery)
+	tmpPath := filepath.Join(t.Dir, "tmp", name)
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create mail file: %w", err)
+	}
+	if _, err := buildGomailMessage(msg).WriteTo(f); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write mail file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write mail file: %w", err)
+	}
+	return os.Rename(tmpPath, filepath.Join(t.Dir, "new", name))
+}
+
+// ---------------- In-memory dev mailbox ----------------
+
+// MemoryTransport keeps sent messages in memory for local development and QA.
+// Only the most recent `limit` messages are retained.
+type MemoryTransport struct {
+	mu       sync.RWMutex
+	limit    int
+	seq      uint64
+	messages []MailMessage
+}
+
+// NewMemoryTransport returns an empty in-memory mailbox
+func NewMemoryTransport(limit int) *MemoryTransport {
+	if limit <= 0 {
+		limit = devMailboxLimit
+	}
+	return &MemoryTransport{limit: limit}
+}
+
+func (t *MemoryTransport) Name() string { return MailTransportMemory }
+
+func (t *MemoryTransport) Send(msg *MailMessage) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.seq++
+	stored := *msg
+	stored.ID = fmt.Sprintf("%d", t.seq)
+	msg.ID = stored.ID
+
+	t.messages = append(t.messages, stored)
+	if len(t.messages) > t.limit {
+		t.messages = t.messages[len(t.messages)-t.limit:]
+	}
+	return nil
+}
+
+// Messages returns captured messages newest first, optionally filtered by recipient
+func (t *MemoryTransport) Messages(to string) []MailMessage {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	to = strings.ToLower(strings.TrimSpace(to))
+	out := make([]MailMessage, 0, len(t.messages))
+	for i := len(t.messages) - 1; i >= 0; i-- {
+		if to != "" && strings.ToLower(t.messages[i].To) != to {
+			continue
+		}
+		out = append(out, t.messages[i])
+	}
+	return out
+}
+
+// Message returns a single captured message by ID
+func (t *MemoryTransport) Message(id string) (*MailMessage, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for i := range t.messages {
+		if t.messages[i].ID == id {
+			m := t.messages[i]
+			return &m, nil
+		}
+	}
+	return nil, errors.New("message not found")
+}
+
+// Clear removes all captured messages
+func (t *MemoryTransport) Clear() {
+	t.mu.Lock()
+	t.messages = nil
+	t.mu.Unlock()
+}
+
+// DevMailbox returns the in-memory mailbox when it is the active transport
+// and APP_ENV is explicitly development; otherwise nil.
+func DevMailbox() *MemoryTransport {
+	if !strings.EqualFold(os.Getenv("APP_ENV"), "development") {
+		return nil
+	}
+	if mt, ok := GetMailTransport().(*MemoryTransport); ok {
+		return mt
+	}
+	return nil
+}
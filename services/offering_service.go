@@ -0,0 +1,455 @@
+// services/offering_service.go
+package services
+
+import (
+	"attendance-system/connection"
+	"attendance-system/logging"
+	"attendance-system/models"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrOfferingNotFound is returned when an offering does not exist or is not visible
+var ErrOfferingNotFound = errors.New("class offering not found")
+
+const offeringWhere = "offering_id = ?"
+
+// canManageOffering: the assigned faculty or an admin over them
+func canManageOffering(offering *models.ClassOffering, user models.User) bool {
+	return offering.FacultyID == user.StudentID || administersStudentID(user, offering.FacultyID)
+}
+
+// getManagedOffering loads an offering the user may manage
+func getManagedOffering(offeringID uint, user models.User) (*models.ClassOffering, error) {
+	var offering models.ClassOffering
+	if err := connection.DB.First(&offering, offeringID).Error; err != nil {
+		return nil, ErrOfferingNotFound
+	}
+	if !canManageOffering(&offering, user) {
+		return nil, errors.New("unauthorized: only the assigned faculty or an admin can manage this offering")
+	}
+	return &offering, nil
+}
+
+// applyOfferingRequest copies non-empty fields and validates the faculty and term
+func applyOfferingRequest(offering *models.ClassOffering, req models.ClassOfferingRequest, user models.User) error {
+	if code := strings.ToUpper(strings.TrimSpace(req.SubjectCode)); code != "" {
+		offering.SubjectCode = code
+	}
+	if req.SubjectTitle != "" {
+		offering.SubjectTitle = strings.TrimSpace(req.SubjectTitle)
+	}
+	if req.Section != "" {
+		offering.Section = strings.TrimSpace(req.Section)
+	}
+	if req.Course != "" {
+		offering.Course = strings.ToUpper(strings.TrimSpace(req.Course))
+	}
+	if req.YearLevel != "" {
+		offering.YearLevel = req.YearLevel
+	}
+	if req.IsActive != nil {
+		offering.IsActive = *req.IsActive
+	}
+
+	if req.TermID != 0 {
+		var term models.AcademicTerm
+		if err := connection.DB.First(&term, req.TermID).Error; err != nil {
+			return ErrTermNotFound
+		}
+		offering.TermID = &term.ID
+	}
+
+	if req.FacultyID != "" && req.FacultyID != offering.FacultyID {
+		var faculty models.User
+		if err := connection.DB.Where(studentWhere, req.FacultyID).First(&faculty).Error; err != nil {
+			return errors.New("faculty not found")
+		}
+		if !administers(user, faculty) {
+			return errors.New("only an admin over the faculty member's department can assign them an offering")
+		}
+		if faculty.Role == models.RoleStudent {
+			return errors.New("an offering must be assigned to faculty or an admin")
+		}
+		offering.FacultyID = faculty.StudentID
+	}
+
+	if offering.SubjectCode == "" {
+		return errors.New("subject_code is required")
+	}
+	return nil
+}
+
+// CreateClassOffering adds an offering; faculty are assigned to their own
+func CreateClassOffering(req models.ClassOfferingRequest, user models.User) (*models.ClassOffering, error) {
+	offering := &models.ClassOffering{FacultyID: user.StudentID, IsActive: true}
+	if req.TermID == 0 {
+		if term, err := GetActiveTerm(); err == nil {
+			req.TermID = term.ID
+		}
+	}
+	if err := applyOfferingRequest(offering, req, user); err != nil {
+		return nil, err
+	}
+	if err := CreateWithoutID(offering); err != nil {
+		return nil, fmt.Errorf("failed to create class offering: %v", err)
+	}
+	return offering, nil
+}
+
+// ListClassOfferings returns the user's offerings (their department's for
+// department admins, all for superadmins), optionally in one term
+func ListClassOfferings(user models.User, termID uint) ([]models.ClassOffering, error) {
+	query := scopeOwnedQuery(connection.DB.Model(&models.ClassOffering{}), user, "faculty_id")
+	if termID != 0 {
+		query = query.Where("term_id = ?", termID)
+	}
+
+	var offerings []models.ClassOffering
+	if err := query.Order("subject_code, section").Find(&offerings).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch class offerings: %v", err)
+	}
+	for i := range offerings {
+		offerings[i].EnrollmentCount = offeringEnrollmentCount(offerings[i].ID)
+	}
+	return offerings, nil
+}
+
+// GetClassOffering returns an offering the user manages
+func GetClassOffering(offeringID uint, user models.User) (*models.ClassOffering, error) {
+	offering, err := getManagedOffering(offeringID, user)
+	if err != nil {
+		return nil, err
+	}
+	offering.EnrollmentCount = offeringEnrollmentCount(offering.ID)
+	return offering, nil
+}
+
+// UpdateClassOffering applies non-empty fields to an offering
+func UpdateClassOffering(offeringID uint, req models.ClassOfferingRequest, user models.User) (*models.ClassOffering, error) {
+	offering, err := getManagedOffering(offeringID, user)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyOfferingRequest(offering, req, user); err != nil {
+		return nil, err
+	}
+	if err := connection.DB.Save(offering).Error; err != nil {
+		return nil, fmt.Errorf("failed to update class offering: %v", err)
+	}
+	offering.EnrollmentCount = offeringEnrollmentCount(offering.ID)
+	return offering, nil
+}
+
+// DeactivateClassOffering hides an offering; its events and enrollments are kept
+func DeactivateClassOffering(offeringID uint, user models.User) error {
+	offering, err := getManagedOffering(offeringID, user)
+	if err != nil {
+		return err
+	}
+	return connection.DB.Model(offering).Update("is_active", false).Error
+}
+
+func offeringEnrollmentCount(offeringID uint) int {
+	var count int64
+	connection.DB.Model(&models.Enrollment{}).Where(offeringWhere, offeringID).Count(&count)
+	return int(count)
+}
+
+// ListEnrollments returns the students enrolled in an offering
+func ListEnrollments(offeringID uint, user models.User) ([]models.Enrollment, error) {
+	if _, err := getManagedOffering(offeringID, user); err != nil {
+		return nil, err
+	}
+
+	var enrollments []models.Enrollment
+	if err := connection.DB.Where(offeringWhere, offeringID).Order("student_id").Find(&enrollments).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch enrollments: %v", err)
+	}
+
+	names := studentNames(enrollmentStudentIDs(enrollments))
+	for i := range enrollments {
+		enrollments[i].Name = names[enrollments[i].StudentID]
+	}
+	return enrollments, nil
+}
+
+// EnrollStudents adds students to an offering; unknown or already-enrolled IDs are reported
+func EnrollStudents(offeringID uint, studentIDs []string, user models.User, ipAddress string) (*models.EnrollmentResult, error) {
+	offering, err := getManagedOffering(offeringID, user)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.EnrollmentResult{}
+	seen := make(map[string]bool, len(studentIDs))
+	for _, id := range studentIDs {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		var student models.User
+		if err := connection.DB.Select("student_id").Where(studentWhere+" AND role = ?", id, models.RoleStudent).First(&student).Error; err != nil {
+			result.NotFound = append(result.NotFound, id)
+			continue
+		}
+
+		enrollment := models.Enrollment{OfferingID: offering.ID, StudentID: student.StudentID, EnrolledBy: user.StudentID}
+		res := connection.DB.Clauses(clause.OnConflict{DoNothing: true}).Omit("id").Create(&enrollment)
+		if res.Error != nil {
+			return nil, fmt.Errorf("failed to enroll %s: %v", id, res.Error)
+		}
+		if res.RowsAffected == 0 {
+			result.AlreadyEnrolled++
+			continue
+		}
+		result.Enrolled++
+	}
+
+	if result.Enrolled > 0 {
+		go LogAuditAction(AuditEnrollmentChanged, user.StudentID, strconv.FormatUint(uint64(offering.ID), 10),
+			fmt.Sprintf("enrolled %d student(s) in %s", result.Enrolled, offering.SubjectCode), ipAddress)
+	}
+	return result, nil
+}
+
+// ImportEnrollmentsCSV enrolls the student IDs in a CSV file. The student ID is
+// read from a "student_id" column, or the first column when there is no header.
+func ImportEnrollmentsCSV(offeringID uint, r io.Reader, user models.User, ipAddress string) (*models.EnrollmentResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %v", err)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("CSV file is empty")
+	}
+
+	column := 0
+	for i, name := range records[0] {
+		if strings.EqualFold(strings.TrimSpace(name), "student_id") {
+			column = i
+			records = records[1:]
+			break
+		}
+	}
+
+	ids := make([]string, 0, len(records))
+	for _, record := range records {
+		if column < len(record) {
+			ids = append(ids, record[column])
+		}
+	}
+	return EnrollStudents(offeringID, ids, user, ipAddress)
+}
+
+// UnenrollStudent removes a student from an offering
+func UnenrollStudent(offeringID uint, studentID string, user models.User, ipAddress string) error {
+	offering, err := getManagedOffering(offeringID, user)
+	if err != nil {
+		return err
+	}
+
+	result := connection.DB.Where(offeringWhere+" AND student_id = ?", offeringID, studentID).Delete(&models.Enrollment{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove enrollment: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("enrollment not found")
+	}
+
+	go LogAuditAction(AuditEnrollmentChanged, user.StudentID, studentID,
+		fmt.Sprintf("unenrolled from %s (offering %d)", offering.SubjectCode, offering.ID), ipAddress)
+	return nil
+}
+
+// enrolledStudentsQuery selects the users enrolled in an offering
+func enrolledStudentsQuery(offeringID uint) *gorm.DB {
+	return connection.DB.Model(&models.User{}).Where("student_id IN (?)",
+		connection.DB.Model(&models.Enrollment{}).Select("student_id").Where(offeringWhere, offeringID))
+}
+
+// isEnrolled reports whether a student is enrolled in an offering
+func isEnrolled(offeringID uint, studentID string) bool {
+	var count int64
+	connection.DB.Model(&models.Enrollment{}).Where(offeringWhere+" AND student_id = ?", offeringID, studentID).Count(&count)
+	return count > 0
+}
+
+// enrolledOfferingIDs returns the offerings a student is enrolled in
+func enrolledOfferingIDs(studentID string) map[uint]bool {
+	var ids []uint
+	connection.DB.Model(&models.Enrollment{}).Where(studentWhere, studentID).Pluck("offering_id", &ids)
+	out := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		out[id] = true
+	}
+	return out
+}
+
+// applyEventOffering links a new event to an offering the creator manages and
+// fills the audience fields from it.
+func applyEventOffering(event *models.Event, offeringID uint, createdBy string) error {
+	var user models.User
+	if err := connection.DB.Where(studentWhere, createdBy).First(&user).Error; err != nil {
+		return errors.New("unauthorized")
+	}
+	offering, err := getManagedOffering(offeringID, user)
+	if err != nil {
+		return err
+	}
+	if !offering.IsActive {
+		return errors.New("class offering is not active")
+	}
+	if offering.TermID != nil {
+		if term := termForDate(event.EventDate); term == nil || term.ID != *offering.TermID {
+			return errors.New("event date is outside the class offering's term")
+		}
+	}
+
+	event.OfferingID = &offering.ID
+	if event.Course == "" {
+		event.Course = offering.Course
+	}
+	if event.Section == "" {
+		event.Section = offering.Section
+	}
+	if event.YearLevel == "" {
+		event.YearLevel = offering.YearLevel
+	}
+	return nil
+}
+
+// FinalizeOfferingAbsences marks enrolled students without an attendance
+// record as absent once an offering event is completed.
+func FinalizeOfferingAbsences(event models.Event) {
+	if event.OfferingID == nil {
+		return
+	}
+
+	var studentIDs []string
+	if err := enrolledStudentsQuery(*event.OfferingID).
+		Where("student_id NOT IN (?)", connection.DB.Model(&models.Attendance{}).Select("student_id").Where(EventWhere, event.ID)).
+		Pluck("student_id", &studentIDs).Error; err != nil {
+		logging.Logger.Error("Failed to load students for absence finalization", zap.Uint("event_id", event.ID), zap.Error(err))
+		return
+	}
+
+	now := time.Now()
+	for _, studentID := range studentIDs {
+		att := &models.Attendance{
+			EventID:      event.ID,
+			StudentID:    studentID,
+			Status:       models.AttendanceStatusAbsent,
+			MarkedAt:     now,
+			MarkedBy:     SystemActor,
+			MarkedByRole: SystemActor,
+			Method:       "auto",
+		}
+		if err := createAttendanceRaw(att); err != nil {
+			logging.Logger.Error("Failed to record absence", zap.Uint("event_id", event.ID), zap.String("student_id", studentID), zap.Error(err))
+		}
+	}
+
+	if len(studentIDs) > 0 {
+		logging.Logger.Info("Absences finalized", zap.Uint("event_id", event.ID), zap.Int("count", len(studentIDs)))
+	}
+}
+
+// GetOfferingAttendanceReport summarizes each enrolled student's attendance
+// across the offering's completed events.
+func GetOfferingAttendanceReport(offeringID uint, user models.User) ([]models.OfferingStudentReport, error) {
+	if _, err := getManagedOffering(offeringID, user); err != nil {
+		return nil, err
+	}
+
+	var enrollments []models.Enrollment
+	if err := connection.DB.Where(offeringWhere, offeringID).Order("student_id").Find(&enrollments).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch enrollments: %v", err)
+	}
+
+	var sessions int64
+	eventIDs := connection.DB.Model(&models.Event{}).Select("id").
+		Where("offering_id = ? AND status = ?", offeringID, models.EventStatusCompleted)
+	connection.DB.Model(&models.Event{}).Where("offering_id = ? AND status = ?", offeringID, models.EventStatusCompleted).Count(&sessions)
+
+	var rows []struct {
+		StudentID string
+		Status    string
+		Count     int
+	}
+	if err := connection.DB.Model(&models.Attendance{}).
+		Select("student_id, status, COUNT(*) AS count").
+		Where("event_id IN (?)", eventIDs).
+		Group("student_id, status").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to summarize attendance: %v", err)
+	}
+
+	names := studentNames(enrollmentStudentIDs(enrollments))
+	byStudent := make(map[string]*models.OfferingStudentReport, len(enrollments))
+	report := make([]models.OfferingStudentReport, len(enrollments))
+	for i, e := range enrollments {
+		report[i] = models.OfferingStudentReport{StudentID: e.StudentID, Name: names[e.StudentID], Sessions: int(sessions)}
+		byStudent[e.StudentID] = &report[i]
+	}
+	for _, row := range rows {
+		r, ok := byStudent[row.StudentID]
+		if !ok {
+			continue
+		}
+		switch row.Status {
+		case models.AttendanceStatusPresent:
+			r.PresentCount += row.Count
+		case models.AttendanceStatusLate:
+			r.LateCount += row.Count
+		case models.AttendanceStatusExcused:
+			r.ExcusedCount += row.Count
+		case models.AttendanceStatusAbsent:
+			r.AbsentCount += row.Count
+		}
+	}
+	for i := range report {
+		if report[i].Sessions > 0 {
+			attended := report[i].PresentCount + report[i].LateCount + report[i].ExcusedCount
+			report[i].AttendanceRate = float64(attended) / float64(report[i].Sessions) * 100
+		}
+	}
+	return report, nil
+}
+
+func enrollmentStudentIDs(enrollments []models.Enrollment) []string {
+	ids := make([]string, 0, len(enrollments))
+	for _, e := range enrollments {
+		ids = append(ids, e.StudentID)
+	}
+	return ids
+}
+
+// studentNames maps student IDs to display names
+func studentNames(ids []string) map[string]string {
+	names := make(map[string]string, len(ids))
+	if len(ids) == 0 {
+		return names
+	}
+	var users []models.User
+	connection.DB.Select("student_id", "first_name", "last_name").Where("student_id IN ?", ids).Find(&users)
+	for _, u := range users {
+		names[u.StudentID] = strings.TrimSpace(u.FirstName + " " + u.LastName)
+	}
+	return names
+}
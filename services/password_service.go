@@ -3,12 +3,18 @@ package services
 
 import (
 	"attendance-system/connection"
+	"attendance-system/logging"
 	"attendance-system/models"
 	"attendance-system/utils"
 	"errors"
 	"fmt"
+	"os"
+	"strconv"
 	"strings"
 	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 // Constants
@@ -17,6 +23,12 @@ const (
 	codeQuery  = "email = ? AND code = ? AND used = ? AND expires_at > ?"
 )
 
+// ErrPasswordReused is returned when a new password matches a recent one
+var ErrPasswordReused = errors.New("new password must not match one of your recent passwords")
+
+// ErrWrongCurrentPassword is returned by ChangePassword for a wrong current password
+var ErrWrongCurrentPassword = errors.New("current password is incorrect")
+
 func ForgotPassword(email string) (string, string, error) {
 	// Sanitize input
 	email = utils.SanitizeEmail(email)
@@ -47,110 +59,216 @@ func ForgotPassword(email string) (string, string, error) {
 	}
 
 	// Generate JWT token for password reset flow
-	token, err := GeneratePasswordResetToken(email, code)
+	token, err := GeneratePasswordResetToken(email, 0)
 	if err != nil {
 		return code, "", fmt.Errorf("failed to generate reset token: %w", err)
 	}
 
 	// Send email with code
-	content := fmt.Sprintf(`<p>You requested to reset your password.</p>
-		<p><strong>Reset code:</strong></p>
-		<p style="font-size:22px; font-weight:700; letter-spacing:2px;">%s</p>
-		<p>This code will expire in 15 minutes.</p>
-		<p>If you didn't request this, please ignore this email.</p>
-	`, code)
-
-	footer := `<p class="muted">If you didn't request this change, contact support immediately.</p>`
-	htmlBody := BuildHTMLEmail("Password reset code", "Password Reset Code", content, footer)
-
-	if err := SendEmail(email, "Password Reset Code - Attendance System", htmlBody); err != nil {
+	emailData := map[string]interface{}{
+		"Code":             code,
+		"ExpiresInMinutes": 15,
+	}
+	if err := SendTemplatedEmail(email, EmailTemplatePasswordReset, user.Locale, emailData); err != nil {
 		// Log error without exposing sensitive information to client
-		fmt.Printf("Failed to send reset email to %s: %v\n", email, err)
+		logging.Logger.Warn("Failed to send password reset email", zap.String("student_id", user.StudentID), zap.Error(err))
 	}
 
 	return code, token, nil
 }
 
-func VerifyResetCode(email, code string) error {
-	var reset models.PasswordReset
-	if err := connection.DB.Where(codeQuery, email, code, false, time.Now()).First(&reset).Error; err != nil {
-		return errors.New("invalid or expired reset code")
-	}
-	return nil
-}
-
-// VerifyResetCodeDB is an alias for VerifyResetCode for controller usage
-func VerifyResetCodeDB(email, code string) error {
-	return VerifyResetCode(email, code)
-}
+// ErrInvalidResetCode is returned for a wrong, used or expired reset code
+var ErrInvalidResetCode = errors.New("invalid or expired reset code")
 
-func ResetPasswordWithCode(email, code, newPassword string) error {
-	// Sanitize inputs
+// findResetCode returns the unused, unexpired reset matching email and code
+func findResetCode(email, code string) (models.PasswordReset, error) {
 	email = utils.SanitizeEmail(email)
 	code = strings.TrimSpace(code) // Just trim, don't sanitize the code itself
 
-	// Verify code first - try exact match first
+	// Try exact match first
 	var reset models.PasswordReset
-	if err := connection.DB.Where(codeQuery, email, code, false, time.Now()).First(&reset).Error; err != nil {
-		// If exact match fails, fetch all valid codes and compare with trimming
-		var allResets []models.PasswordReset
-		if err := connection.DB.Where("email = ? AND used = ? AND expires_at > ?", email, false, time.Now()).Find(&allResets).Error; err != nil {
-			return errors.New("invalid or expired reset code")
-		}
+	if err := connection.DB.Where(codeQuery, email, code, false, time.Now()).First(&reset).Error; err == nil {
+		return reset, nil
+	}
 
-		// Check if any code matches (with trimming)
-		found := false
-		for _, r := range allResets {
-			if strings.TrimSpace(r.Code) == code {
-				reset = r
-				found = true
-				break
-			}
+	// If exact match fails, fetch all valid codes and compare with trimming
+	var allResets []models.PasswordReset
+	if err := connection.DB.Where("email = ? AND used = ? AND expires_at > ?", email, false, time.Now()).Find(&allResets).Error; err != nil {
+		return reset, ErrInvalidResetCode
+	}
+	for _, r := range allResets {
+		if code != "" && strings.TrimSpace(r.Code) == code {
+			return r, nil
 		}
+	}
+	return reset, ErrInvalidResetCode
+}
 
-		if !found {
-			return errors.New("invalid or expired reset code")
-		}
+// VerifyResetCode checks a reset code and returns a reset token bound to it,
+// which /reset-password accepts without the code
+func VerifyResetCode(email, code string) (string, error) {
+	reset, err := findResetCode(email, code)
+	if err != nil {
+		return "", err
 	}
+	return GeneratePasswordResetToken(reset.Email, reset.ID)
+}
 
-	// Hash new password
-	hashedPassword, err := utils.HashPassword(newPassword)
+// ResetPasswordWithCode resets the password of the account a reset code was sent to
+func ResetPasswordWithCode(email, code, newPassword string) error {
+	reset, err := findResetCode(email, code)
 	if err != nil {
-		return fmt.Errorf("failed to hash password: %w", err)
+		return err
 	}
+	return completePasswordReset(reset, newPassword)
+}
+
+// ResetPasswordWithVerifiedReset resets the password with a reset whose code
+// was already checked by VerifyResetCode. It fails once the code is used,
+// expired or cancelled by a lockout.
+func ResetPasswordWithVerifiedReset(email string, resetID uint, newPassword string) error {
+	var reset models.PasswordReset
+	if err := connection.DB.Where("id = ? AND email = ? AND used = ? AND expires_at > ?",
+		resetID, utils.SanitizeEmail(email), false, time.Now()).First(&reset).Error; err != nil {
+		return ErrInvalidResetCode
+	}
+	return completePasswordReset(reset, newPassword)
+}
+
+// completePasswordReset sets the new password and uses up the reset
+func completePasswordReset(reset models.PasswordReset, newPassword string) error {
+	email := reset.Email
 
 	// Find user
 	var user models.User
-	if err := connection.DB.Where(emailQuery, email).First(&user).Error; err != nil {
+	if err := connection.DB.Where(emailQuery, email).First(&user).Error; err != nil || user.IsServiceAccount {
 		return errors.New("user not found")
 	}
+	if isPasswordReused(user, newPassword) {
+		return ErrPasswordReused
+	}
 
 	// Update password
-	if err := connection.DB.Model(&user).Update("password", string(hashedPassword)).Error; err != nil {
-		return fmt.Errorf("failed to update password: %v", err)
+	if err := setPassword(user, newPassword); err != nil {
+		return err
 	}
 
 	// Mark code as used
 	if err := connection.DB.Model(&reset).Update("used", true).Error; err != nil {
 		// Log error without exposing sensitive information
-		fmt.Printf("Failed to mark code as used\n")
+		logging.Logger.Error("Failed to mark reset code as used", zap.String("student_id", user.StudentID), zap.Error(err))
 	}
 
-	// Send confirmation email
-	content := `<p>Your password has been successfully reset.</p>
-		<p>You can now log in with your new password.</p>
-		<p>If you didn't make this change, contact support immediately.</p>`
-	footer := `<p class="muted">If you did not initiate this change, please contact support.</p>`
-	htmlBody := BuildHTMLEmail("Password changed", "Password Changed", content, footer)
+	// Log out every session; a stolen refresh token must not outlive the reset
+	if _, err := RevokeAllSessions(user.StudentID, models.SessionRevokedPasswordReset); err != nil {
+		logging.Logger.Error("Failed to revoke sessions after password reset", zap.String("student_id", user.StudentID), zap.Error(err))
+	}
 
-	if err := SendEmail(email, "Password Changed - Attendance System", htmlBody); err != nil {
+	// Send confirmation email
+	if err := SendTemplatedEmail(email, EmailTemplatePasswordChanged, user.Locale, nil); err != nil {
 		// Log error without exposing sensitive information to client
-		fmt.Printf("Failed to send confirmation email to %s: %v\n", email, err)
+		logging.Logger.Warn("Failed to send password changed email", zap.String("student_id", user.StudentID), zap.Error(err))
 	}
 
 	return nil
 }
 
+// ChangePassword changes the logged-in user's password after checking the
+// current one, then logs out every other session
+func ChangePassword(studentID, currentPassword, newPassword, sessionID, ipAddress string) error {
+	var user models.User
+	if err := GetUserByStudentID(studentID, &user); err != nil || user.IsServiceAccount {
+		return errors.New(models.ErrUserNotFound)
+	}
+	if utils.ComparePassword(user.Password, currentPassword) != nil {
+		return ErrWrongCurrentPassword
+	}
+	if isPasswordReused(user, newPassword) {
+		return ErrPasswordReused
+	}
+	if err := setPassword(user, newPassword); err != nil {
+		return err
+	}
+
+	if sessionID != "" {
+		_, err := RevokeOtherSessions(user.StudentID, sessionID, models.SessionRevokedPasswordChange)
+		if err != nil {
+			logging.Logger.Error("Failed to revoke sessions after password change", zap.String("student_id", user.StudentID), zap.Error(err))
+		}
+	} else if _, err := RevokeAllSessions(user.StudentID, models.SessionRevokedPasswordChange); err != nil {
+		logging.Logger.Error("Failed to revoke sessions after password change", zap.String("student_id", user.StudentID), zap.Error(err))
+	}
+
+	if err := SendTemplatedEmail(user.Email, EmailTemplatePasswordChanged, user.Locale, nil); err != nil {
+		logging.Logger.Warn("Failed to send password change confirmation", zap.String("student_id", user.StudentID), zap.Error(err))
+	}
+
+	go LogAuditAction(AuditPasswordChanged, user.StudentID, user.StudentID, "changed by user", ipAddress)
+	return nil
+}
+
+// passwordHistoryDepth is how many recent passwords, the current one included,
+// cannot be reused (PASSWORD_HISTORY_DEPTH, default 5)
+func passwordHistoryDepth() int {
+	if n, err := strconv.Atoi(os.Getenv("PASSWORD_HISTORY_DEPTH")); err == nil && n >= 1 {
+		return n
+	}
+	return 5
+}
+
+// isPasswordReused reports whether password matches the current password or
+// one kept in the history
+func isPasswordReused(user models.User, password string) bool {
+	if utils.ComparePassword(user.Password, password) == nil {
+		return true
+	}
+	if passwordHistoryDepth() == 1 {
+		return false
+	}
+	var history []models.PasswordHistory
+	connection.DB.Where(studentWhere, user.StudentID).
+		Order("id DESC").Limit(passwordHistoryDepth() - 1).
+		Find(&history)
+	for _, h := range history {
+		if utils.ComparePassword(h.PasswordHash, password) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// setPassword stores a new password and moves the old hash into the history,
+// keeping only as many entries as the reuse check looks at
+func setPassword(user models.User, newPassword string) error {
+	hashedPassword, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	keep := passwordHistoryDepth() - 1
+
+	err = connection.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where(studentWhere, user.StudentID).Update("password", hashedPassword).Error; err != nil {
+			return err
+		}
+		if keep > 0 && user.Password != "" {
+			entry := models.PasswordHistory{StudentID: user.StudentID, PasswordHash: user.Password}
+			if err := tx.Omit("id").Create(&entry).Error; err != nil {
+				return err
+			}
+		}
+		if keep == 0 {
+			return tx.Where(studentWhere, user.StudentID).Delete(&models.PasswordHistory{}).Error
+		}
+		recent := tx.Model(&models.PasswordHistory{}).Select("id").
+			Where(studentWhere, user.StudentID).Order("id DESC").Limit(keep)
+		return tx.Where("student_id = ? AND id NOT IN (?)", user.StudentID, recent).Delete(&models.PasswordHistory{}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update password: %v", err)
+	}
+	return nil
+}
+
 // Resend reset code
 func ResendResetCode(email string) (string, string, error) {
 	// Delete any existing unused codes for this email
@@ -159,3 +277,9 @@ func ResendResetCode(email string) (string, string, error) {
 	// Generate and send new code
 	return ForgotPassword(email)
 }
+
+// DeleteExpiredPasswordResets removes reset codes that are expired or already used
+func DeleteExpiredPasswordResets() (int64, error) {
+	result := connection.DB.Where("expires_at < ? OR used = ?", time.Now(), true).Delete(&models.PasswordReset{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,314 @@
+// services/permission_service.go
+package services
+
+import (
+	"attendance-system/connection"
+	"attendance-system/logging"
+	"attendance-system/models"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrRoleNotFound is returned when a role name does not exist
+var ErrRoleNotFound = errors.New("role not found")
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+
+// systemRoles are seeded on startup with their default permissions. Superadmins
+// implicitly hold every permission.
+var systemRoles = []struct {
+	name        string
+	description string
+	permissions []string
+}{
+	{models.RoleSuperAdmin, "Full system access", nil},
+	{models.RoleAdmin, "Department administrator", []string{
+		models.PermEventCreate, models.PermEventDeleteAny, models.PermAttendanceOverride, models.PermAuditRead,
+		models.PermUserUnlock, models.PermProfileLock, models.PermUserStatus,
+	}},
+	{models.RoleFaculty, "Teaching staff", []string{models.PermEventCreate, models.PermAttendanceOverride}},
+	{models.RoleStaff, "Non-teaching staff", []string{models.PermAttendanceOverride}},
+	{models.RoleStudent, "Student", nil},
+}
+
+// untrackedDefaults are the defaults built-in roles were seeded with before
+// default grants were recorded. Roles from that time already received them
+// (or had them removed on purpose), so they are recorded without granting.
+var untrackedDefaults = map[string][]string{
+	models.RoleAdmin:   {models.PermEventCreate, models.PermEventDeleteAny, models.PermAttendanceOverride, models.PermAuditRead},
+	models.RoleFaculty: {models.PermEventCreate, models.PermAttendanceOverride},
+	models.RoleStaff:   {models.PermAttendanceOverride},
+}
+
+// SeedRoles creates missing system roles and grants each of their default
+// permissions once. Defaults added in later versions reach existing roles;
+// a default a superadmin removed afterwards is not granted again.
+func SeedRoles() {
+	for _, sr := range systemRoles {
+		err := connection.DB.Transaction(func(tx *gorm.DB) error {
+			var existing models.Role
+			existed := tx.Where("name = ?", sr.name).First(&existing).Error == nil
+			if !existed {
+				role := models.Role{Name: sr.name, Description: sr.description, IsSystem: true}
+				if err := tx.Create(&role).Error; err != nil {
+					return err
+				}
+			}
+			return applyDefaultPermissions(tx, sr.name, sr.permissions, existed)
+		})
+		if err != nil {
+			logging.Logger.Error("Failed to seed role", zap.String("role", sr.name), zap.Error(err))
+		}
+	}
+}
+
+// applyDefaultPermissions grants the defaults of a built-in role that were
+// never granted before and records them
+func applyDefaultPermissions(tx *gorm.DB, role string, defaults []string, existed bool) error {
+	var grants []models.RoleDefaultGrant
+	if err := tx.Where("role_name = ?", role).Find(&grants).Error; err != nil {
+		return err
+	}
+	granted := make(map[string]bool, len(grants))
+	for _, g := range grants {
+		granted[g.Permission] = true
+	}
+	var pending []string
+	if existed && len(grants) == 0 {
+		pending = untrackedDefaults[role]
+		for _, p := range pending {
+			granted[p] = true
+		}
+	}
+
+	var held []string
+	if err := tx.Model(&models.RolePermission{}).Where("role_name = ?", role).Pluck("permission", &held).Error; err != nil {
+		return err
+	}
+	holds := make(map[string]bool, len(held))
+	for _, p := range held {
+		holds[p] = true
+	}
+
+	var grant []string
+	for _, p := range defaults {
+		if granted[p] {
+			continue
+		}
+		pending = append(pending, p)
+		if !holds[p] {
+			grant = append(grant, p)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	if err := insertRolePermissions(tx, role, grant); err != nil {
+		return err
+	}
+	rows := make([]models.RoleDefaultGrant, 0, len(pending))
+	for _, p := range pending {
+		rows = append(rows, models.RoleDefaultGrant{RoleName: role, Permission: p})
+	}
+	if err := tx.Create(&rows).Error; err != nil {
+		return err
+	}
+	if existed && len(grant) > 0 {
+		logging.Logger.Info("Granted new default permissions", zap.String("role", role), zap.Strings("permissions", grant))
+	}
+	return nil
+}
+
+// HasPermission reports whether a role grants a permission
+func HasPermission(role, permission string) bool {
+	if role == models.RoleSuperAdmin {
+		return true
+	}
+	var count int64
+	connection.DB.Model(&models.RolePermission{}).
+		Where("role_name = ? AND permission = ?", role, permission).
+		Count(&count)
+	return count > 0
+}
+
+// CanGrantRole reports whether a user with granterRole may assign role: every
+// permission of role must also be held by the granter. Only superadmins may
+// grant or manage superadmin, which has no permission rows to compare.
+func CanGrantRole(granterRole, role string) bool {
+	if granterRole == models.RoleSuperAdmin {
+		return true
+	}
+	if role == models.RoleSuperAdmin {
+		return false
+	}
+	var missing int64
+	connection.DB.Model(&models.RolePermission{}).
+		Where("role_name = ? AND permission NOT IN (?)", role,
+			connection.DB.Model(&models.RolePermission{}).Select("permission").Where("role_name = ?", granterRole)).
+		Count(&missing)
+	return missing == 0
+}
+
+// RoleExists reports whether a role is defined
+func RoleExists(name string) bool {
+	var count int64
+	connection.DB.Model(&models.Role{}).Where("name = ?", name).Count(&count)
+	return count > 0
+}
+
+// ListRoles returns every role with its permissions
+func ListRoles() ([]models.Role, error) {
+	var roles []models.Role
+	if err := connection.DB.Order("is_system DESC, name").Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch roles: %v", err)
+	}
+	var grants []models.RolePermission
+	if err := connection.DB.Order("permission").Find(&grants).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch role permissions: %v", err)
+	}
+	byRole := make(map[string][]string)
+	for _, g := range grants {
+		byRole[g.RoleName] = append(byRole[g.RoleName], g.Permission)
+	}
+	for i := range roles {
+		roles[i].Permissions = rolePermissions(roles[i].Name, byRole[roles[i].Name])
+	}
+	return roles, nil
+}
+
+// rolePermissions returns the effective permissions for display
+func rolePermissions(role string, granted []string) []string {
+	if role == models.RoleSuperAdmin {
+		return models.AllPermissions
+	}
+	if granted == nil {
+		return []string{}
+	}
+	return granted
+}
+
+// validatePermissions rejects unknown permission names and removes duplicates
+func validatePermissions(perms []string) ([]string, error) {
+	known := make(map[string]bool, len(models.AllPermissions))
+	for _, p := range models.AllPermissions {
+		known[p] = true
+	}
+	seen := make(map[string]bool, len(perms))
+	out := make([]string, 0, len(perms))
+	for _, p := range perms {
+		p = strings.TrimSpace(p)
+		if !known[p] {
+			return nil, fmt.Errorf("unknown permission %q", p)
+		}
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func insertRolePermissions(tx *gorm.DB, role string, perms []string) error {
+	if len(perms) == 0 {
+		return nil
+	}
+	rows := make([]models.RolePermission, 0, len(perms))
+	for _, p := range perms {
+		rows = append(rows, models.RolePermission{RoleName: role, Permission: p})
+	}
+	return tx.Omit("id").Create(&rows).Error
+}
+
+// CreateRole adds a custom role with the given permissions
+func CreateRole(req models.RoleRequest, actor, ipAddress string) (*models.Role, error) {
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+	if !roleNamePattern.MatchString(name) {
+		return nil, errors.New("invalid role name. Use 2-50 lowercase letters, digits or underscores, starting with a letter")
+	}
+	if RoleExists(name) {
+		return nil, errors.New("a role with this name already exists")
+	}
+	perms, err := validatePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := models.Role{Name: name, Description: strings.TrimSpace(req.Description)}
+	err = connection.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&role).Error; err != nil {
+			return err
+		}
+		return insertRolePermissions(tx, name, perms)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create role: %v", err)
+	}
+
+	go LogAuditAction(AuditRoleCreated, actor, name, strings.Join(perms, ","), ipAddress)
+	role.Permissions = perms
+	return &role, nil
+}
+
+// SetRolePermissions replaces the permissions granted to a role. The
+// superadmin role always holds every permission and cannot be edited.
+func SetRolePermissions(name string, permissions []string, actor, ipAddress string) (*models.Role, error) {
+	var role models.Role
+	if err := connection.DB.Where("name = ?", name).First(&role).Error; err != nil {
+		return nil, ErrRoleNotFound
+	}
+	if role.Name == models.RoleSuperAdmin {
+		return nil, errors.New("the superadmin role always has every permission")
+	}
+	perms, err := validatePermissions(permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	err = connection.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_name = ?", role.Name).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		return insertRolePermissions(tx, role.Name, perms)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update role permissions: %v", err)
+	}
+
+	go LogAuditAction(AuditRolePermissions, actor, role.Name, strings.Join(perms, ","), ipAddress)
+	role.Permissions = perms
+	return &role, nil
+}
+
+// DeleteRole removes a custom role that no user holds
+func DeleteRole(name, actor, ipAddress string) error {
+	var role models.Role
+	if err := connection.DB.Where("name = ?", name).First(&role).Error; err != nil {
+		return ErrRoleNotFound
+	}
+	if role.IsSystem {
+		return errors.New("built-in roles cannot be deleted")
+	}
+	var holders int64
+	connection.DB.Model(&models.User{}).Where("role = ?", role.Name).Count(&holders)
+	if holders > 0 {
+		return fmt.Errorf("role is assigned to %d user(s); reassign them first", holders)
+	}
+
+	err := connection.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_name = ?", role.Name).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&role).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete role: %v", err)
+	}
+
+	go LogAuditAction(AuditRoleDeleted, actor, role.Name, role.Description, ipAddress)
+	return nil
+}
@@ -0,0 +1,221 @@
+// services/policy.go
+package services
+
+import (
+	"attendance-system/connection"
+	"attendance-system/models"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Row-level access policy. Every "may this user see or change this row"
+// decision for events, attendance and student data goes through here:
+//   - students see only their own data
+//   - faculty see events they own or staff, and the students in them
+//   - department admins see their department
+//   - superadmins see everything
+
+// ErrForbidden is returned when the policy denies access to a row
+var ErrForbidden = errors.New("forbidden: you do not have access to this data")
+
+// isSuperAdmin reports whether the user sees everything
+func isSuperAdmin(user models.User) bool {
+	return user.Role == models.RoleSuperAdmin
+}
+
+// isDepartmentAdmin reports whether the user administers a department. An
+// admin without a department has no department-wide access.
+func isDepartmentAdmin(user models.User) bool {
+	return user.Role == models.RoleAdmin && (user.DepartmentID != nil || strings.TrimSpace(user.Department) != "")
+}
+
+// inDepartment reports whether a row's department (by ID, else by name) is the user's
+func inDepartment(user models.User, departmentID *uint, department string) bool {
+	if user.DepartmentID != nil && departmentID != nil {
+		return *user.DepartmentID == *departmentID
+	}
+	name := strings.TrimSpace(department)
+	return name != "" && strings.EqualFold(name, strings.TrimSpace(user.Department))
+}
+
+// administers reports whether the user has admin rights over another user
+func administers(user, other models.User) bool {
+	if isSuperAdmin(user) {
+		return true
+	}
+	return isDepartmentAdmin(user) && inDepartment(user, other.DepartmentID, other.Department)
+}
+
+// CanManageUser: superadmins, and department admins for users in their department
+func CanManageUser(user, other models.User) bool {
+	return administers(user, other)
+}
+
+// scopeAdministeredUsers limits a users query to those the user administers
+func scopeAdministeredUsers(query *gorm.DB, user models.User) *gorm.DB {
+	if isSuperAdmin(user) {
+		return query
+	}
+	if !isDepartmentAdmin(user) {
+		return query.Where("1 = 0")
+	}
+	if user.DepartmentID != nil {
+		return query.Where("department_id = ?", *user.DepartmentID)
+	}
+	return query.Where("UPPER(TRIM(department)) = ?", strings.ToUpper(strings.TrimSpace(user.Department)))
+}
+
+// administersStudentID is administers for a student ID
+func administersStudentID(user models.User, studentID string) bool {
+	if isSuperAdmin(user) {
+		return true
+	}
+	if !isDepartmentAdmin(user) {
+		return false
+	}
+	var other models.User
+	if err := connection.DB.Select("student_id", "department", "department_id").Where(studentWhere, studentID).First(&other).Error; err != nil {
+		return false
+	}
+	return administers(user, other)
+}
+
+// administersEvent: superadmins, and department admins for events of their
+// department (or, for events without one, events created in their department)
+func administersEvent(user models.User, event *models.Event) bool {
+	if isSuperAdmin(user) {
+		return true
+	}
+	return isDepartmentAdmin(user) && inEventDepartment(user, event)
+}
+
+// inEventDepartment reports whether an event belongs to the user's department
+// (or, for events without one, was created by someone in it)
+func inEventDepartment(user models.User, event *models.Event) bool {
+	if user.DepartmentID == nil && strings.TrimSpace(user.Department) == "" {
+		return false
+	}
+	if event.DepartmentID != nil || strings.TrimSpace(event.Department) != "" {
+		return inDepartment(user, event.DepartmentID, event.Department)
+	}
+	var creator models.User
+	if err := connection.DB.Select("student_id", "department", "department_id").Where(studentWhere, event.CreatedBy).First(&creator).Error; err != nil {
+		return false
+	}
+	return inDepartment(user, creator.DepartmentID, creator.Department)
+}
+
+// canDeleteEvent: the creator, superadmins, and roles granted event.delete.any
+// for events in their department
+func canDeleteEvent(event *models.Event, user models.User) bool {
+	if event.CreatedBy == user.StudentID || isSuperAdmin(user) {
+		return true
+	}
+	return HasPermission(user.Role, models.PermEventDeleteAny) && inEventDepartment(user, event)
+}
+
+// scopeOwnedQuery limits rows to those owned (ownerColumn) by the user or, for
+// department admins, by anyone in their department. Superadmins see all rows.
+func scopeOwnedQuery(query *gorm.DB, user models.User, ownerColumn string) *gorm.DB {
+	if isSuperAdmin(user) {
+		return query
+	}
+	if !isDepartmentAdmin(user) {
+		return query.Where(ownerColumn+" = ?", user.StudentID)
+	}
+	members := connection.DB.Model(&models.User{}).Select("student_id")
+	if user.DepartmentID != nil {
+		members = members.Where("department_id = ?", *user.DepartmentID)
+	} else {
+		members = members.Where("UPPER(TRIM(department)) = ?", strings.ToUpper(strings.TrimSpace(user.Department)))
+	}
+	return query.Where(ownerColumn+" = ? OR "+ownerColumn+" IN (?)", user.StudentID, members)
+}
+
+// eventStaffRole returns the user's staff role on the event, or "" if none
+func eventStaffRole(eventID uint, studentID string) string {
+	var staff models.EventStaff
+	if err := connection.DB.Where("event_id = ? AND student_id = ?", eventID, studentID).First(&staff).Error; err != nil {
+		return ""
+	}
+	return staff.Role
+}
+
+// canOverrideAttendance: event editors whose role grants attendance.override
+func canOverrideAttendance(event *models.Event, user models.User) bool {
+	return canEditEvent(event, user) && HasPermission(user.Role, models.PermAttendanceOverride)
+}
+
+// canEditEvent: the creator, a co-organizer, or an admin over the event
+func canEditEvent(event *models.Event, user models.User) bool {
+	if event.CreatedBy == user.StudentID || administersEvent(user, event) {
+		return true
+	}
+	return eventStaffRole(event.ID, user.StudentID) == models.EventStaffCoOrganizer
+}
+
+// canScanEvent: anyone who can edit the event, plus assigned scanners
+func canScanEvent(event *models.Event, user models.User) bool {
+	if event.CreatedBy == user.StudentID || administersEvent(user, event) {
+		return true
+	}
+	switch eventStaffRole(event.ID, user.StudentID) {
+	case models.EventStaffCoOrganizer, models.EventStaffScanner:
+		return true
+	}
+	return false
+}
+
+// ensureEventOwner allows only the event creator (or an admin over it) to manage staff
+func ensureEventOwner(event *models.Event, user models.User) error {
+	if event.CreatedBy == user.StudentID || administersEvent(user, event) {
+		return nil
+	}
+	return errors.New("unauthorized: only the event owner can manage event staff")
+}
+
+// CanViewEvent: published events are visible to every signed-in user; drafts
+// only to the event's staff and admins
+func CanViewEvent(user models.User, event *models.Event) bool {
+	if eventStatus(event) != models.EventStatusDraft {
+		return true
+	}
+	return canScanEvent(event, user)
+}
+
+// CanViewEventAttendance: the event's owner, staff and admins over it
+func CanViewEventAttendance(user models.User, event *models.Event) bool {
+	return canScanEvent(event, user)
+}
+
+// CanViewStudent reports whether the viewer may read a student's records:
+// the student themself, admins over them, and faculty/staff of an event the
+// student attended or an offering they are enrolled in.
+func CanViewStudent(viewer models.User, studentID string) bool {
+	if viewer.StudentID == studentID || administersStudentID(viewer, studentID) {
+		return true
+	}
+	if viewer.Role == models.RoleStudent {
+		return false
+	}
+
+	managed := connection.DB.Model(&models.Event{}).Select("id").
+		Where("created_by = ? OR id IN (?)", viewer.StudentID,
+			connection.DB.Model(&models.EventStaff{}).Select("event_id").Where(studentWhere, viewer.StudentID))
+
+	var count int64
+	connection.DB.Model(&models.Attendance{}).
+		Where("student_id = ? AND event_id IN (?)", studentID, managed).
+		Count(&count)
+	if count > 0 {
+		return true
+	}
+
+	connection.DB.Model(&models.Enrollment{}).
+		Where("student_id = ? AND offering_id IN (?)", studentID,
+			connection.DB.Model(&models.ClassOffering{}).Select("id").Where("faculty_id = ?", viewer.StudentID)).
+		Count(&count)
+	return count > 0
+}
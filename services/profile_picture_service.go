@@ -0,0 +1,214 @@
+// services/profile_picture_service.go
+package services
+
+import (
+	"attendance-system/connection"
+	"attendance-system/logging"
+	"attendance-system/models"
+	"attendance-system/utils"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	// Decoders for the accepted upload formats
+	_ "image/gif"
+	_ "image/png"
+
+	"go.uber.org/zap"
+)
+
+// Profile pictures are stored only as square JPEG thumbnails of these sizes;
+// the uploaded original (and its metadata) is discarded
+const (
+	ProfilePictureSize      = 256
+	ProfilePictureThumbSize = 64
+
+	profilePicturePrefix  = "profile-pictures/"
+	profilePictureQuality = 85
+	// Larger images are rejected before decoding to bound memory use
+	profilePictureMaxPixels = 40_000_000
+)
+
+// ErrProfilePictureLocked is returned when administrators locked the photo
+var ErrProfilePictureLocked = errors.New("profile_picture is locked by an administrator")
+
+// ErrNoProfilePicture is returned when removing a photo that was never set
+var ErrNoProfilePicture = errors.New("no profile picture set")
+
+var profilePictureTypes = map[string]bool{"image/jpeg": true, "image/png": true, "image/gif": true}
+
+// ProfilePictureMaxBytes is the upload size limit (PROFILE_PICTURE_MAX_BYTES, default 5 MB)
+func ProfilePictureMaxBytes() int {
+	if n, err := strconv.Atoi(os.Getenv("PROFILE_PICTURE_MAX_BYTES")); err == nil && n > 0 {
+		return n
+	}
+	return 5 * 1024 * 1024
+}
+
+// profilePictureKey is the storage key of one thumbnail size
+func profilePictureKey(base string, size int) string {
+	return fmt.Sprintf("%s_%d.jpg", base, size)
+}
+
+// ProfilePictureURL returns the URL of a user's photo at the given size, or ""
+// when none is set. Columns holding anything but a storage key (e.g. legacy
+// base64 data) are ignored.
+func ProfilePictureURL(base string, size int) string {
+	if !strings.HasPrefix(base, profilePicturePrefix) {
+		return ""
+	}
+	return PublicAPIURL() + "/media/" + profilePictureKey(base, size)
+}
+
+// mediaKeyPattern matches the keys SetProfilePicture writes:
+// profile-pictures/<student>/<32 hex chars>_<size>.jpg
+var mediaKeyPattern = regexp.MustCompile(`^profile-pictures/[A-Za-z0-9_-]+/[0-9a-f]{32}_(256|64)\.jpg$`)
+
+// IsMediaKey reports whether key names a file served by GET /media. Only keys
+// with the full random token qualify, since the route needs no sign-in.
+func IsMediaKey(key string) bool {
+	return mediaKeyPattern.MatchString(key) && validStorageKey(key)
+}
+
+// DecodeImageDataURL decodes a data:image/...;base64 URL
+func DecodeImageDataURL(dataURL string) ([]byte, error) {
+	dataURL = strings.TrimSpace(dataURL)
+	if !utils.ValidateBase64Image(dataURL) {
+		return nil, errors.New("image must be a base64 data URL (data:image/jpeg;base64,...)")
+	}
+	data, err := base64.StdEncoding.DecodeString(dataURL[strings.Index(dataURL, ",")+1:])
+	if err != nil {
+		return nil, errors.New("image is not valid base64")
+	}
+	return data, nil
+}
+
+// makeProfilePictureThumbnails validates an upload and renders the thumbnails
+func makeProfilePictureThumbnails(data []byte) (map[int][]byte, error) {
+	if len(data) == 0 {
+		return nil, errors.New("image is empty")
+	}
+	if len(data) > ProfilePictureMaxBytes() {
+		return nil, fmt.Errorf("image must be at most %d KB", ProfilePictureMaxBytes()/1024)
+	}
+	contentType := http.DetectContentType(data)
+	if !profilePictureTypes[contentType] {
+		return nil, fmt.Errorf("unsupported image type %s; upload a JPEG, PNG or GIF", contentType)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("image could not be read")
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > profilePictureMaxPixels {
+		return nil, fmt.Errorf("image dimensions %dx%d are not allowed", cfg.Width, cfg.Height)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("image could not be read")
+	}
+
+	thumbnails := make(map[int][]byte, 2)
+	for _, size := range []int{ProfilePictureSize, ProfilePictureThumbSize} {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, utils.SquareThumbnail(img, size), &jpeg.Options{Quality: profilePictureQuality}); err != nil {
+			return nil, fmt.Errorf("failed to encode thumbnail: %v", err)
+		}
+		thumbnails[size] = buf.Bytes()
+	}
+	return thumbnails, nil
+}
+
+// SetProfilePicture validates, resizes and stores a new photo, replacing the old one
+func SetProfilePicture(user models.User, data []byte, ipAddress string) (models.User, error) {
+	if !HasPermission(user.Role, models.PermProfileLock) && lockedProfileFields()["profile_picture"] {
+		return user, ErrProfilePictureLocked
+	}
+	thumbnails, err := makeProfilePictureThumbnails(data)
+	if err != nil {
+		return user, err
+	}
+
+	// A fresh random key per upload keeps URLs unguessable and lets clients cache them
+	token, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return user, err
+	}
+	base := profilePicturePrefix + storageSafeName(user.StudentID) + "/" + token
+	storage := GetFileStorage()
+	for size, img := range thumbnails {
+		if err := storage.Put(profilePictureKey(base, size), img, "image/jpeg"); err != nil {
+			deleteProfilePictureFiles(base)
+			return user, fmt.Errorf("failed to store profile picture: %v", err)
+		}
+	}
+
+	if err := connection.DB.Model(&models.User{}).Where(studentWhere, user.StudentID).Update("profile_picture", base).Error; err != nil {
+		deleteProfilePictureFiles(base)
+		return user, fmt.Errorf("failed to save profile picture: %v", err)
+	}
+	deleteProfilePictureFiles(user.ProfilePicture)
+	user.ProfilePicture = base
+
+	go LogAuditAction(AuditPhotoUpdated, user.StudentID, user.StudentID, storage.Name(), ipAddress)
+	return user, nil
+}
+
+// RemoveProfilePicture deletes the user's photo
+func RemoveProfilePicture(user models.User, ipAddress string) (models.User, error) {
+	if user.ProfilePicture == "" {
+		return user, ErrNoProfilePicture
+	}
+	if !HasPermission(user.Role, models.PermProfileLock) && lockedProfileFields()["profile_picture"] {
+		return user, ErrProfilePictureLocked
+	}
+	if err := connection.DB.Model(&models.User{}).Where(studentWhere, user.StudentID).Update("profile_picture", "").Error; err != nil {
+		return user, fmt.Errorf("failed to remove profile picture: %v", err)
+	}
+	deleteProfilePictureFiles(user.ProfilePicture)
+	user.ProfilePicture = ""
+
+	go LogAuditAction(AuditPhotoRemoved, user.StudentID, user.StudentID, "", ipAddress)
+	return user, nil
+}
+
+// GetMediaFile reads a file served by GET /media
+func GetMediaFile(key string) ([]byte, string, error) {
+	if !IsMediaKey(key) {
+		return nil, "", ErrFileNotFound
+	}
+	return GetFileStorage().Get(key)
+}
+
+// deleteProfilePictureFiles removes every thumbnail of base, logging failures
+func deleteProfilePictureFiles(base string) {
+	if !strings.HasPrefix(base, profilePicturePrefix) {
+		return
+	}
+	for _, size := range []int{ProfilePictureSize, ProfilePictureThumbSize} {
+		if err := GetFileStorage().Delete(profilePictureKey(base, size)); err != nil {
+			logging.Logger.Warn("Failed to delete profile picture", zap.String("key", base), zap.Error(err))
+		}
+	}
+}
+
+// storageSafeName maps a student ID onto characters safe in any storage key
+func storageSafeName(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '_' {
+			b.WriteRune(c)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
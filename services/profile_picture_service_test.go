@@ -0,0 +1,20 @@
+package services
+
+import "testing"
+
+func TestIsMediaKey(t *testing.T) {
+	token := "0123456789abcdef0123456789abcdef"
+	cases := map[string]bool{
+		profilePictureKey(profilePicturePrefix+"2024-001/"+token, ProfilePictureSize):      true,
+		profilePictureKey(profilePicturePrefix+"2024-001/"+token, ProfilePictureThumbSize): true,
+		"profile-pictures/2024-001/0123_256.jpg":                                           false,
+		"profile-pictures/2024-001/" + token + "_128.jpg":                                  false,
+		"profile-pictures/../" + token + "_256.jpg":                                        false,
+		"exports/2024-001/" + token + "_256.jpg":                                           false,
+	}
+	for key, want := range cases {
+		if got := IsMediaKey(key); got != want {
+			t.Errorf("IsMediaKey(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
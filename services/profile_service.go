@@ -0,0 +1,322 @@
+// services/profile_service.go
+package services
+
+import (
+	"attendance-system/connection"
+	"attendance-system/logging"
+	"attendance-system/models"
+	"attendance-system/utils"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrNoEmailChange is returned when there is no unexpired email change to confirm
+var ErrNoEmailChange = errors.New("no pending email change, or its code has expired")
+
+// ErrInvalidEmailChangeCode is returned for a wrong email change code
+var ErrInvalidEmailChangeCode = errors.New("invalid verification code")
+
+const emailChangeExpiry = 30 * time.Minute
+
+var contactNumberPattern = regexp.MustCompile(`^[0-9+()\- ]{7,20}$`)
+
+// lockedProfileFields returns the fields users cannot change themselves
+func lockedProfileFields() map[string]bool {
+	var locks []models.ProfileFieldLock
+	connection.DB.Find(&locks)
+	locked := make(map[string]bool, len(locks))
+	for _, l := range locks {
+		locked[l.Field] = true
+	}
+	return locked
+}
+
+// ListProfileLocks returns the locked profile fields
+func ListProfileLocks() ([]models.ProfileFieldLock, error) {
+	var locks []models.ProfileFieldLock
+	if err := connection.DB.Order("field").Find(&locks).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch profile locks: %v", err)
+	}
+	return locks, nil
+}
+
+// SetProfileLocks replaces the set of locked profile fields
+func SetProfileLocks(fields []string, actor, ipAddress string) ([]models.ProfileFieldLock, error) {
+	lockable := make(map[string]bool, len(models.LockableProfileFields))
+	for _, f := range models.LockableProfileFields {
+		lockable[f] = true
+	}
+	seen := make(map[string]bool, len(fields))
+	locks := make([]models.ProfileFieldLock, 0, len(fields))
+	for _, f := range fields {
+		f = strings.TrimSpace(f)
+		if !lockable[f] {
+			return nil, fmt.Errorf("field %q cannot be locked. Lockable fields: %s", f, strings.Join(models.LockableProfileFields, ", "))
+		}
+		if !seen[f] {
+			seen[f] = true
+			locks = append(locks, models.ProfileFieldLock{Field: f, LockedBy: actor})
+		}
+	}
+
+	err := connection.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.ProfileFieldLock{}).Error; err != nil {
+			return err
+		}
+		if len(locks) == 0 {
+			return nil
+		}
+		return tx.Create(&locks).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update profile locks: %v", err)
+	}
+
+	names := make([]string, 0, len(locks))
+	for _, l := range locks {
+		names = append(names, l.Field)
+	}
+	go LogAuditAction(AuditProfileLocksSet, actor, "profile", "locked: "+strings.Join(names, ","), ipAddress)
+	return locks, nil
+}
+
+// GetProfile builds the user's own profile
+func GetProfile(user models.User) models.ProfileResponse {
+	profile := models.ProfileResponse{
+		Message:          models.SuccessProfileFetched,
+		UserResponse:     models.NewUserResponse(user),
+		InstitutionRefs:  user.InstitutionRefs,
+		Locale:           user.Locale,
+		TwoFactorEnabled: IsTwoFactorEnabled(user.StudentID),
+		LockedFields:     []string{},
+
+		ProfilePictureURL:          ProfilePictureURL(user.ProfilePicture, ProfilePictureSize),
+		ProfilePictureThumbnailURL: ProfilePictureURL(user.ProfilePicture, ProfilePictureThumbSize),
+	}
+	if !HasPermission(user.Role, models.PermProfileLock) {
+		locked := lockedProfileFields()
+		for _, f := range models.LockableProfileFields {
+			if locked[f] {
+				profile.LockedFields = append(profile.LockedFields, f)
+			}
+		}
+	}
+	var change models.EmailChange
+	if err := connection.DB.Where("student_id = ? AND expires_at > ?", user.StudentID, time.Now()).First(&change).Error; err == nil {
+		profile.PendingEmail = change.NewEmail
+	}
+	return profile
+}
+
+// UpdateProfile applies a self-service profile update. Locked fields can only
+// be changed by holders of profile.lock. A new email address is not applied
+// but gets a verification code; emailPending reports that.
+func UpdateProfile(user models.User, req models.UpdateUserRequest, ipAddress string) (updated *models.User, emailPending bool, err error) {
+	locked := map[string]bool{}
+	if !HasPermission(user.Role, models.PermProfileLock) {
+		locked = lockedProfileFields()
+	}
+
+	updates := map[string]interface{}{}
+	var changed []string
+	set := func(column, value, current string, maxLen int) error {
+		value = strings.TrimSpace(value)
+		if value == "" || value == current {
+			return nil
+		}
+		if len(value) > maxLen {
+			return fmt.Errorf("%s must be at most %d characters", column, maxLen)
+		}
+		if locked[column] && !strings.EqualFold(value, current) {
+			return fmt.Errorf("%s is locked by an administrator", column)
+		}
+		updates[column] = value
+		changed = append(changed, column)
+		return nil
+	}
+
+	if strings.TrimSpace(req.ContactNumber) != "" && !contactNumberPattern.MatchString(strings.TrimSpace(req.ContactNumber)) {
+		return nil, false, errors.New("contact_number may only contain digits, spaces and + ( ) -, 7 to 20 characters")
+	}
+	for _, f := range []struct {
+		column, value, current string
+		maxLen                 int
+	}{
+		{"first_name", req.FirstName, user.FirstName, 100},
+		{"last_name", req.LastName, user.LastName, 100},
+		{"middle_name", req.MiddleName, user.MiddleName, 100},
+		{"year_level", req.YearLevel, user.YearLevel, 50},
+		{"contact_number", req.ContactNumber, user.ContactNumber, 20},
+		{"address", req.Address, user.Address, 1000},
+	} {
+		if err := set(f.column, f.value, f.current, f.maxLen); err != nil {
+			return nil, false, err
+		}
+	}
+	if strings.TrimSpace(req.Locale) != "" {
+		if locale := NormalizeLocale(req.Locale); locale != user.Locale {
+			updates["locale"] = locale
+			changed = append(changed, "locale")
+		}
+	}
+
+	// Institution fields are validated together; a new course may imply a new
+	// department and college, which are then subject to their own locks
+	if req.College != "" || req.Department != "" || req.Course != "" || req.Section != "" {
+		college, department, course, section := pick(req.College, user.College), pick(req.Department, user.Department),
+			pick(req.Course, user.Course), pick(req.Section, user.Section)
+		refs, err := ResolveInstitution(&college, &department, &course, &section)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, f := range []struct{ column, value, current string }{
+			{"college", college, user.College},
+			{"department", department, user.Department},
+			{"course", course, user.Course},
+			{"section", section, user.Section},
+		} {
+			if err := set(f.column, f.value, f.current, 100); err != nil {
+				return nil, false, err
+			}
+		}
+		updates["college_id"] = refs.CollegeID
+		updates["department_id"] = refs.DepartmentID
+		updates["course_id"] = refs.CourseID
+		updates["section_id"] = refs.SectionID
+	}
+
+	newEmail := utils.SanitizeEmail(req.Email)
+	changingEmail := newEmail != "" && newEmail != strings.ToLower(user.Email)
+	if changingEmail {
+		if !utils.ValidateEmail(newEmail) {
+			return nil, false, errors.New("invalid email format")
+		}
+		if err := checkEmailDuplicates(newEmail); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if len(changed) > 0 {
+		if err := connection.DB.Model(&models.User{}).Where(studentWhere, user.StudentID).Updates(updates).Error; err != nil {
+			return nil, false, fmt.Errorf("failed to update profile: %v", err)
+		}
+		go LogAuditAction(AuditProfileUpdated, user.StudentID, user.StudentID, strings.Join(changed, ","), ipAddress)
+	}
+	if changingEmail {
+		if err := startEmailChange(user, newEmail); err != nil {
+			return nil, false, err
+		}
+	}
+
+	var fresh models.User
+	if err := GetUserByStudentID(user.StudentID, &fresh); err != nil {
+		return nil, false, errors.New(models.ErrUserNotFound)
+	}
+	return &fresh, changingEmail, nil
+}
+
+// pick returns value, or current when value is empty
+func pick(value, current string) string {
+	if v := strings.TrimSpace(value); v != "" {
+		return v
+	}
+	return current
+}
+
+// startEmailChange replaces any pending change and mails a code to the new address
+func startEmailChange(user models.User, newEmail string) error {
+	code, err := utils.GenerateVerificationCode()
+	if err != nil {
+		return fmt.Errorf("failed to generate verification code: %w", err)
+	}
+	change := models.EmailChange{
+		StudentID: user.StudentID,
+		NewEmail:  newEmail,
+		Code:      code,
+		ExpiresAt: time.Now().Add(emailChangeExpiry),
+	}
+	err = connection.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(studentWhere, user.StudentID).Delete(&models.EmailChange{}).Error; err != nil {
+			return err
+		}
+		return tx.Omit("id").Create(&change).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start email change: %v", err)
+	}
+
+	data := map[string]interface{}{
+		"Code":             code,
+		"ExpiresInMinutes": int(emailChangeExpiry.Minutes()),
+	}
+	if err := SendTemplatedEmail(newEmail, EmailTemplateEmailChange, user.Locale, data); err != nil {
+		logging.Logger.Warn("Failed to send email change code", zap.String("student_id", user.StudentID), zap.Error(err))
+	}
+	return nil
+}
+
+// ConfirmEmailChange applies a pending email change once its code matches
+func ConfirmEmailChange(user models.User, code, ipAddress string) (string, error) {
+	var change models.EmailChange
+	if err := connection.DB.Where("student_id = ? AND expires_at > ?", user.StudentID, time.Now()).First(&change).Error; err != nil {
+		return "", ErrNoEmailChange
+	}
+	if strings.TrimSpace(code) != change.Code {
+		return "", ErrInvalidEmailChangeCode
+	}
+
+	var count int64
+	connection.DB.Model(&models.User{}).Where("email = ? AND student_id <> ?", change.NewEmail, user.StudentID).Count(&count)
+	if count > 0 {
+		connection.DB.Delete(&change)
+		return "", errors.New("email already registered")
+	}
+
+	oldEmail := user.Email
+	err := connection.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where(studentWhere, user.StudentID).Update("email", change.NewEmail).Error; err != nil {
+			return err
+		}
+		// Reset codes sent to the old address must not work any more
+		if err := tx.Where("email = ? AND used = ?", oldEmail, false).Delete(&models.PasswordReset{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&change).Error
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to change email: %v", err)
+	}
+
+	go LogAuditAction(AuditEmailChanged, user.StudentID, user.StudentID, oldEmail+" -> "+change.NewEmail, ipAddress)
+	go func() {
+		data := map[string]interface{}{"NewEmail": change.NewEmail}
+		if err := SendTemplatedEmail(oldEmail, EmailTemplateEmailChanged, user.Locale, data); err != nil {
+			logging.Logger.Warn("Failed to send email changed notice", zap.String("student_id", user.StudentID), zap.Error(err))
+		}
+	}()
+	return change.NewEmail, nil
+}
+
+// CancelEmailChange discards a pending email change
+func CancelEmailChange(studentID string) error {
+	result := connection.DB.Where(studentWhere, studentID).Delete(&models.EmailChange{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to cancel email change: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNoEmailChange
+	}
+	return nil
+}
+
+// DeleteExpiredEmailChanges removes email changes whose code has expired
+func DeleteExpiredEmailChanges() (int64, error) {
+	result := connection.DB.Where("expires_at < ?", time.Now()).Delete(&models.EmailChange{})
+	return result.RowsAffected, result.Error
+}
@@ -3,11 +3,14 @@ package services
 
 import (
 	"attendance-system/connection"
+	"attendance-system/logging"
 	"attendance-system/models"
 	"attendance-system/utils"
 	"errors"
 	"fmt"
 	"time"
+
+	"go.uber.org/zap"
 )
 
 func RegisterService(req models.RegisterRequest) (string, string, error) {
@@ -21,6 +24,11 @@ func RegisterService(req models.RegisterRequest) (string, string, error) {
 		return "", "", err
 	}
 
+	// Only colleges, departments, courses and sections an admin has set up are accepted
+	if _, err := ResolveInstitution(&req.College, &req.Department, &req.Course, &req.Section); err != nil {
+		return "", "", err
+	}
+
 	studentID, err := generateOrValidateStudentID(req.StudentID)
 	if err != nil {
 		return "", "", err
@@ -45,13 +53,13 @@ func RegisterService(req models.RegisterRequest) (string, string, error) {
 		return "", "", err
 	}
 
-	if err := sendVerificationEmail(req.Email, studentID, verificationCode); err != nil {
+	if err := sendVerificationEmail(req.Email, studentID, verificationCode, req.Locale); err != nil {
 		// Log error without exposing sensitive information
-		fmt.Printf("Failed to send verification email\n")
+		logging.Logger.Warn("Failed to send verification email", zap.String("student_id", studentID), zap.Error(err))
 	}
 
 	// Generate email verification token
-	token, err := GenerateEmailVerificationToken(req.Email, verificationCode)
+	token, err := GenerateEmailVerificationToken(req.Email)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to generate verification token: %w", err)
 	}
@@ -156,6 +164,7 @@ func createPendingUser(req models.RegisterRequest, studentID, hashedPassword, ve
 		College:          req.College,
 		ContactNumber:    req.ContactNumber,
 		Address:          req.Address,
+		Locale:           NormalizeLocale(req.Locale),
 		VerificationCode: verificationCode,
 		ExpiresAt:        time.Now().Add(30 * time.Minute),
 	}
@@ -167,17 +176,16 @@ func createPendingUser(req models.RegisterRequest, studentID, hashedPassword, ve
 	return pending, nil
 }
 
-func sendVerificationEmail(email, studentID, verificationCode string) error {
-	// Build modern HTML email
-	content := fmt.Sprintf(`<p>Thank you for registering with the Attendance System.</p>
-		<p><strong>Student ID:</strong> <code>%s</code></p>
-		<p><strong>Verification code:</strong></p>
-		<p style="font-size:22px; font-weight:700; letter-spacing:2px;">%s</p>
-		<p>This code will expire in 30 minutes. Enter it on the verification page to complete your registration.</p>
-	`, studentID, verificationCode)
-
-	footer := `<p class="muted">If you did not register, please ignore this message.</p>`
-	htmlBody := BuildHTMLEmail("Verify your email", "Email Verification", content, footer)
+func sendVerificationEmail(email, studentID, verificationCode, locale string) error {
+	return SendTemplatedEmail(email, EmailTemplateVerification, locale, map[string]interface{}{
+		"StudentID":        studentID,
+		"Code":             verificationCode,
+		"ExpiresInMinutes": 30,
+	})
+}
 
-	return SendEmail(email, "Verification Code - Attendance System", htmlBody)
+// DeleteExpiredPendingUsers removes registrations whose verification code expired
+func DeleteExpiredPendingUsers() (int64, error) {
+	result := connection.DB.Where("expires_at < ?", time.Now()).Delete(&models.PendingUser{})
+	return result.RowsAffected, result.Error
 }
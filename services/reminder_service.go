@@ -0,0 +1,221 @@
+// services/reminder_service.go
+package services
+
+import (
+	"attendance-system/connection"
+	"attendance-system/logging"
+	"attendance-system/models"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm/clause"
+)
+
+// defaultReminderOffsets are used when EVENT_REMINDER_OFFSETS is not set
+var defaultReminderOffsets = []time.Duration{24 * time.Hour, 30 * time.Minute}
+
+// ReminderOffsets returns the configured reminder offsets before an event's
+// start time, largest first. EVENT_REMINDER_OFFSETS is a comma-separated list
+// of Go durations (e.g. "24h,30m"); set it to "off" to disable reminders.
+func ReminderOffsets() []time.Duration {
+	raw := strings.TrimSpace(os.Getenv("EVENT_REMINDER_OFFSETS"))
+	if raw == "" {
+		return defaultReminderOffsets
+	}
+	if strings.EqualFold(raw, "off") {
+		return nil
+	}
+
+	seen := make(map[time.Duration]bool)
+	var offsets []time.Duration
+	for _, part := range strings.Split(raw, ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil || d <= 0 || seen[d] {
+			continue
+		}
+		seen[d] = true
+		offsets = append(offsets, d)
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] > offsets[j] })
+	return offsets
+}
+
+// SendDueEventReminders emails eligible students about upcoming events whose
+// reminder time has been reached. Each (event, student, offset) is recorded in
+// event_reminders before sending so a restart never resends it. It returns the
+// number of reminders sent; an error means some could not be sent.
+func SendDueEventReminders() (int, error) {
+	offsets := ReminderOffsets()
+	if len(offsets) == 0 {
+		return 0, nil
+	}
+
+	now := time.Now()
+	var events []models.Event
+	if err := connection.DB.Where("is_active = ? AND status IN ? AND start_time > ? AND start_time <= ?",
+		true, []string{models.EventStatusScheduled, models.EventStatusPostponed}, now, now.Add(offsets[0])).Find(&events).Error; err != nil {
+		return 0, fmt.Errorf("failed to load events for reminders: %v", err)
+	}
+
+	total, failed := 0, 0
+	for _, event := range events {
+		offset, ok := dueReminderOffset(event.StartTime, now, offsets)
+		if !ok {
+			continue
+		}
+		sent, f := sendEventReminders(event, offset)
+		total += sent
+		failed += f
+	}
+	if failed > 0 {
+		return total, fmt.Errorf("failed to send %d event reminders", failed)
+	}
+	return total, nil
+}
+
+// dueReminderOffset returns the smallest offset whose send time has passed.
+// Only that reminder is sent, so an event created an hour before it starts
+// does not receive every larger reminder at once.
+func dueReminderOffset(start, now time.Time, offsets []time.Duration) (time.Duration, bool) {
+	var due time.Duration
+	found := false
+	for _, o := range offsets {
+		if !now.Before(start.Add(-o)) {
+			due = o
+			found = true
+		}
+	}
+	return due, found
+}
+
+// sendEventReminders sends one event's reminders. failed counts reminders
+// that will be retried by a later run (1 when the recipients could not be loaded).
+func sendEventReminders(event models.Event, offset time.Duration) (sent, failed int) {
+	students, err := EligibleStudentsForEvent(event)
+	if err != nil {
+		logging.Logger.Error("Failed to load students for reminders", zap.Uint("event_id", event.ID), zap.Error(err))
+		return 0, 1
+	}
+
+	optedOut, err := reminderOptOuts(event.ID)
+	if err != nil {
+		logging.Logger.Error("Failed to load reminder opt-outs", zap.Uint("event_id", event.ID), zap.Error(err))
+		return 0, 1
+	}
+
+	for _, student := range students {
+		if student.Email == "" || optedOut[student.StudentID] {
+			continue
+		}
+
+		// Claim the reminder first; a conflict means it was already sent
+		record := models.EventReminder{
+			EventID:       event.ID,
+			StudentID:     student.StudentID,
+			OffsetMinutes: int(offset / time.Minute),
+		}
+		result := connection.DB.Clauses(clause.OnConflict{DoNothing: true}).Omit("id").Create(&record)
+		if result.Error != nil {
+			logging.Logger.Error("Failed to record event reminder", zap.Uint("event_id", event.ID), zap.Error(result.Error))
+			failed++
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		if err := sendEventReminderEmail(event, student); err != nil {
+			// Release the claim so the next run retries this student
+			connection.DB.Delete(&record)
+			failed++
+			continue
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		logging.Logger.Info("Event reminders sent",
+			zap.Uint("event_id", event.ID),
+			zap.Duration("offset", offset),
+			zap.Int("count", sent),
+		)
+	}
+	return sent, failed
+}
+
+func sendEventReminderEmail(event models.Event, student models.User) error {
+	optOutToken, err := GenerateReminderOptOutToken(event.ID, student.StudentID)
+	if err != nil {
+		return err
+	}
+
+	data := map[string]interface{}{
+		"StudentName": strings.TrimSpace(student.FirstName + " " + student.LastName),
+		"EventTitle":  event.Title,
+		"StartTime":   event.StartTime.Local().Format("Monday, January 2, 2006 3:04 PM"),
+		"Location":    event.Location,
+		"EventLink":   fmt.Sprintf("%s/events/%d", AppBaseURL(), event.ID),
+		"OptOutLink":  fmt.Sprintf("%s/reminders/opt-out?token=%s", PublicAPIURL(), url.QueryEscape(optOutToken)),
+	}
+	return SendTemplatedEmail(student.Email, EmailTemplateEventReminder, student.Locale, data)
+}
+
+func reminderOptOuts(eventID uint) (map[string]bool, error) {
+	var optOuts []models.EventReminderOptOut
+	if err := connection.DB.Where(EventWhere, eventID).Find(&optOuts).Error; err != nil {
+		return nil, err
+	}
+	out := make(map[string]bool, len(optOuts))
+	for _, o := range optOuts {
+		out[o.StudentID] = true
+	}
+	return out, nil
+}
+
+// OptOutOfEventReminders stops reminders for one event for a student
+func OptOutOfEventReminders(eventID uint, studentID string) error {
+	var event models.Event
+	if err := connection.DB.First(&event, eventID).Error; err != nil {
+		return errors.New(errEventNotFound)
+	}
+
+	optOut := models.EventReminderOptOut{EventID: eventID, StudentID: studentID}
+	if err := connection.DB.Clauses(clause.OnConflict{DoNothing: true}).Omit("id").Create(&optOut).Error; err != nil {
+		return fmt.Errorf("failed to opt out of reminders: %v", err)
+	}
+	return nil
+}
+
+// OptInToEventReminders re-enables reminders for one event for a student
+func OptInToEventReminders(eventID uint, studentID string) error {
+	if err := connection.DB.Where(EventAndStudentWhere, eventID, studentID).Delete(&models.EventReminderOptOut{}).Error; err != nil {
+		return fmt.Errorf("failed to opt in to reminders: %v", err)
+	}
+	return nil
+}
+
+// PublicAPIURL is the externally reachable base URL of this API (PUBLIC_API_URL)
+func PublicAPIURL() string {
+	if u := os.Getenv("PUBLIC_API_URL"); u != "" {
+		return strings.TrimRight(u, "/")
+	}
+	port := os.Getenv("APP_PORT")
+	if port == "" {
+		port = "3000"
+	}
+	return "http://localhost:" + port
+}
+
+// AppBaseURL is the base URL of the client app used for deep links (APP_BASE_URL)
+func AppBaseURL() string {
+	if u := os.Getenv("APP_BASE_URL"); u != "" {
+		return strings.TrimRight(u, "/")
+	}
+	return PublicAPIURL()
+}
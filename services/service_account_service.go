@@ -0,0 +1,266 @@
+// services/service_account_service.go
+package services
+
+import (
+	"attendance-system/connection"
+	"attendance-system/models"
+	"attendance-system/utils"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrServiceAccountNotFound is returned when a service account ID does not exist
+var ErrServiceAccountNotFound = errors.New("service account not found")
+
+// ErrInvalidAPIKey is returned for unknown, expired or revoked API keys
+var ErrInvalidAPIKey = errors.New("invalid, expired or revoked API key")
+
+const (
+	apiKeyPrefix            = "ak_"
+	serviceAccountIDPrefix  = "svc-"
+	apiKeyLastUsedThrottle  = time.Minute
+	serviceAccountEmailHost = "service-accounts.invalid"
+)
+
+var serviceAccountNamePattern = regexp.MustCompile(`^[a-z][a-z0-9-]{1,49}$`)
+
+// LegacyStudentIDAuthEnabled reports whether raw student IDs are still
+// accepted as bearer tokens and in the X-Student-ID header. The fallback
+// skips sessions and 2FA, so it is off unless LEGACY_STUDENT_ID_AUTH=true.
+func LegacyStudentIDAuthEnabled() bool {
+	v, err := strconv.ParseBool(os.Getenv("LEGACY_STUDENT_ID_AUTH"))
+	return err == nil && v
+}
+
+// hashAPIKey returns the stored form of an API key
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// ListServiceAccounts returns all service accounts with their roles
+func ListServiceAccounts() ([]models.ServiceAccount, error) {
+	var accounts []models.ServiceAccount
+	if err := connection.DB.Order("name").Find(&accounts).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch service accounts: %v", err)
+	}
+	ids := make([]string, 0, len(accounts))
+	for _, a := range accounts {
+		ids = append(ids, a.StudentID)
+	}
+	var users []models.User
+	if len(ids) > 0 {
+		connection.DB.Select("student_id", "role").Where("student_id IN ?", ids).Find(&users)
+	}
+	roles := make(map[string]string, len(users))
+	for _, u := range users {
+		roles[u.StudentID] = u.Role
+	}
+	for i := range accounts {
+		accounts[i].Role = roles[accounts[i].StudentID]
+	}
+	return accounts, nil
+}
+
+// CreateServiceAccount adds a service account and its backing user with the
+// given (non-superadmin) role
+func CreateServiceAccount(req models.ServiceAccountRequest, actor, ipAddress string) (*models.ServiceAccount, error) {
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+	if !serviceAccountNamePattern.MatchString(name) {
+		return nil, errors.New("invalid name. Use 2-50 lowercase letters, digits or dashes, starting with a letter")
+	}
+	if req.Role == models.RoleSuperAdmin || !RoleExists(req.Role) {
+		return nil, errors.New("role must be an existing role other than 'superadmin'")
+	}
+
+	studentID := serviceAccountIDPrefix + name
+	var count int64
+	connection.DB.Model(&models.User{}).Where(studentWhere, studentID).Count(&count)
+	if count > 0 {
+		return nil, errors.New("a service account with this name already exists")
+	}
+
+	account := models.ServiceAccount{
+		StudentID:   studentID,
+		Name:        name,
+		Description: strings.TrimSpace(req.Description),
+		CreatedBy:   actor,
+		Role:        req.Role,
+	}
+	user := models.User{
+		StudentID:        studentID,
+		Email:            studentID + "@" + serviceAccountEmailHost,
+		Password:         "!", // never matches a bcrypt hash
+		Username:         studentID,
+		Role:             req.Role,
+		IsVerified:       true,
+		IsServiceAccount: true,
+		VerifiedAt:       time.Now(),
+		FirstName:        name,
+		LastName:         "(service account)",
+	}
+	err := connection.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("id").Create(&user).Error; err != nil {
+			return err
+		}
+		return tx.Omit("id").Create(&account).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create service account: %v", err)
+	}
+
+	go LogAuditAction(AuditSvcAccountCreated, actor, studentID, "role: "+req.Role, ipAddress)
+	return &account, nil
+}
+
+// DisableServiceAccount disables an account and revokes all of its keys
+func DisableServiceAccount(accountID uint, actor, ipAddress string) error {
+	var account models.ServiceAccount
+	if err := connection.DB.First(&account, accountID).Error; err != nil {
+		return ErrServiceAccountNotFound
+	}
+	if account.DisabledAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	err := connection.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&account).Update("disabled_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.APIKey{}).
+			Where("service_account_id = ? AND revoked_at IS NULL", account.ID).
+			Update("revoked_at", now).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to disable service account: %v", err)
+	}
+
+	go LogAuditAction(AuditSvcAccountDisabled, actor, account.StudentID, account.Name, ipAddress)
+	return nil
+}
+
+// ListAPIKeys returns the keys of a service account, newest first
+func ListAPIKeys(accountID uint) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	if err := connection.DB.Where("service_account_id = ?", accountID).Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch API keys: %v", err)
+	}
+	for i := range keys {
+		keys[i].LoadScopes()
+	}
+	return keys, nil
+}
+
+// CreateAPIKey issues a new key for an active service account. The returned
+// plaintext key is not stored and cannot be shown again.
+func CreateAPIKey(accountID uint, req models.APIKeyRequest, actor, ipAddress string) (string, *models.APIKey, error) {
+	var account models.ServiceAccount
+	if err := connection.DB.First(&account, accountID).Error; err != nil {
+		return "", nil, ErrServiceAccountNotFound
+	}
+	if account.DisabledAt != nil {
+		return "", nil, errors.New("service account is disabled")
+	}
+	scopes, err := validatePermissions(req.Scopes)
+	if err != nil {
+		return "", nil, err
+	}
+	if req.ExpiresInDays < 0 {
+		return "", nil, errors.New("expires_in_days must not be negative")
+	}
+
+	prefix, err := utils.GenerateRandomToken(4)
+	if err != nil {
+		return "", nil, err
+	}
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", nil, err
+	}
+	plaintext := apiKeyPrefix + prefix + "_" + secret
+
+	key := models.APIKey{
+		ServiceAccountID: account.ID,
+		Name:             strings.TrimSpace(req.Name),
+		Prefix:           apiKeyPrefix + prefix,
+		KeyHash:          hashAPIKey(plaintext),
+		ScopesCSV:        strings.Join(scopes, ","),
+		CreatedBy:        actor,
+	}
+	if req.ExpiresInDays > 0 {
+		expires := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		key.ExpiresAt = &expires
+	}
+	if err := CreateWithoutID(&key); err != nil {
+		return "", nil, fmt.Errorf("failed to create API key: %v", err)
+	}
+
+	go LogAuditAction(AuditAPIKeyCreated, actor, account.StudentID,
+		fmt.Sprintf("key %s scopes: %s", key.Prefix, key.ScopesCSV), ipAddress)
+	key.LoadScopes()
+	return plaintext, &key, nil
+}
+
+// RevokeAPIKey revokes one key of a service account
+func RevokeAPIKey(accountID, keyID uint, actor, ipAddress string) error {
+	var account models.ServiceAccount
+	if err := connection.DB.First(&account, accountID).Error; err != nil {
+		return ErrServiceAccountNotFound
+	}
+	var key models.APIKey
+	if err := connection.DB.Where("id = ? AND service_account_id = ?", keyID, accountID).First(&key).Error; err != nil {
+		return errors.New("API key not found")
+	}
+	if key.RevokedAt != nil {
+		return nil
+	}
+	if err := connection.DB.Model(&key).Update("revoked_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to revoke API key: %v", err)
+	}
+
+	go LogAuditAction(AuditAPIKeyRevoked, actor, account.StudentID, "key "+key.Prefix, ipAddress)
+	return nil
+}
+
+// AuthenticateAPIKey resolves an API key to its key record and service
+// account user, recording when and from where it was last used
+func AuthenticateAPIKey(plaintext, ipAddress string) (*models.APIKey, *models.User, error) {
+	if !strings.HasPrefix(plaintext, apiKeyPrefix) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	var key models.APIKey
+	if err := connection.DB.Where("key_hash = ?", hashAPIKey(plaintext)).First(&key).Error; err != nil {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	now := time.Now()
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && now.After(*key.ExpiresAt)) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	var account models.ServiceAccount
+	if err := connection.DB.First(&account, key.ServiceAccountID).Error; err != nil || account.DisabledAt != nil {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	var user models.User
+	if err := connection.DB.Where(studentWhere+" AND is_service_account = ?", account.StudentID, true).First(&user).Error; err != nil {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	if err := CheckAccountAccess(user); err != nil {
+		return nil, nil, err
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyLastUsedThrottle || key.LastUsedIP != ipAddress {
+		connection.DB.Model(&key).Updates(map[string]interface{}{"last_used_at": now, "last_used_ip": ipAddress})
+	}
+	return &key, &user, nil
+}
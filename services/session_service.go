@@ -0,0 +1,242 @@
+// services/session_service.go
+package services
+
+import (
+	"attendance-system/connection"
+	"attendance-system/logging"
+	"attendance-system/models"
+	"attendance-system/utils"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrRefreshTokenReused is returned when an already rotated refresh token is
+// presented again; the whole session is revoked.
+var ErrRefreshTokenReused = errors.New("refresh token reuse detected; the session has been revoked")
+
+// ErrSessionNotFound is returned when a session ID does not belong to the user
+var ErrSessionNotFound = errors.New("session not found")
+
+// TokenPair is the result of a login or refresh
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	SessionID    string
+}
+
+// StartSession creates a new login session and issues its first tokens
+func StartSession(user models.User, userAgent, ipAddress string) (*TokenPair, error) {
+	sessionID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	session := models.AuthSession{
+		ID:         sessionID,
+		StudentID:  user.StudentID,
+		UserAgent:  truncate(userAgent, 512),
+		IPAddress:  ipAddress,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(RefreshTokenExpiry),
+	}
+
+	var pair *TokenPair
+	err = connection.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+		var err error
+		pair, err = issueSessionTokens(tx, user, sessionID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start session: %v", err)
+	}
+	return pair, nil
+}
+
+// issueSessionTokens records a new refresh token for the session and signs
+// both tokens
+func issueSessionTokens(tx *gorm.DB, user models.User, sessionID string) (*TokenPair, error) {
+	tokenID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Create(&models.RefreshToken{
+		ID:        tokenID,
+		SessionID: sessionID,
+		ExpiresAt: time.Now().Add(RefreshTokenExpiry),
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	access, err := GenerateAccessToken(user, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	refresh, err := GenerateRefreshToken(user, sessionID, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{AccessToken: access, RefreshToken: refresh, SessionID: sessionID}, nil
+}
+
+// RotateRefreshToken exchanges a refresh token for a new pair. Each token can
+// be used once; presenting a rotated token revokes the whole session.
+func RotateRefreshToken(tokenString, userAgent, ipAddress string) (*TokenPair, error) {
+	claims, err := VerifyRefreshToken(tokenString)
+	if err != nil || claims.ID == "" || claims.SessionID == "" {
+		return nil, ErrInvalidToken
+	}
+
+	var token models.RefreshToken
+	if err := connection.DB.Where("id = ? AND session_id = ?", claims.ID, claims.SessionID).First(&token).Error; err != nil {
+		return nil, ErrInvalidToken
+	}
+	var session models.AuthSession
+	if err := connection.DB.Where("id = ? AND student_id = ?", token.SessionID, claims.StudentID).First(&session).Error; err != nil {
+		return nil, ErrInvalidToken
+	}
+	if session.RevokedAt != nil {
+		return nil, ErrInvalidToken
+	}
+
+	// Claim the token; if it was already rotated this is a replay
+	now := time.Now()
+	result := connection.DB.Model(&models.RefreshToken{}).
+		Where("id = ? AND rotated_at IS NULL", token.ID).
+		Update("rotated_at", now)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		if _, err := revokeSessions(connection.DB.Where("id = ?", session.ID), models.SessionRevokedReuse); err != nil {
+			return nil, err
+		}
+		logging.Logger.Warn("Refresh token reuse detected",
+			zap.String("student_id", session.StudentID),
+			zap.String("session_id", session.ID),
+			zap.String("ip_address", ipAddress),
+		)
+		go LogAuditAction(AuditRefreshTokenReuse, session.StudentID, session.StudentID, "session "+session.ID, ipAddress)
+		return nil, ErrRefreshTokenReused
+	}
+
+	var user models.User
+	if err := GetUserByStudentID(session.StudentID, &user); err != nil {
+		return nil, errors.New(models.ErrUserNotFound)
+	}
+	if err := CheckAccountAccess(user); err != nil {
+		revokeSessions(connection.DB.Where("id = ?", session.ID), models.SessionRevokedAccountStatus)
+		return nil, err
+	}
+	// Sessions opened before 2FA became mandatory for the role end here
+	if TwoFactorRequired(user.Role) && !IsTwoFactorEnabled(user.StudentID) {
+		revokeSessions(connection.DB.Where("id = ?", session.ID), models.SessionRevoked2FARequired)
+		return nil, ErrTwoFactorRequired
+	}
+
+	var pair *TokenPair
+	err = connection.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&session).Updates(map[string]interface{}{
+			"last_used_at": now,
+			"expires_at":   now.Add(RefreshTokenExpiry),
+			"user_agent":   truncate(userAgent, 512),
+			"ip_address":   ipAddress,
+		}).Error; err != nil {
+			return err
+		}
+		var err error
+		pair, err = issueSessionTokens(tx, user, session.ID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh session: %v", err)
+	}
+	return pair, nil
+}
+
+// IsSessionRevoked reports whether a session has been revoked. Tokens issued
+// before sessions existed carry no session ID and are not checked.
+func IsSessionRevoked(sessionID string) bool {
+	if sessionID == "" {
+		return false
+	}
+	var session models.AuthSession
+	if err := connection.DB.Select("revoked_at").Where("id = ?", sessionID).First(&session).Error; err != nil {
+		return true
+	}
+	return session.RevokedAt != nil
+}
+
+// ListSessions returns the user's active sessions, most recently used first
+func ListSessions(studentID, currentSessionID string) ([]models.AuthSession, error) {
+	var sessions []models.AuthSession
+	if err := connection.DB.
+		Where("student_id = ? AND revoked_at IS NULL AND expires_at > ?", studentID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch sessions: %v", err)
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+	return sessions, nil
+}
+
+// RevokeSession logs out a single session of the user
+func RevokeSession(studentID, sessionID, reason string) error {
+	n, err := revokeSessions(connection.DB.Where("id = ? AND student_id = ?", sessionID, studentID), reason)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeAllSessions logs the user out everywhere. It is called on logout-all,
+// password reset and role change.
+func RevokeAllSessions(studentID, reason string) (int64, error) {
+	return revokeSessions(connection.DB.Where("student_id = ?", studentID), reason)
+}
+
+// RevokeOtherSessions logs the user out everywhere except the given session
+func RevokeOtherSessions(studentID, keepSessionID, reason string) (int64, error) {
+	return revokeSessions(connection.DB.Where("student_id = ? AND id <> ?", studentID, keepSessionID), reason)
+}
+
+func revokeSessions(scope *gorm.DB, reason string) (int64, error) {
+	result := scope.Model(&models.AuthSession{}).
+		Where("revoked_at IS NULL").
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %v", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// DeleteExpiredSessions removes expired refresh tokens and sessions that
+// expired or were revoked over a refresh-token lifetime ago
+func DeleteExpiredSessions() (int64, error) {
+	cutoff := time.Now().Add(-RefreshTokenExpiry)
+	stale := connection.DB.Model(&models.AuthSession{}).Select("id").
+		Where("expires_at < ? OR revoked_at < ?", time.Now(), cutoff)
+	if err := connection.DB.Where("expires_at < ? OR session_id IN (?)", time.Now(), stale).Delete(&models.RefreshToken{}).Error; err != nil {
+		return 0, err
+	}
+	result := connection.DB.Where("expires_at < ? OR revoked_at < ?", time.Now(), cutoff).Delete(&models.AuthSession{})
+	return result.RowsAffected, result.Error
+}
+
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
@@ -0,0 +1,273 @@
+// services/term_service.go
+package services
+
+import (
+	"attendance-system/connection"
+	"attendance-system/models"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrTermNotFound is returned when a term ID does not exist
+var ErrTermNotFound = errors.New("academic term not found")
+
+// ErrTermClosed is returned when attendance or event dates in a closed term
+// would change
+var ErrTermClosed = errors.New("the academic term for this event is closed; its attendance can no longer be changed")
+
+const termDateLayout = "2006-01-02"
+
+// ListTerms returns all terms, newest first
+func ListTerms() ([]models.AcademicTerm, error) {
+	var terms []models.AcademicTerm
+	if err := connection.DB.Order("start_date DESC").Find(&terms).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch terms: %v", err)
+	}
+	return terms, nil
+}
+
+// GetActiveTerm returns the term marked active, if any
+func GetActiveTerm() (*models.AcademicTerm, error) {
+	var term models.AcademicTerm
+	if err := connection.DB.Where("is_active = ?", true).First(&term).Error; err != nil {
+		return nil, errors.New("no active academic term")
+	}
+	return &term, nil
+}
+
+// parseTermRequest validates the name and date range of a term request
+func parseTermRequest(req models.AcademicTermRequest) (string, time.Time, time.Time, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return "", time.Time{}, time.Time{}, errors.New("name is required")
+	}
+	start, err := time.Parse(termDateLayout, req.StartDate)
+	if err != nil {
+		return "", time.Time{}, time.Time{}, errors.New("invalid start_date format. Use YYYY-MM-DD")
+	}
+	end, err := time.Parse(termDateLayout, req.EndDate)
+	if err != nil {
+		return "", time.Time{}, time.Time{}, errors.New("invalid end_date format. Use YYYY-MM-DD")
+	}
+	if end.Before(start) {
+		return "", time.Time{}, time.Time{}, errors.New("end_date must not be before start_date")
+	}
+	return name, start, end, nil
+}
+
+// ensureNoTermOverlap rejects ranges that overlap another term, so each event
+// date belongs to at most one term.
+func ensureNoTermOverlap(start, end time.Time, excludeID uint) error {
+	var other models.AcademicTerm
+	err := connection.DB.
+		Where("id <> ? AND start_date <= ? AND end_date >= ?", excludeID, end.Format(termDateLayout), start.Format(termDateLayout)).
+		First(&other).Error
+	if err == nil {
+		return fmt.Errorf("dates overlap with term %q", other.Name)
+	}
+	return nil
+}
+
+// CreateTerm adds a term and attaches existing events in its date range
+func CreateTerm(req models.AcademicTermRequest) (*models.AcademicTerm, error) {
+	name, start, end, err := parseTermRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureNoTermOverlap(start, end, 0); err != nil {
+		return nil, err
+	}
+
+	term := &models.AcademicTerm{Name: name, StartDate: start, EndDate: end}
+	if err := CreateWithoutID(term); err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return nil, errors.New("a term with this name already exists")
+		}
+		return nil, fmt.Errorf("failed to create term: %v", err)
+	}
+
+	if err := attachEventsToTerm(connection.DB, term); err != nil {
+		return nil, err
+	}
+	return term, nil
+}
+
+// UpdateTerm renames a term or changes its dates, re-attaching events.
+// A closed term's dates cannot change.
+func UpdateTerm(termID uint, req models.AcademicTermRequest) (*models.AcademicTerm, error) {
+	var term models.AcademicTerm
+	if err := connection.DB.First(&term, termID).Error; err != nil {
+		return nil, ErrTermNotFound
+	}
+
+	if req.Name == "" {
+		req.Name = term.Name
+	}
+	if req.StartDate == "" {
+		req.StartDate = term.StartDate.Format(termDateLayout)
+	}
+	if req.EndDate == "" {
+		req.EndDate = term.EndDate.Format(termDateLayout)
+	}
+	name, start, end, err := parseTermRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	datesChanged := !start.Equal(term.StartDate) || !end.Equal(term.EndDate)
+	if datesChanged && term.IsClosed {
+		return nil, errors.New("reopen the term before changing its dates")
+	}
+	if err := ensureNoTermOverlap(start, end, term.ID); err != nil {
+		return nil, err
+	}
+
+	term.Name, term.StartDate, term.EndDate = name, start, end
+	err = connection.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&term).Updates(map[string]interface{}{
+			"name":       name,
+			"start_date": start,
+			"end_date":   end,
+		}).Error; err != nil {
+			return err
+		}
+		if !datesChanged {
+			return nil
+		}
+		return attachEventsToTerm(tx, &term)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update term: %v", err)
+	}
+	return &term, nil
+}
+
+// attachEventsToTerm points events dated within the term at it and detaches
+// events that no longer fall inside it.
+func attachEventsToTerm(db *gorm.DB, term *models.AcademicTerm) error {
+	start, end := term.StartDate.Format(termDateLayout), term.EndDate.Format(termDateLayout)
+	if err := db.Model(&models.Event{}).
+		Where("term_id = ? AND NOT (DATE(event_date) BETWEEN ? AND ?)", term.ID, start, end).
+		Update("term_id", nil).Error; err != nil {
+		return fmt.Errorf("failed to detach events from term: %v", err)
+	}
+	if err := db.Model(&models.Event{}).
+		Where("DATE(event_date) BETWEEN ? AND ?", start, end).
+		Update("term_id", term.ID).Error; err != nil {
+		return fmt.Errorf("failed to attach events to term: %v", err)
+	}
+	return nil
+}
+
+// ActivateTerm makes a term the active one
+func ActivateTerm(termID uint) (*models.AcademicTerm, error) {
+	var term models.AcademicTerm
+	if err := connection.DB.First(&term, termID).Error; err != nil {
+		return nil, ErrTermNotFound
+	}
+	err := connection.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.AcademicTerm{}).Where("is_active = ?", true).Update("is_active", false).Error; err != nil {
+			return err
+		}
+		return tx.Model(&term).Update("is_active", true).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to activate term: %v", err)
+	}
+	return &term, nil
+}
+
+// CloseTerm freezes the attendance of every event in the term
+func CloseTerm(termID uint, closedBy, ipAddress string) (*models.AcademicTerm, error) {
+	return setTermClosed(termID, true, closedBy, ipAddress)
+}
+
+// ReopenTerm allows attendance in the term to be edited again
+func ReopenTerm(termID uint, reopenedBy, ipAddress string) (*models.AcademicTerm, error) {
+	return setTermClosed(termID, false, reopenedBy, ipAddress)
+}
+
+func setTermClosed(termID uint, closed bool, actor, ipAddress string) (*models.AcademicTerm, error) {
+	var term models.AcademicTerm
+	if err := connection.DB.First(&term, termID).Error; err != nil {
+		return nil, ErrTermNotFound
+	}
+	if term.IsClosed == closed {
+		return &term, nil
+	}
+
+	updates := map[string]interface{}{"is_closed": closed, "closed_at": nil, "closed_by": ""}
+	action := AuditTermReopened
+	if closed {
+		updates["closed_at"] = time.Now()
+		updates["closed_by"] = actor
+		action = AuditTermClosed
+	}
+	if err := connection.DB.Model(&term).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update term: %v", err)
+	}
+
+	go LogAuditAction(action, actor, strconv.FormatUint(uint64(term.ID), 10), term.Name, ipAddress)
+	return &term, nil
+}
+
+// termForDate returns the term containing date, or nil
+func termForDate(date time.Time) *models.AcademicTerm {
+	var term models.AcademicTerm
+	day := date.Format(termDateLayout)
+	if err := connection.DB.Where("start_date <= ? AND end_date >= ?", day, day).First(&term).Error; err != nil {
+		return nil
+	}
+	return &term
+}
+
+// isTermClosed reports whether termID refers to a closed term
+func isTermClosed(termID *uint) bool {
+	if termID == nil {
+		return false
+	}
+	var term models.AcademicTerm
+	if err := connection.DB.Select("is_closed").First(&term, *termID).Error; err != nil {
+		return false
+	}
+	return term.IsClosed
+}
+
+// eventTermFor returns the term for an event dated date that is currently in
+// term current. Moving an event into or out of a closed term is refused.
+func eventTermFor(current *uint, date time.Time) (*uint, error) {
+	var next *uint
+	term := termForDate(date)
+	if term != nil {
+		next = &term.ID
+	}
+	if current != nil && next != nil && *current == *next {
+		return next, nil
+	}
+	if isTermClosed(current) || (term != nil && term.IsClosed) {
+		return nil, ErrTermClosed
+	}
+	return next, nil
+}
+
+// assignEventTerm attaches an event to the term containing its date
+func assignEventTerm(event *models.Event) error {
+	termID, err := eventTermFor(event.TermID, event.EventDate)
+	if err != nil {
+		return err
+	}
+	event.TermID = termID
+	return nil
+}
+
+// ensureEventTermOpen rejects attendance changes for events in a closed term
+func ensureEventTermOpen(event models.Event) error {
+	if isTermClosed(event.TermID) {
+		return ErrTermClosed
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+// services/two_factor_service.go
+package services
+
+import (
+	"attendance-system/connection"
+	"attendance-system/models"
+	"attendance-system/utils"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidTwoFactorCode is returned for wrong, reused or missing codes
+var ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+
+// ErrTwoFactorRequired is returned when a role requires 2FA that the user has
+// not enrolled
+var ErrTwoFactorRequired = errors.New("two-factor authentication is required for your role; please log in again to set it up")
+
+const recoveryCodeCount = 10
+
+// TwoFactorSetup is returned when enrolment starts
+type TwoFactorSetup struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+	QRCode     string `json:"qr_code"` // base64 PNG of OTPAuthURI
+}
+
+// TwoFactorStatus describes a user's 2FA state
+type TwoFactorStatus struct {
+	Enabled                bool       `json:"enabled"`
+	Required               bool       `json:"required"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"`
+}
+
+// twoFactorIssuer is the account label shown in authenticator apps
+func twoFactorIssuer() string {
+	if issuer := strings.TrimSpace(os.Getenv("TWO_FACTOR_ISSUER")); issuer != "" {
+		return issuer
+	}
+	return "Attendance System"
+}
+
+// TwoFactorRequired reports whether the role must use 2FA. Configured with
+// TWO_FACTOR_REQUIRED_ROLES (comma-separated, default "admin,superadmin";
+// "none" disables the policy).
+func TwoFactorRequired(role string) bool {
+	roles := os.Getenv("TWO_FACTOR_REQUIRED_ROLES")
+	if strings.TrimSpace(roles) == "" {
+		roles = models.RoleAdmin + "," + models.RoleSuperAdmin
+	}
+	for _, r := range strings.Split(roles, ",") {
+		if strings.TrimSpace(r) == role {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTwoFactorEnabled reports whether the user has a confirmed second factor
+func IsTwoFactorEnabled(studentID string) bool {
+	var count int64
+	connection.DB.Model(&models.TwoFactor{}).Where("student_id = ? AND enabled = ?", studentID, true).Count(&count)
+	return count > 0
+}
+
+// GetTwoFactorStatus returns the user's 2FA state
+func GetTwoFactorStatus(user models.User) TwoFactorStatus {
+	status := TwoFactorStatus{Required: TwoFactorRequired(user.Role)}
+	var tf models.TwoFactor
+	if err := connection.DB.Where("student_id = ? AND enabled = ?", user.StudentID, true).First(&tf).Error; err != nil {
+		return status
+	}
+	status.Enabled = true
+	status.EnabledAt = tf.EnabledAt
+	connection.DB.Model(&models.RecoveryCode{}).Where("student_id = ? AND used_at IS NULL", user.StudentID).Count(&status.RecoveryCodesRemaining)
+	return status
+}
+
+// BeginTwoFactorSetup creates a new (unconfirmed) secret for the user
+func BeginTwoFactorSetup(user models.User) (*TwoFactorSetup, error) {
+	if IsTwoFactorEnabled(user.StudentID) {
+		return nil, errors.New("two-factor authentication is already enabled; disable it first")
+	}
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	tf := models.TwoFactor{StudentID: user.StudentID, Secret: secret}
+	err = connection.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("student_id = ?", user.StudentID).Delete(&models.TwoFactor{}).Error; err != nil {
+			return err
+		}
+		return tx.Omit("id").Create(&tf).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start two-factor setup: %v", err)
+	}
+
+	uri := utils.TOTPProvisioningURI(secret, twoFactorIssuer(), user.Email)
+	png, err := qrcode.Encode(uri, qrcode.Medium, 256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate QR code: %v", err)
+	}
+	return &TwoFactorSetup{
+		Secret:     secret,
+		OTPAuthURI: uri,
+		QRCode:     base64Prefix + base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+// EnableTwoFactor confirms setup with a first valid code and returns the
+// recovery codes, which are shown only once
+func EnableTwoFactor(user models.User, code, ipAddress string) ([]string, error) {
+	var tf models.TwoFactor
+	if err := connection.DB.Where("student_id = ?", user.StudentID).First(&tf).Error; err != nil {
+		return nil, errors.New("start two-factor setup first")
+	}
+	if tf.Enabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+	step, ok := utils.ValidateTOTP(tf.Secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	var codes []string
+	err := connection.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&tf).Updates(map[string]interface{}{
+			"enabled":        true,
+			"enabled_at":     time.Now(),
+			"last_used_step": step,
+		}).Error; err != nil {
+			return err
+		}
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user.StudentID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable two-factor authentication: %v", err)
+	}
+
+	go LogAuditAction(AuditTwoFactorEnabled, user.StudentID, user.StudentID, "", ipAddress)
+	return codes, nil
+}
+
+// VerifySecondFactor checks a TOTP code (each code works once) or consumes a
+// recovery code
+func VerifySecondFactor(studentID, code, recoveryCode string) error {
+	var tf models.TwoFactor
+	if err := connection.DB.Where("student_id = ? AND enabled = ?", studentID, true).First(&tf).Error; err != nil {
+		return ErrInvalidTwoFactorCode
+	}
+
+	if recoveryCode != "" {
+		result := connection.DB.Model(&models.RecoveryCode{}).
+			Where("student_id = ? AND code_hash = ? AND used_at IS NULL", studentID, hashRecoveryCode(recoveryCode)).
+			Update("used_at", time.Now())
+		if result.Error != nil || result.RowsAffected == 0 {
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+
+	step, ok := utils.ValidateTOTP(tf.Secret, code, time.Now())
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+	result := connection.DB.Model(&models.TwoFactor{}).
+		Where("id = ? AND last_used_step < ?", tf.ID, step).
+		Update("last_used_step", step)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// DisableTwoFactor turns 2FA off after re-checking the password and a second factor
+func DisableTwoFactor(user models.User, req models.DisableTwoFactorRequest, ipAddress string) error {
+	if !IsTwoFactorEnabled(user.StudentID) {
+		return errors.New("two-factor authentication is not enabled")
+	}
+	var current models.User
+	if err := GetUserByStudentID(user.StudentID, &current); err != nil {
+		return errors.New(models.ErrUserNotFound)
+	}
+	if utils.ComparePassword(current.Password, req.Password) != nil {
+		return errors.New("password is incorrect")
+	}
+	if err := VerifySecondFactor(user.StudentID, req.Code, req.RecoveryCode); err != nil {
+		return err
+	}
+
+	if err := deleteTwoFactor(user.StudentID); err != nil {
+		return err
+	}
+	go LogAuditAction(AuditTwoFactorDisabled, user.StudentID, user.StudentID, "disabled by user", ipAddress)
+	return nil
+}
+
+// ResetTwoFactor removes another user's 2FA (e.g. a lost device); they must
+// enrol again at their next login if their role requires it
+func ResetTwoFactor(studentID, actor, ipAddress string) error {
+	if !IsTwoFactorEnabled(studentID) {
+		return errors.New("two-factor authentication is not enabled for this user")
+	}
+	if err := deleteTwoFactor(studentID); err != nil {
+		return err
+	}
+	if _, err := RevokeAllSessions(studentID, models.SessionRevoked2FAReset); err != nil {
+		return err
+	}
+	go LogAuditAction(AuditTwoFactorDisabled, actor, studentID, "reset by administrator", ipAddress)
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after a valid TOTP code
+func RegenerateRecoveryCodes(user models.User, code, ipAddress string) ([]string, error) {
+	if err := VerifySecondFactor(user.StudentID, code, ""); err != nil {
+		return nil, err
+	}
+	codes, err := replaceRecoveryCodes(connection.DB, user.StudentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate recovery codes: %v", err)
+	}
+	go LogAuditAction(AuditRecoveryCodesReset, user.StudentID, user.StudentID, "", ipAddress)
+	return codes, nil
+}
+
+func deleteTwoFactor(studentID string) error {
+	return connection.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("student_id = ?", studentID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("student_id = ?", studentID).Delete(&models.TwoFactor{}).Error
+	})
+}
+
+// replaceRecoveryCodes deletes the user's recovery codes and issues new ones
+func replaceRecoveryCodes(tx *gorm.DB, studentID string) ([]string, error) {
+	if err := tx.Where("student_id = ?", studentID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, recoveryCodeCount)
+	rows := make([]models.RecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw, err := utils.GenerateRandomToken(5)
+		if err != nil {
+			return nil, err
+		}
+		code := raw[:5] + "-" + raw[5:]
+		codes = append(codes, code)
+		rows = append(rows, models.RecoveryCode{StudentID: studentID, CodeHash: hashRecoveryCode(code)})
+	}
+	if err := tx.Omit("id").Create(&rows).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// hashRecoveryCode normalizes (case, dashes, spaces) and hashes a recovery code
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
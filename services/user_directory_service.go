@@ -0,0 +1,162 @@
+// services/user_directory_service.go
+package services
+
+import (
+	"attendance-system/connection"
+	"attendance-system/models"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// userSortColumns are the columns the directory can be sorted by
+var userSortColumns = map[string]bool{
+	"created_at": true, "student_id": true, "email": true, "first_name": true, "last_name": true,
+	"role": true, "course": true, "year_level": true, "section": true, "department": true, "status": true,
+}
+
+// userCSVHeader is the column order of the directory CSV export
+var userCSVHeader = []string{
+	"student_id", "email", "username", "role", "status", "is_verified", "first_name", "middle_name", "last_name",
+	"college", "department", "course", "year_level", "section", "contact_number", "created_at", "verified_at",
+}
+
+// userDirectoryQuery applies the directory filters. Supported keys: role,
+// course, year_level, section, department, college, status (strings),
+// verified and include_deleted (bools), created_from and created_to
+// (time.Time) and q (search over student ID, email and names).
+func userDirectoryQuery(filters map[string]interface{}) *gorm.DB {
+	query := connection.DB.Model(&models.User{})
+	for _, key := range []string{"role", "course", "year_level", "section", "department", "college", "status"} {
+		if v, ok := filters[key].(string); ok && v != "" {
+			query = query.Where(key+" = ?", v)
+		}
+	}
+	if verified, ok := filters["verified"].(bool); ok {
+		query = query.Where("is_verified = ?", verified)
+	}
+	if includeDeleted, _ := filters["include_deleted"].(bool); !includeDeleted {
+		query = query.Where("deleted_at IS NULL")
+	}
+	if from, ok := filters["created_from"].(time.Time); ok && !from.IsZero() {
+		query = query.Where("created_at >= ?", from)
+	}
+	if to, ok := filters["created_to"].(time.Time); ok && !to.IsZero() {
+		query = query.Where("created_at < ?", to)
+	}
+	if q, ok := filters["q"].(string); ok && strings.TrimSpace(q) != "" {
+		like := "%" + escapeLike(strings.TrimSpace(q)) + "%"
+		query = query.Where("(student_id ILIKE ? OR email ILIKE ? OR username ILIKE ? OR first_name ILIKE ? OR last_name ILIKE ? OR (first_name || ' ' || last_name) ILIKE ?)",
+			like, like, like, like, like, like)
+	}
+	return query
+}
+
+// escapeLike escapes the LIKE wildcards in user input
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// ValidateUserDirectorySort reports an unsupported sort before an export starts
+func ValidateUserDirectorySort(sort string) error {
+	_, err := userDirectoryOrder(sort)
+	return err
+}
+
+// userDirectoryOrder turns ?sort=field or ?sort=-field into an ORDER BY clause
+func userDirectoryOrder(sort string) (string, error) {
+	if sort == "" {
+		return "created_at DESC, id DESC", nil
+	}
+	dir := "ASC"
+	if strings.HasPrefix(sort, "-") {
+		dir, sort = "DESC", sort[1:]
+	}
+	if !userSortColumns[sort] {
+		return "", fmt.Errorf("invalid sort field %q", sort)
+	}
+	return sort + " " + dir + ", id " + dir, nil
+}
+
+// ListUsers returns one page of the user directory and the number of matching users
+func ListUsers(filters map[string]interface{}, sort string, limit, offset int) ([]models.UserResponse, int64, error) {
+	order, err := userDirectoryOrder(sort)
+	if err != nil {
+		return nil, 0, err
+	}
+	var total int64
+	if err := userDirectoryQuery(filters).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %v", err)
+	}
+	var users []models.User
+	if err := userDirectoryQuery(filters).Order(order).Limit(limit).Offset(offset).Find(&users).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch users: %v", err)
+	}
+	out := make([]models.UserResponse, 0, len(users))
+	for _, u := range users {
+		out = append(out, models.NewUserResponse(u))
+	}
+	return out, total, nil
+}
+
+// ExportUsersCSV writes every user matching filters as CSV and returns the row count
+func ExportUsersCSV(w io.Writer, filters map[string]interface{}, sort, actor, ipAddress string) (int, error) {
+	order, err := userDirectoryOrder(sort)
+	if err != nil {
+		return 0, err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(userCSVHeader); err != nil {
+		return 0, err
+	}
+
+	const batch = 1000
+	rows := 0
+	for offset := 0; ; offset += batch {
+		var users []models.User
+		if err := userDirectoryQuery(filters).Order(order).Limit(batch).Offset(offset).Find(&users).Error; err != nil {
+			return rows, fmt.Errorf("failed to fetch users: %v", err)
+		}
+		for _, u := range users {
+			verifiedAt := ""
+			if !u.VerifiedAt.IsZero() {
+				verifiedAt = u.VerifiedAt.UTC().Format(time.RFC3339)
+			}
+			record := []string{
+				u.StudentID, u.Email, u.Username, u.Role, u.AccountStatus(), strconv.FormatBool(u.IsVerified),
+				u.FirstName, u.MiddleName, u.LastName, u.College, u.Department, u.Course, u.YearLevel, u.Section,
+				u.ContactNumber, u.CreatedAt.UTC().Format(time.RFC3339), verifiedAt,
+			}
+			for i := range record {
+				record[i] = csvSafe(record[i])
+			}
+			if err := cw.Write(record); err != nil {
+				return rows, err
+			}
+			rows++
+		}
+		if len(users) < batch {
+			break
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return rows, err
+	}
+
+	go LogAuditAction(AuditUsersExported, actor, "users", fmt.Sprintf("%d users exported", rows), ipAddress)
+	return rows, nil
+}
+
+// csvSafe stops spreadsheet apps from evaluating user-supplied text as a formula
+func csvSafe(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
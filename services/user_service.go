@@ -81,16 +81,14 @@ func UpdateUserByID(studentID string, updates map[string]interface{}) error {
 	if err := connection.DB.Model(&models.User{}).Where("student_id = ?", studentID).Updates(updates).Error; err != nil {
 		return fmt.Errorf("failed to update user: %v", err)
 	}
-	return nil
-}
-
-// DeleteUserByStudentID deletes a user by student id
-func DeleteUserByStudentID(studentID string) error {
-	if studentID == "" {
-		return fmt.Errorf("student id is required")
-	}
-	if err := connection.DB.Where("student_id = ?", studentID).Delete(&models.User{}).Error; err != nil {
-		return fmt.Errorf("failed to delete user: %v", err)
+	if _, ok := updates["role"]; ok {
+		if _, err := RevokeAllSessions(studentID, models.SessionRevokedRoleChange); err != nil {
+			return err
+		}
+	} else if _, ok := updates["password"]; ok {
+		if _, err := RevokeAllSessions(studentID, models.SessionRevokedPasswordReset); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -0,0 +1,52 @@
+// Command verify_audit_log walks the audit log hash chain and reports the
+// first break. It exits with status 1 when the chain is broken.
+//
+//	go run ./tools/verify_audit_log [-json]
+package main
+
+import (
+	"attendance-system/connection"
+	"attendance-system/services"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+func main() {
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+
+	// Connect using same logic as app (reads .env)
+	connection.Connect()
+
+	report, err := services.VerifyAuditChain()
+	if err != nil {
+		log.Fatalf("verification failed: %v", err)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	} else {
+		if report.Checkpoint != nil {
+			fmt.Printf("checkpoint:  %d (entries up to %d pruned)\n", report.Checkpoint.ID, report.Checkpoint.LastEntryID)
+		}
+		fmt.Printf("checked:     %d entries (%d redacted)\n", report.Checked, report.Redacted)
+		fmt.Printf("head:        entry %d %s\n", report.HeadID, report.HeadHash)
+		if report.SignedHead != nil {
+			fmt.Printf("signed head: entry %d\n", report.SignedHead.LastEntryID)
+		}
+		if report.Valid {
+			fmt.Println("result:      chain intact")
+		} else {
+			fmt.Printf("result:      BROKEN at entry %d: %s\n", report.BreakID, report.BreakReason)
+		}
+	}
+
+	if !report.Valid {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"image"
+	"image/color"
+)
+
+// SquareThumbnail center-crops img to a square and scales it to size x size.
+// Each output pixel averages the source pixels it covers, which keeps
+// downscaled photos smooth without an external imaging library.
+func SquareThumbnail(img image.Image, size int) *image.RGBA {
+	b := img.Bounds()
+	side := b.Dx()
+	if b.Dy() < side {
+		side = b.Dy()
+	}
+	x0 := b.Min.X + (b.Dx()-side)/2
+	y0 := b.Min.Y + (b.Dy()-side)/2
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		sy0 := y0 + y*side/size
+		sy1 := y0 + (y+1)*side/size
+		if sy1 <= sy0 {
+			sy1 = sy0 + 1
+		}
+		for x := 0; x < size; x++ {
+			sx0 := x0 + x*side/size
+			sx1 := x0 + (x+1)*side/size
+			if sx1 <= sx0 {
+				sx1 = sx0 + 1
+			}
+			var r, g, bl, a, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					bl += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+			// Flatten transparency onto white so JPEG output looks right
+			ar := a / n
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8((r/n + (0xffff - ar)) >> 8),
+				G: uint8((g/n + (0xffff - ar)) >> 8),
+				B: uint8((bl/n + (0xffff - ar)) >> 8),
+				A: 0xff,
+			})
+		}
+	}
+	return dst
+}
@@ -4,6 +4,7 @@ package utils
 import (
 	"crypto/rand"
 	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"fmt"
 
@@ -58,6 +59,15 @@ func GenerateSecureCode(length int) (string, error) {
 	return fmt.Sprintf(format, int(codeNum)), nil
 }
 
+// GenerateRandomToken returns n random bytes, hex-encoded
+func GenerateRandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
 // GenerateVerificationCode generates a 6-digit verification code or returns error
 func GenerateVerificationCode() (string, error) {
 	return GenerateSecureCode(VerificationCodeLength)